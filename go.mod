@@ -7,13 +7,13 @@ toolchain go1.23.5
 require (
 	github.com/Cray-HPE/hms-base/v2 v2.2.0
 	github.com/Cray-HPE/hms-smd/v2 v2.34.0
+	github.com/Cray-HPE/hms-xname v1.4.0
 	github.com/hashicorp/go-retryablehttp v0.7.7
 )
 
 require (
 	github.com/Cray-HPE/hms-certs v1.6.0 // indirect
 	github.com/Cray-HPE/hms-securestorage v1.16.0 // indirect
-	github.com/Cray-HPE/hms-xname v1.4.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/go-jose/go-jose/v4 v4.0.5 // indirect
@@ -0,0 +1,392 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// TokenSource supplies the bearer token sent with each HSM request, e.g.
+// when HSM is reached through the API gateway. Token is called once per
+// request, so implementations should cache.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator is implemented by token sources that cache tokens. When
+// a request is refused with 401 Unauthorized, the token it carried is passed
+// to InvalidateToken, so the next request doesn't reuse it.
+type TokenInvalidator interface {
+	InvalidateToken(token string)
+}
+
+// WithTokenSource makes the helper send "Authorization: Bearer <token>" on
+// every request, with the token taken from ts.
+func WithTokenSource(ts TokenSource) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.tokenSource = ts
+	}
+}
+
+// Context key of the helper's service name, for token sources that make
+// requests of their own.
+type serviceNameKey struct{}
+
+// setAuthHeader adds the bearer token to req, if the helper has a token
+// source, and returns the token.
+func setAuthHeader(ctx context.Context, helper *DNSDHCPHelper, req *http.Request, serviceName string) (string, error) {
+	if helper.tokenSource == nil {
+		return "", nil
+	}
+	token, err := helper.tokenSource.Token(context.WithValue(ctx, serviceNameKey{}, serviceName))
+	if err != nil {
+		return "", fmt.Errorf("failed to get auth token: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return token, nil
+}
+
+// invalidateToken drops token from the helper's token source, if it caches.
+func invalidateToken(helper *DNSDHCPHelper, token string) {
+	if inv, ok := helper.tokenSource.(TokenInvalidator); ok && token != "" {
+		inv.InvalidateToken(token)
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////
+// Static token
+///////////////////////////////////////////////////////////////////////////
+
+// StaticTokenSource always returns the same token.
+type StaticTokenSource string
+
+func (ts StaticTokenSource) Token(ctx context.Context) (string, error) {
+	return string(ts), nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+// Token file
+///////////////////////////////////////////////////////////////////////////
+
+// FileTokenSource reads the token from a file, re-reading it whenever the
+// file changes, so a token rotated on disk (e.g. a mounted Kubernetes
+// secret) is picked up without restarting.
+type FileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func NewFileTokenSource(path string) *FileTokenSource {
+	return &FileTokenSource{path: path}
+}
+
+func (ts *FileTokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// Stat rather than open, it's cheap enough to do per request. os.Stat
+	// follows symlinks, so secret volume updates are seen too.
+	info, err := os.Stat(ts.path)
+	if err != nil {
+		return "", err
+	}
+	if ts.token != "" && info.ModTime().Equal(ts.modTime) && info.Size() == ts.size {
+		return ts.token, nil
+	}
+
+	tokenBytes, err := ioutil.ReadFile(ts.path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(tokenBytes))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", ts.path)
+	}
+
+	ts.token = token
+	ts.modTime = info.ModTime()
+	ts.size = info.Size()
+	return ts.token, nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+// OAuth2 client credentials
+///////////////////////////////////////////////////////////////////////////
+
+const (
+	defaultTokenRefreshMargin = 30 * time.Second
+	defaultTokenLifetime      = 5 * time.Minute
+)
+
+// ClientCredentialsTokenSource gets tokens from an OAuth2 token endpoint
+// (e.g. Keycloak) with the client credentials grant. The token is cached
+// and fetched again shortly before it expires, or once HSM refuses it.
+// Concurrent callers share one fetch, and the cache isn't locked while it
+// runs.
+type ClientCredentialsTokenSource struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// Client used to reach the token endpoint.
+	HTTPClient *retryablehttp.Client
+
+	// How long before expiry to get a new token. Capped at half the
+	// token's lifetime; defaults to 30 seconds.
+	RefreshMargin time.Duration
+
+	// How long a token lasts when the endpoint doesn't say (no expires_in).
+	// Defaults to 5 minutes.
+	DefaultLifetime time.Duration
+
+	// Service name sent in the User-Agent of token requests. Defaults to
+	// that of the helper making the request.
+	ServiceName string
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	fetch     *tokenFetch
+	now       func() time.Time
+}
+
+// A token request in progress.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// Token endpoint response (RFC 6749 section 5.1).
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func NewClientCredentialsTokenSource(tokenURL string, clientID string, clientSecret string,
+	httpClient *retryablehttp.Client) *ClientCredentialsTokenSource {
+	if httpClient == nil {
+		httpClient = retryablehttp.NewClient()
+	}
+	return &ClientCredentialsTokenSource{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		HTTPClient:   httpClient,
+	}
+}
+
+func (ts *ClientCredentialsTokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	if ts.now == nil {
+		ts.now = time.Now
+	}
+	if ts.token != "" && ts.now().Before(ts.refreshAt) {
+		token := ts.token
+		ts.mu.Unlock()
+		return token, nil
+	}
+	fetch := ts.fetch
+	if fetch == nil {
+		// The fetch outlives ctx, as other callers may be waiting on it.
+		fetch = &tokenFetch{done: make(chan struct{})}
+		ts.fetch = fetch
+		go ts.runFetch(context.WithoutCancel(ctx), fetch)
+	}
+	ts.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// InvalidateToken drops token if it's the cached one, so the next call to
+// Token() fetches a new one.
+func (ts *ClientCredentialsTokenSource) InvalidateToken(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.token == token {
+		ts.token = ""
+		ts.refreshAt = time.Time{}
+	}
+}
+
+// runFetch gets a token, caches it and hands it to the callers waiting on
+// fetch.
+func (ts *ClientCredentialsTokenSource) runFetch(ctx context.Context, fetch *tokenFetch) {
+	issued := ts.now()
+	token, lifetime, err := ts.fetchToken(ctx)
+
+	ts.mu.Lock()
+	if err == nil {
+		if lifetime <= 0 {
+			lifetime = ts.DefaultLifetime
+			if lifetime <= 0 {
+				lifetime = defaultTokenLifetime
+			}
+		}
+		margin := ts.RefreshMargin
+		if margin <= 0 {
+			margin = defaultTokenRefreshMargin
+		}
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		ts.token = token
+		ts.refreshAt = issued.Add(lifetime - margin)
+	}
+	ts.fetch = nil
+	ts.mu.Unlock()
+
+	fetch.token, fetch.err = token, err
+	close(fetch.done)
+}
+
+// fetchToken requests a token from the token endpoint, returning it with
+// its lifetime, or 0 if the endpoint didn't give one.
+func (ts *ClientCredentialsTokenSource) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", ts.ClientID)
+	form.Set("client_secret", ts.ClientSecret)
+	if len(ts.Scopes) > 0 {
+		form.Set("scope", strings.Join(ts.Scopes, " "))
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", ts.TokenURL, []byte(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to construct token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	serviceName := ts.ServiceName
+	if serviceName == "" {
+		serviceName, _ = ctx.Value(serviceNameKey{}).(string)
+	}
+	if serviceName == "" {
+		serviceName = defaultServiceName()
+	}
+	base.SetHTTPUserAgent(req.Request, serviceName)
+
+	rsp, err := ts.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get token: %w", err)
+	}
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token endpoint returned %d: %s", rsp.StatusCode,
+			strings.TrimSpace(string(body)))
+	}
+
+	var tokRsp tokenResponse
+	if err = json.Unmarshal(body, &tokRsp); err != nil {
+		return "", 0, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokRsp.AccessToken == "" {
+		return "", 0, fmt.Errorf("token endpoint returned no access token")
+	}
+	return tokRsp.AccessToken, time.Duration(tokRsp.ExpiresIn) * time.Second, nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+// TLS clients
+///////////////////////////////////////////////////////////////////////////
+
+// Create a retryable HTTP client that verifies HSM against a CA bundle and,
+// optionally, presents a client certificate (mTLS). This follows
+// hms_certs.CreateRetryableSecureHTTPClient(), but reads everything from
+// files.
+//
+// caBundle(in):      Pathname of the PEM CA trust bundle, added to the system
+// pool. May be empty to use the system pool only.
+// certFile(in):      Pathname of the PEM client certificate, or empty.
+// keyFile(in):       Pathname of the PEM client key, or empty.
+// timeoutSecs(in):   Max timeout, in seconds.
+// maxRetryCount(in): Max number of times to retry failures.  0 == try once.
+// maxRetrySecs(in):  Max back-off time, in seconds, for retries.
+// Return:            HTTP client, err on error, nil on success.
+func NewTLSHTTPClient(caBundle string, certFile string, keyFile string, timeoutSecs int,
+	maxRetryCount int, maxRetrySecs int) (*retryablehttp.Client, error) {
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		certPool = x509.NewCertPool()
+	}
+	if caBundle != "" {
+		caPEM, err := ioutil.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+		}
+	}
+	tlsConfig := &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	client := &http.Client{Transport: transport,
+		Timeout: time.Duration(timeoutSecs) * time.Second}
+
+	rtClient := retryablehttp.NewClient()
+	rtClient.RetryMax = maxRetryCount
+	rtClient.RetryWaitMax = time.Duration(maxRetrySecs) * time.Second
+	rtClient.HTTPClient = client
+	rtClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+
+	return rtClient, nil
+}
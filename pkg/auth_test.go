@@ -0,0 +1,297 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var gotAuth string
+
+func handleAuth(w http.ResponseWriter, req *http.Request) {
+	gotAuth = req.Header.Get("Authorization")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("[]"))
+}
+
+func TestStaticTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleAuth))
+	defer srv.Close()
+
+	hlp := New(srv.URL, WithTokenSource(StaticTokenSource("abc123")))
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (err != nil) {
+		t.Errorf("ERROR, GetAllEthernetInterfaces() error: %v", err)
+	}
+	if (gotAuth != "Bearer abc123") {
+		t.Errorf("ERROR, wrong Authorization header '%s'", gotAuth)
+	}
+
+	hlp = New(srv.URL)
+	hlp.GetAllEthernetInterfaces()
+	if (gotAuth != "") {
+		t.Errorf("ERROR, unexpected Authorization header '%s'", gotAuth)
+	}
+}
+
+func TestFileTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleAuth))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	ts := NewFileTokenSource(path)
+	hlp := New(srv.URL, WithTokenSource(ts))
+
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (err == nil) {
+		t.Errorf("ERROR, GetAllEthernetInterfaces() didn't fail without a token file.")
+	}
+
+	ioutil.WriteFile(path, []byte("first\n"), 0600)
+	hlp.GetAllEthernetInterfaces()
+	if (gotAuth != "Bearer first") {
+		t.Errorf("ERROR, wrong Authorization header '%s'", gotAuth)
+	}
+
+	ioutil.WriteFile(path, []byte("second-token\n"), 0600)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	hlp.GetAllEthernetInterfaces()
+	if (gotAuth != "Bearer second-token") {
+		t.Errorf("ERROR, rotated token not picked up, got '%s'", gotAuth)
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	var nReqs int
+	tokSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req.ParseForm()
+		if (req.Form.Get("grant_type") != "client_credentials" ||
+			req.Form.Get("client_id") != "dns-dhcp" || req.Form.Get("client_secret") != "s3cr3t") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		nReqs++
+		fmt.Fprintf(w, `{"access_token":"tok%d","token_type":"Bearer","expires_in":300}`, nReqs)
+	}))
+	defer tokSrv.Close()
+
+	now := time.Now()
+	ts := NewClientCredentialsTokenSource(tokSrv.URL, "dns-dhcp", "s3cr3t", nil)
+	ts.now = func() time.Time { return now }
+
+	tok, err := ts.Token(context.Background())
+	if (err != nil || tok != "tok1") {
+		t.Errorf("ERROR, Token() returned '%s', %v", tok, err)
+	}
+	now = now.Add(200 * time.Second)
+	tok, _ = ts.Token(context.Background())
+	if (tok != "tok1" || nReqs != 1) {
+		t.Errorf("ERROR, cached token not used, got '%s' after %d requests", tok, nReqs)
+	}
+	// Inside the refresh margin
+	now = now.Add(80 * time.Second)
+	tok, _ = ts.Token(context.Background())
+	if (tok != "tok2" || nReqs != 2) {
+		t.Errorf("ERROR, token not refreshed, got '%s' after %d requests", tok, nReqs)
+	}
+
+	bad := NewClientCredentialsTokenSource(tokSrv.URL, "dns-dhcp", "wrong", nil)
+	_, err = bad.Token(context.Background())
+	if (err == nil) {
+		t.Errorf("ERROR, Token() didn't fail with bad credentials.")
+	}
+}
+
+func TestClientCredentialsTokenSourceRefresh(t *testing.T) {
+	var nReqs int32
+	var userAgent string
+	release := make(chan struct{})
+	tokSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		n := atomic.AddInt32(&nReqs, 1)
+		userAgent = req.Header.Get("User-Agent")
+		fmt.Fprintf(w, `{"access_token":"tok%d","token_type":"Bearer"}`, n)
+	}))
+	defer tokSrv.Close()
+
+	now := time.Now()
+	ts := NewClientCredentialsTokenSource(tokSrv.URL, "dns-dhcp", "s3cr3t", nil)
+	ts.now = func() time.Time { return now }
+
+	// Callers share the fetch, and one giving up doesn't fail the others.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := ts.Token(ctx)
+		cancelled <- err
+	}()
+	results := make(chan string, 3)
+	for ix := 0; ix < 3; ix++ {
+		go func() {
+			tok, _ := ts.Token(context.Background())
+			results <- tok
+		}()
+	}
+	cancel()
+	if err := <-cancelled; (!errors.Is(err, context.Canceled)) {
+		t.Errorf("ERROR, cancelled Token() returned %v", err)
+	}
+	close(release)
+	for ix := 0; ix < 3; ix++ {
+		if tok := <-results; (tok != "tok1") {
+			t.Errorf("ERROR, concurrent Token() returned '%s'", tok)
+		}
+	}
+	if (atomic.LoadInt32(&nReqs) != 1) {
+		t.Errorf("ERROR, expected 1 token request, got %d", nReqs)
+	}
+
+	// Without expires_in, the token still expires.
+	now = now.Add(defaultTokenLifetime)
+	tok, _ := ts.Token(context.Background())
+	if (tok != "tok2") {
+		t.Errorf("ERROR, token without expires_in not refreshed, got '%s'", tok)
+	}
+
+	// A token HSM refuses is dropped, and the token request carries the
+	// helper's User-Agent.
+	var gotTokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotTokens = append(gotTokens, req.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	hlp := New(srv.URL, WithTokenSource(ts), WithServiceName("token-test"))
+	hlp.HTTPClient.RetryMax = 0
+	hlp.GetAllEthernetInterfaces()
+	hlp.GetAllEthernetInterfaces()
+	if (len(gotTokens) != 2 || gotTokens[0] != "Bearer tok2" || gotTokens[1] != "Bearer tok3") {
+		t.Errorf("ERROR, refused token not dropped, HSM got %v", gotTokens)
+	}
+	if (!strings.HasPrefix(userAgent, "token-test")) {
+		t.Errorf("ERROR, token request has User-Agent '%s'", userAgent)
+	}
+}
+
+// writeCert creates a localhost certificate signed by parent (self-signed if
+// parent is nil) and writes it and its key to dir.
+func writeCert(t *testing.T, dir string, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if (parent == nil) {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if (err != nil) {
+		t.Fatalf("ERROR, can't create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	ioutil.WriteFile(filepath.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return cert, key
+}
+
+func TestNewTLSHTTPClient(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := writeCert(t, dir, "ca", nil, nil, true)
+	writeCert(t, dir, "server", caCert, caKey, false)
+	writeCert(t, dir, "client", caCert, caKey, false)
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+	srvCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	if (err != nil) {
+		t.Fatalf("ERROR, can't load server certificate: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(handleAuth))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{srvCert},
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	client, err := NewTLSHTTPClient(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "client.crt"),
+		filepath.Join(dir, "client.key"), 5, 0, 1)
+	if (err != nil) {
+		t.Fatalf("ERROR, NewTLSHTTPClient() error: %v", err)
+	}
+	hlp := New(srv.URL, WithHTTPClient(client))
+	_, err = hlp.GetAllEthernetInterfaces()
+	if (err != nil) {
+		t.Errorf("ERROR, mTLS request failed: %v", err)
+	}
+
+	// No client certificate, the server refuses the handshake.
+	client, err = NewTLSHTTPClient(filepath.Join(dir, "ca.crt"), "", "", 5, 0, 1)
+	if (err != nil) {
+		t.Fatalf("ERROR, NewTLSHTTPClient() error: %v", err)
+	}
+	hlp = New(srv.URL, WithHTTPClient(client))
+	_, err = hlp.GetAllEthernetInterfaces()
+	if (err == nil) {
+		t.Errorf("ERROR, request without a client certificate succeeded.")
+	}
+
+	_, err = NewTLSHTTPClient(filepath.Join(dir, "client.key"), "", "", 5, 0, 1)
+	if (err == nil) {
+		t.Errorf("ERROR, NewTLSHTTPClient() accepted a CA bundle with no certificates.")
+	}
+}
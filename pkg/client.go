@@ -0,0 +1,117 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"io"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// Client is the set of HSM operations offered by DNSDHCPHelper. Consumers
+// should depend on Client rather than on the helper itself so they can
+// substitute a fake in unit tests; see the dns_dhcptest package.
+type Client interface {
+	GetUnknownComponents() ([]sm.CompEthInterfaceV2, error)
+	GetUnknownComponentsCtx(ctx context.Context) ([]sm.CompEthInterfaceV2, error)
+	GetAllEthernetInterfaces() ([]sm.CompEthInterfaceV2, error)
+	GetAllEthernetInterfacesCtx(ctx context.Context) ([]sm.CompEthInterfaceV2, error)
+	QueryEthernetInterfaces(filter EthernetInterfaceFilter) ([]sm.CompEthInterfaceV2, error)
+	QueryEthernetInterfacesCtx(ctx context.Context, filter EthernetInterfaceFilter) ([]sm.CompEthInterfaceV2, error)
+	GetEthernetInterface(macOrID string) (sm.CompEthInterfaceV2, error)
+	GetEthernetInterfaceCtx(ctx context.Context, macOrID string) (sm.CompEthInterfaceV2, error)
+
+	AddNewEthernetInterface(newInterface sm.CompEthInterfaceV2, patchIfConflict bool) error
+	AddNewEthernetInterfaceCtx(ctx context.Context, newInterface sm.CompEthInterfaceV2, patchIfConflict bool) error
+	PatchEthernetInterface(theInterface sm.CompEthInterfaceV2) error
+	PatchEthernetInterfaceCtx(ctx context.Context, theInterface sm.CompEthInterfaceV2) error
+	PatchEthernetInterfaceFields(macOrID string, patch sm.CompEthInterfaceV2Patch) (sm.CompEthInterfaceV2, error)
+	PatchEthernetInterfaceFieldsCtx(ctx context.Context, macOrID string,
+		patch sm.CompEthInterfaceV2Patch) (sm.CompEthInterfaceV2, error)
+	DeleteEthernetInterface(macOrID string) error
+	DeleteEthernetInterfaceCtx(ctx context.Context, macOrID string) error
+	DeleteEthernetInterfaces(filter EthernetInterfaceFilter, dryRun bool) ([]sm.CompEthInterfaceV2, error)
+	DeleteEthernetInterfacesCtx(ctx context.Context, filter EthernetInterfaceFilter,
+		dryRun bool) ([]sm.CompEthInterfaceV2, error)
+
+	ListIPAddresses(macOrID string) ([]sm.IPAddressMapping, error)
+	ListIPAddressesCtx(ctx context.Context, macOrID string) ([]sm.IPAddressMapping, error)
+	AddIPAddress(macOrID string, ipAddr sm.IPAddressMapping) error
+	AddIPAddressCtx(ctx context.Context, macOrID string, ipAddr sm.IPAddressMapping) error
+	PatchIPAddressNetwork(macOrID string, ipAddr string, network string) (sm.IPAddressMapping, error)
+	PatchIPAddressNetworkCtx(ctx context.Context, macOrID string, ipAddr string,
+		network string) (sm.IPAddressMapping, error)
+	DeleteIPAddress(macOrID string, ipAddr string) error
+	DeleteIPAddressCtx(ctx context.Context, macOrID string, ipAddr string) error
+
+	GetComponents(types ...string) ([]base.Component, error)
+	GetComponentsCtx(ctx context.Context, types ...string) ([]base.Component, error)
+	GetNodeComponents() ([]base.Component, error)
+	GetNodeComponentsCtx(ctx context.Context) ([]base.Component, error)
+	GetNodeMaps() ([]sm.NodeMap, error)
+	GetNodeMapsCtx(ctx context.Context) ([]sm.NodeMap, error)
+
+	GetRedfishEndpoints() ([]sm.RedfishEndpoint, error)
+	GetRedfishEndpointsCtx(ctx context.Context) ([]sm.RedfishEndpoint, error)
+	GetComponentEndpoints() ([]sm.ComponentEndpoint, error)
+	GetComponentEndpointsCtx(ctx context.Context) ([]sm.ComponentEndpoint, error)
+	ResolveUnknownComponents() ([]ComponentMatch, error)
+	ResolveUnknownComponentsCtx(ctx context.Context) ([]ComponentMatch, error)
+	ApplyComponentMatches(matches []ComponentMatch, minConfidence MatchConfidence) ([]ComponentMatch, error)
+	ApplyComponentMatchesCtx(ctx context.Context, matches []ComponentMatch,
+		minConfidence MatchConfidence) ([]ComponentMatch, error)
+
+	AnalyzeStaleInterfaces(minAge time.Duration) (*StaleReport, error)
+	AnalyzeStaleInterfacesCtx(ctx context.Context, minAge time.Duration) (*StaleReport, error)
+	PruneStaleInterfaces(report *StaleReport, opts PruneOptions) ([]InterfaceAge, error)
+	PruneStaleInterfacesCtx(ctx context.Context, report *StaleReport, opts PruneOptions) ([]InterfaceAge, error)
+	FindIPConflicts() ([]IPConflict, error)
+	FindIPConflictsCtx(ctx context.Context) ([]IPConflict, error)
+	CheckTopology(checker *TopologyChecker) ([]TopologyViolation, error)
+	CheckTopologyCtx(ctx context.Context, checker *TopologyChecker) ([]TopologyViolation, error)
+	GenerateKeaDhcp4(gen *KeaGenerator) (*KeaDhcp4Config, []Unplaced, error)
+	GenerateKeaDhcp4Ctx(ctx context.Context, gen *KeaGenerator) (*KeaDhcp4Config, []Unplaced, error)
+	GenerateKeaDhcp6(gen *KeaGenerator) (*KeaDhcp6Config, []Unplaced, error)
+	GenerateKeaDhcp6Ctx(ctx context.Context, gen *KeaGenerator) (*KeaDhcp6Config, []Unplaced, error)
+	WriteDhcpdHosts(w io.Writer, gen *DhcpdGenerator) ([]Unplaced, error)
+	WriteDhcpdHostsCtx(ctx context.Context, w io.Writer, gen *DhcpdGenerator) ([]Unplaced, error)
+	WriteDnsmasqHosts(w io.Writer, gen *DnsmasqGenerator) ([]Unplaced, error)
+	WriteDnsmasqHostsCtx(ctx context.Context, w io.Writer, gen *DnsmasqGenerator) ([]Unplaced, error)
+	IngestLeases(ingester *LeaseIngester, leases []KeaLease4) ([]IngestResult, []Unplaced, error)
+	IngestLeasesCtx(ctx context.Context, ingester *LeaseIngester, leases []KeaLease4) ([]IngestResult, []Unplaced,
+		error)
+	IngestKeaLeases4(kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) ([]IngestResult, []Unplaced, error)
+	IngestKeaLeases4Ctx(ctx context.Context, kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) (
+		[]IngestResult, []Unplaced, error)
+	IngestLeases6(ingester *LeaseIngester, leases []KeaLease6) ([]IngestResult, []Unplaced, error)
+	IngestLeases6Ctx(ctx context.Context, ingester *LeaseIngester, leases []KeaLease6) ([]IngestResult, []Unplaced,
+		error)
+	IngestKeaLeases6(kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) ([]IngestResult, []Unplaced, error)
+	IngestKeaLeases6Ctx(ctx context.Context, kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) (
+		[]IngestResult, []Unplaced, error)
+}
+
+var _ Client = (*DNSDHCPHelper)(nil)
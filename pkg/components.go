@@ -0,0 +1,261 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// EthernetInterfaces only carry a ComponentID. Names such as nid000123 or
+// uan01 need the node's NID, Role and SubRole, which live in HSM's
+// State/Components (for discovered nodes) and Defaults/NodeMaps (for the
+// defaults applied at discovery). A ComponentCache loads both once per
+// sync cycle and hands out a ComponentIndex for the naming engine.
+
+// getJSON GETs the HSM API path apiPath, which may carry a query, and
+// decodes the response into result.
+func (helper *DNSDHCPHelper) getJSON(ctx context.Context, apiPath string, result interface{}) (err error) {
+	if _, err = helper.negotiateAPIVersion(ctx); err != nil {
+		return
+	}
+	response, err := rtGet(ctx, helper, helper.hsmURL(apiPath))
+	if err != nil {
+		return
+	}
+
+	jsonBytes, err := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(jsonBytes, result)
+
+	return
+}
+
+func (helper *DNSDHCPHelper) GetComponents(types ...string) (components []base.Component, err error) {
+	return helper.GetComponentsCtx(context.Background(), types...)
+}
+
+// GetComponentsCtx returns the components in HSM of any of the given HMS
+// types, e.g. "Node", or every component if no types are given.
+func (helper *DNSDHCPHelper) GetComponentsCtx(ctx context.Context, types ...string) (
+	components []base.Component, err error) {
+	var compArray base.ComponentArray
+	query := url.Values{"type": types}
+	compPath := "/State/Components"
+	if len(types) > 0 {
+		compPath += "?" + query.Encode()
+	}
+	if err = helper.getJSON(ctx, compPath, &compArray); err != nil {
+		return
+	}
+	for _, component := range compArray.Components {
+		if component != nil {
+			components = append(components, *component)
+		}
+	}
+	return
+}
+
+func (helper *DNSDHCPHelper) GetNodeComponents() (components []base.Component, err error) {
+	return helper.GetNodeComponentsCtx(context.Background())
+}
+
+// GetNodeComponentsCtx returns the Node components in HSM, with their NID,
+// Role and SubRole.
+func (helper *DNSDHCPHelper) GetNodeComponentsCtx(ctx context.Context) (components []base.Component, err error) {
+	return helper.GetComponentsCtx(ctx, xnametypes.Node.String())
+}
+
+func (helper *DNSDHCPHelper) GetNodeMaps() (nodeMaps []sm.NodeMap, err error) {
+	return helper.GetNodeMapsCtx(context.Background())
+}
+
+// GetNodeMapsCtx returns HSM's default NID, Role and SubRole for each node
+// xname.
+func (helper *DNSDHCPHelper) GetNodeMapsCtx(ctx context.Context) (nodeMaps []sm.NodeMap, err error) {
+	var nodeMapArray sm.NodeMapArray
+	if err = helper.getJSON(ctx, "/Defaults/NodeMaps", &nodeMapArray); err != nil {
+		return
+	}
+	for _, nodeMap := range nodeMapArray.NodeMaps {
+		if nodeMap != nil {
+			nodeMaps = append(nodeMaps, *nodeMap)
+		}
+	}
+	return
+}
+
+// ComponentInfo is what the naming engine knows about a node.
+type ComponentInfo struct {
+	ID      string
+	NID     int
+	Role    string
+	SubRole string
+
+	// RoleIndex is the 1-based position of the node, ordered by NID, among
+	// the nodes with the same Role and SubRole, e.g. 1 for uan01.
+	RoleIndex int
+}
+
+// ComponentIndex is a read-only snapshot of node components, keyed by
+// xname.
+type ComponentIndex struct {
+	components map[string]ComponentInfo
+}
+
+// NewComponentIndex builds an index from HSM components and node maps.
+// Values from components take precedence; node maps fill in whatever a
+// component doesn't have, and add nodes that haven't been discovered yet.
+func NewComponentIndex(components []base.Component, nodeMaps []sm.NodeMap) *ComponentIndex {
+	index := &ComponentIndex{components: make(map[string]ComponentInfo)}
+
+	for _, nodeMap := range nodeMaps {
+		id := xnametypes.NormalizeHMSCompID(nodeMap.ID)
+		index.components[id] = ComponentInfo{
+			ID:      id,
+			NID:     nodeMap.NID,
+			Role:    nodeMap.Role,
+			SubRole: nodeMap.SubRole,
+		}
+	}
+	for _, component := range components {
+		id := xnametypes.NormalizeHMSCompID(component.ID)
+		info := index.components[id]
+		info.ID = id
+		if nid, err := strconv.Atoi(component.NID.String()); err == nil && nid > 0 {
+			info.NID = nid
+		}
+		if component.Role != "" {
+			info.Role = component.Role
+		}
+		if component.SubRole != "" {
+			info.SubRole = component.SubRole
+		}
+		index.components[id] = info
+	}
+
+	// Number the nodes of each Role/SubRole pair.
+	groups := make(map[string][]string)
+	for id, info := range index.components {
+		if info.Role == "" {
+			continue
+		}
+		key := info.Role + "/" + info.SubRole
+		groups[key] = append(groups[key], id)
+	}
+	for _, ids := range groups {
+		sort.Slice(ids, func(i, j int) bool {
+			infoI, infoJ := index.components[ids[i]], index.components[ids[j]]
+			if infoI.NID != infoJ.NID {
+				return infoI.NID < infoJ.NID
+			}
+			return infoI.ID < infoJ.ID
+		})
+		for ix, id := range ids {
+			info := index.components[id]
+			info.RoleIndex = ix + 1
+			index.components[id] = info
+		}
+	}
+	return index
+}
+
+// Lookup returns what is known about the node xname.
+func (index *ComponentIndex) Lookup(xname string) (info ComponentInfo, ok bool) {
+	if index == nil {
+		return
+	}
+	info, ok = index.components[xnametypes.NormalizeHMSCompID(xname)]
+	return
+}
+
+// Len returns the number of nodes in the index.
+func (index *ComponentIndex) Len() int {
+	if index == nil {
+		return 0
+	}
+	return len(index.components)
+}
+
+// ComponentCache loads a ComponentIndex from HSM on first use and keeps it
+// until Reset(), which callers should do at the start of each sync cycle.
+// It is safe for concurrent use.
+type ComponentCache struct {
+	helper *DNSDHCPHelper
+
+	mu    sync.Mutex
+	index *ComponentIndex
+}
+
+// NewComponentCache creates an empty cache backed by helper.
+func (helper *DNSDHCPHelper) NewComponentCache() *ComponentCache {
+	return &ComponentCache{helper: helper}
+}
+
+// Load returns the cached index, loading it from HSM if the cache is
+// empty. A failed load is not cached.
+func (cache *ComponentCache) Load(ctx context.Context) (*ComponentIndex, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.index != nil {
+		return cache.index, nil
+	}
+
+	components, err := cache.helper.GetNodeComponentsCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node components: %w", err)
+	}
+	nodeMaps, err := cache.helper.GetNodeMapsCtx(ctx)
+	if err != nil && !IsHSMErrorClass(err, HSMErrClassNotFound) {
+		return nil, fmt.Errorf("failed to get node maps: %w", err)
+	}
+
+	cache.index = NewComponentIndex(components, nodeMaps)
+	return cache.index, nil
+}
+
+// Reset empties the cache so the next Load() goes to HSM.
+func (cache *ComponentCache) Reset() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.index = nil
+}
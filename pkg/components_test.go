@@ -0,0 +1,149 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var compsReqs int
+
+func handleComponents(w http.ResponseWriter, req *http.Request) {
+	compsReqs++
+	var payload interface{}
+	switch req.URL.Path {
+	case "/hsm/v2/State/Components":
+		if (req.URL.Query().Get("type") != "Node") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payload = base.ComponentArray{Components: []*base.Component{
+			{ID: "x3000c0s1b0n0", Type: "Node", NID: "100001", Role: "Management", SubRole: "Master"},
+			{ID: "x3000c0s2b0n0", Type: "Node", NID: "100002", Role: "Management", SubRole: "Worker"},
+			{ID: "x3000c0s3b0n0", Type: "Node", NID: "100003", Role: "Management", SubRole: "Worker"},
+			{ID: "x3000c0s9b0n0", Type: "Node", Role: "Application", SubRole: "UAN"},
+			{ID: "x1000c0s0b0n0", Type: "Node", NID: "1000", Role: "Compute"},
+		}}
+	case "/hsm/v2/Defaults/NodeMaps":
+		payload = sm.NodeMapArray{NodeMaps: []*sm.NodeMap{
+			{ID: "x3000c0s9b0n0", NID: 49, Role: "Compute"},
+			{ID: "x3000c0s8b0n0", NID: 48, Role: "Application", SubRole: "UAN"},
+		}}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	ba, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+func TestComponentIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleComponents))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	components, err := hlp.GetNodeComponents()
+	if (err != nil || len(components) != 5) {
+		t.Errorf("ERROR, GetNodeComponents() returned %d components, error: %v", len(components), err)
+	}
+	nodeMaps, err := hlp.GetNodeMaps()
+	if (err != nil || len(nodeMaps) != 2) {
+		t.Errorf("ERROR, GetNodeMaps() returned %d node maps, error: %v", len(nodeMaps), err)
+	}
+
+	index := NewComponentIndex(components, nodeMaps)
+	tests := []ComponentInfo{
+		{ID: "x3000c0s1b0n0", NID: 100001, Role: "Management", SubRole: "Master", RoleIndex: 1},
+		{ID: "x3000c0s3b0n0", NID: 100003, Role: "Management", SubRole: "Worker", RoleIndex: 2},
+		// The component's Role wins, the node map fills in the NID.
+		{ID: "x3000c0s9b0n0", NID: 49, Role: "Application", SubRole: "UAN", RoleIndex: 2},
+		// Only in the node maps.
+		{ID: "x3000c0s8b0n0", NID: 48, Role: "Application", SubRole: "UAN", RoleIndex: 1},
+		{ID: "x1000c0s0b0n0", NID: 1000, Role: "Compute", RoleIndex: 1},
+	}
+	for _, exp := range tests {
+		info, ok := index.Lookup(exp.ID)
+		if (!ok || !reflect.DeepEqual(info, exp)) {
+			t.Errorf("ERROR, Lookup(%s) returned %+v, expected %+v", exp.ID, info, exp)
+		}
+	}
+	if _, ok := index.Lookup("x3000c0s1b0"); (ok) {
+		t.Errorf("ERROR, Lookup() found a BMC.")
+	}
+	if (index.Len() != 6) {
+		t.Errorf("ERROR, expected 6 nodes in the index, got %d", index.Len())
+	}
+}
+
+func TestComponentCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleComponents))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	cache := hlp.NewComponentCache()
+
+	compsReqs = 0
+	first, err := cache.Load(context.Background())
+	if (err != nil) {
+		t.Fatalf("ERROR, Load() error: %v", err)
+	}
+	second, _ := cache.Load(context.Background())
+	if (first != second || compsReqs != 2) {
+		t.Errorf("ERROR, Load() didn't cache, %d requests made", compsReqs)
+	}
+
+	cache.Reset()
+	third, _ := cache.Load(context.Background())
+	if (third == first || compsReqs != 4) {
+		t.Errorf("ERROR, Load() after Reset() didn't reload, %d requests made", compsReqs)
+	}
+
+	// Node maps are optional, components are not.
+	noMaps := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if (req.URL.Path == "/hsm/v2/Defaults/NodeMaps") {
+			base.SendProblemDetailsGeneric(w, http.StatusNotFound, "no node maps")
+			return
+		}
+		handleComponents(w, req)
+	}))
+	defer noMaps.Close()
+
+	index, err := New(noMaps.URL).NewComponentCache().Load(context.Background())
+	if (err != nil || index.Len() != 5) {
+		t.Errorf("ERROR, Load() without node maps returned %d nodes, error: %v", index.Len(), err)
+	}
+
+	noComps := New(noMaps.URL, WithAPIVersion("v3"))
+	if _, err = noComps.NewComponentCache().Load(context.Background()); (err == nil) {
+		t.Errorf("ERROR, Load() succeeded without components.")
+	}
+}
@@ -0,0 +1,245 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// HSM lets any number of EthernetInterfaces claim the same IP address,
+// which leaves DNS with several A records for one name, or one address for
+// several names. FindIPConflicts() reports such addresses, and
+// WithIPConflictCheck() checks for them before an interface is added.
+//
+// An IP address mapping with no Network is taken to be on whatever network
+// the other claims to the address are on.
+
+var ErrIPConflict = base.NewHMSError("ip-conflict", "IP address is already claimed by another interface")
+
+// IPClaim is one interface claiming an IP address.
+type IPClaim struct {
+	ID         string `json:"ID"`
+	MACAddr    string `json:"MACAddress"`
+	CompID     string `json:"ComponentID,omitempty"`
+	LastUpdate string `json:"LastUpdate,omitempty"`
+}
+
+// IPConflict is an IP address on a network claimed by more than one
+// interface.
+type IPConflict struct {
+	IPAddr  string    `json:"IPAddress"`
+	Network string    `json:"Network,omitempty"`
+	Claims  []IPClaim `json:"Claims"`
+}
+
+// IPConflictError is returned when WithIPConflictCheck(IPConflictRefuse, ...)
+// refuses an interface. It matches ErrIPConflict with errors.Is().
+type IPConflictError struct {
+	Conflicts []IPConflict
+}
+
+func (e *IPConflictError) Error() string {
+	var descs []string
+	for _, conflict := range e.Conflicts {
+		var claimants []string
+		for _, claim := range conflict.Claims {
+			claimants = append(claimants, claim.ID)
+		}
+		desc := conflict.IPAddr
+		if conflict.Network != "" {
+			desc += " (" + conflict.Network + ")"
+		}
+		descs = append(descs, desc+" claimed by "+strings.Join(claimants, ", "))
+	}
+	return fmt.Sprintf("%s: %s", ErrIPConflict.Error(), strings.Join(descs, "; "))
+}
+
+func (e *IPConflictError) Unwrap() error {
+	return ErrIPConflict
+}
+
+// ipKey returns a canonical form of an IP address so that e.g. "fd00::01"
+// and "fd00::1" compare equal. Unparsable addresses are used as-is.
+func ipKey(ipAddr string) string {
+	if addr, err := netip.ParseAddr(ipAddr); err == nil {
+		return addr.Unmap().String()
+	}
+	return ipAddr
+}
+
+type ipClaimant struct {
+	network string
+	claim   IPClaim
+}
+
+// FindIPConflicts returns every IP address and network claimed by more
+// than one interface, with all the interfaces claiming it, sorted by IP
+// address.
+func FindIPConflicts(ethInterfaces []sm.CompEthInterfaceV2) []IPConflict {
+	claimants := make(map[string][]ipClaimant)
+	for _, ethInterface := range ethInterfaces {
+		for _, ipm := range ethInterface.IPAddrs {
+			if ipm.IPAddr == "" {
+				continue
+			}
+			key := ipKey(ipm.IPAddr)
+			claimants[key] = append(claimants[key], ipClaimant{
+				network: strings.ToUpper(ipm.Network),
+				claim: IPClaim{
+					ID:         ethInterface.ID,
+					MACAddr:    ethInterface.MACAddr,
+					CompID:     ethInterface.CompID,
+					LastUpdate: ethInterface.LastUpdate,
+				},
+			})
+		}
+	}
+
+	var conflicts []IPConflict
+	for ipAddr, ipClaimants := range claimants {
+		networks := make(map[string]bool)
+		for _, claimant := range ipClaimants {
+			if claimant.network != "" {
+				networks[claimant.network] = true
+			}
+		}
+		if len(networks) == 0 {
+			networks[""] = true
+		}
+
+		for network := range networks {
+			var claims []IPClaim
+			seen := make(map[string]bool)
+			for _, claimant := range ipClaimants {
+				if claimant.network != "" && claimant.network != network {
+					continue
+				}
+				if !seen[claimant.claim.ID] {
+					seen[claimant.claim.ID] = true
+					claims = append(claims, claimant.claim)
+				}
+			}
+			if len(claims) > 1 {
+				sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+				conflicts = append(conflicts, IPConflict{IPAddr: ipAddr, Network: network, Claims: claims})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].IPAddr != conflicts[j].IPAddr {
+			return conflicts[i].IPAddr < conflicts[j].IPAddr
+		}
+		return conflicts[i].Network < conflicts[j].Network
+	})
+	return conflicts
+}
+
+func (helper *DNSDHCPHelper) FindIPConflicts() (conflicts []IPConflict, err error) {
+	return helper.FindIPConflictsCtx(context.Background())
+}
+
+// FindIPConflictsCtx runs FindIPConflicts() over every interface in HSM.
+func (helper *DNSDHCPHelper) FindIPConflictsCtx(ctx context.Context) (conflicts []IPConflict, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	return FindIPConflicts(ethInterfaces), nil
+}
+
+// IPConflictPolicy is what AddNewEthernetInterface does about IP addresses
+// already claimed by other interfaces.
+type IPConflictPolicy int
+
+const (
+	// Don't check (the default).
+	IPConflictIgnore IPConflictPolicy = iota
+	// Report conflicts to the warn function and add the interface anyway.
+	IPConflictWarn
+	// Refuse the interface with an *IPConflictError.
+	IPConflictRefuse
+)
+
+// WithIPConflictCheck makes AddNewEthernetInterface look up the new
+// interface's IP addresses in HSM first and apply policy to any that other
+// interfaces already hold. warn, which may be nil, is called with each
+// conflict found under IPConflictWarn.
+func WithIPConflictCheck(policy IPConflictPolicy, warn func(conflict IPConflict)) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.ipConflictPolicy = policy
+		helper.ipConflictWarn = warn
+	}
+}
+
+// checkIPConflicts applies the helper's IP conflict policy to newInterface.
+func (helper *DNSDHCPHelper) checkIPConflicts(ctx context.Context, newInterface sm.CompEthInterfaceV2) error {
+	if helper.ipConflictPolicy == IPConflictIgnore || len(newInterface.IPAddrs) == 0 {
+		return nil
+	}
+
+	var filter EthernetInterfaceFilter
+	for _, ipm := range newInterface.IPAddrs {
+		if ipm.IPAddr != "" {
+			filter.IPAddress = append(filter.IPAddress, ipm.IPAddr)
+		}
+	}
+	if len(filter.IPAddress) == 0 {
+		return nil
+	}
+	claimants, err := helper.QueryEthernetInterfacesCtx(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to check for IP address conflicts: %w", err)
+	}
+
+	// Only conflicts involving the new interface count; others are
+	// pre-existing and not this call's business.
+	var conflicts []IPConflict
+	for _, conflict := range FindIPConflicts(append(claimants, newInterface)) {
+		for _, claim := range conflict.Claims {
+			if claim.ID == newInterface.ID {
+				conflicts = append(conflicts, conflict)
+				break
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	if helper.ipConflictPolicy == IPConflictWarn {
+		if helper.ipConflictWarn != nil {
+			for _, conflict := range conflicts {
+				helper.ipConflictWarn(conflict)
+			}
+		}
+		return nil
+	}
+	return &IPConflictError{Conflicts: conflicts}
+}
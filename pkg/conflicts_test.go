@@ -0,0 +1,69 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestFindIPConflicts(t *testing.T) {
+	ethInterfaces := []sm.CompEthInterfaceV2{
+		{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01", CompID: "x3000c0s1b0n0", LastUpdate: "2026-01-01T00:00:00Z",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}, {IPAddr: "fd00::1", Network: "NMN"}}},
+		{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02", CompID: "x3000c0s2b0n0", LastUpdate: "2026-10-01T00:00:00Z",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "nmn"}, {IPAddr: "10.254.1.5", Network: "HMN"}}},
+		// Same address on a different network isn't a conflict.
+		{ID: "a4bf01000003", MACAddr: "a4:bf:01:00:00:03",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "MTL"}}},
+		// No network conflicts with any, and equal IPv6 spellings match.
+		{ID: "a4bf01000004", MACAddr: "a4:bf:01:00:00:04",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "fd00:0::01"}}},
+		// An interface listing an address twice doesn't conflict with itself.
+		{ID: "a4bf01000005", MACAddr: "a4:bf:01:00:00:05",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.9"}, {IPAddr: "10.252.1.9"}}},
+	}
+
+	conflicts := FindIPConflicts(ethInterfaces)
+	if (len(conflicts) != 2) {
+		t.Fatalf("ERROR, expected 2 conflicts, got %v", conflicts)
+	}
+	if (conflicts[0].IPAddr != "10.252.1.5" || conflicts[0].Network != "NMN" || len(conflicts[0].Claims) != 2) {
+		t.Errorf("ERROR, unexpected conflict %v", conflicts[0])
+	}
+	claim := conflicts[0].Claims[1]
+	if (claim.ID != "a4bf01000002" || claim.CompID != "x3000c0s2b0n0" || claim.LastUpdate != "2026-10-01T00:00:00Z") {
+		t.Errorf("ERROR, unexpected claim %v", claim)
+	}
+	if (conflicts[1].IPAddr != "fd00::1" || len(conflicts[1].Claims) != 2 ||
+		conflicts[1].Claims[1].ID != "a4bf01000004") {
+		t.Errorf("ERROR, unexpected conflict %v", conflicts[1])
+	}
+
+	err := error(&IPConflictError{Conflicts: conflicts[:1]})
+	if (!errors.Is(err, ErrIPConflict) ||
+		err.Error() != ErrIPConflict.Error()+": 10.252.1.5 (NMN) claimed by a4bf01000001, a4bf01000002") {
+		t.Errorf("ERROR, unexpected IPConflictError: %v", err)
+	}
+}
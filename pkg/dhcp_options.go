@@ -0,0 +1,301 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"path"
+	"strings"
+	"text/template"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Boot and vendor options differ by component type and network: compute
+// nodes chain-load iPXE, BMCs need nothing and switches want option 43 or
+// 66. An OptionPolicy holds these as rules. Every rule that matches an
+// interface address applies, in order; an option set by a later rule
+// replaces one with the same name (or code) from an earlier rule. Option
+// data, next-server and boot file name are text/template templates
+// executed with the address's PolicyData, and an option whose data comes
+// out empty is left out, so "{{if .NID}}...{{end}}" limits an option to
+// nodes with a NID.
+
+// HMSError classes of the policy errors.
+const (
+	PolicyErrClassBadTemplate = "policy-bad-template"
+	PolicyErrClassBadValue    = "policy-bad-value"
+)
+
+var ErrPolicyBadTemplate = base.NewHMSError(PolicyErrClassBadTemplate, "invalid DHCP option template")
+var ErrPolicyBadValue = base.NewHMSError(PolicyErrClassBadValue, "invalid DHCP option value")
+
+// OptionRule sets DHCP options on the reservations of matching interface
+// addresses.
+type OptionRule struct {
+	// What the rule applies to. Empty fields match anything. Type is an HMS
+	// type, e.g. "Node"; it is the interface's Type, else that of its
+	// ComponentID. Xname is a glob (see path.Match) on the ComponentID, e.g.
+	// "x3000c0s*b0n0". Role and SubRole only match nodes found in the
+	// ComponentIndex.
+	Network string `json:"Network,omitempty"`
+	Type    string `json:"Type,omitempty"`
+	Role    string `json:"Role,omitempty"`
+	SubRole string `json:"SubRole,omitempty"`
+	Xname   string `json:"Xname,omitempty"`
+
+	// The DHCPv4 next-server (siaddr), an IPv4 address, and boot file name.
+	// Empty keeps those of earlier rules.
+	NextServer   string       `json:"NextServer,omitempty"`
+	BootFileName string       `json:"BootFileName,omitempty"`
+	Options      []DHCPOption `json:"Options,omitempty"`
+}
+
+// PolicyData is what OptionRule templates are executed with.
+type PolicyData struct {
+	ID       string // The interface ID.
+	MACAddr  string // Canonical, e.g. "a4:bf:01:2e:7f:b1".
+	CompID   string // Normalized.
+	Type     string // The HMS type, see OptionRule.
+	Desc     string
+	IPAddr   string
+	Network  string
+	SubnetID int
+	CIDR     string // The subnet's.
+
+	// From the ComponentIndex, for nodes only.
+	NID     int
+	Role    string
+	SubRole string
+}
+
+// OptionSet is what an OptionPolicy yields for one interface address.
+type OptionSet struct {
+	NextServer   string
+	BootFileName string
+	Options      []DHCPOption
+}
+
+type compiledRule struct {
+	OptionRule
+	nextServer   *template.Template
+	bootFileName *template.Template
+	data         []*template.Template
+}
+
+// OptionPolicy computes the DHCP options of interface addresses from a list
+// of rules. It is safe for concurrent use.
+type OptionPolicy struct {
+	rules      []compiledRule
+	components *ComponentIndex
+}
+
+// NewOptionPolicy creates an OptionPolicy, checking the rules and compiling
+// their templates.
+func NewOptionPolicy(rules []OptionRule) (*OptionPolicy, error) {
+	policy := &OptionPolicy{}
+	for ix, rule := range rules {
+		compiled := compiledRule{OptionRule: rule}
+		if rule.Xname != "" {
+			compiled.Xname = strings.ToLower(rule.Xname)
+			if _, err := path.Match(compiled.Xname, ""); err != nil {
+				return nil, fmt.Errorf("option rule %d Xname '%s': %w", ix, rule.Xname, err)
+			}
+		}
+
+		var err error
+		parse := func(name string, text string) *template.Template {
+			if text == "" || err != nil {
+				return nil
+			}
+			var parsed *template.Template
+			parsed, err = template.New(name).Option("missingkey=error").Parse(text)
+			if err != nil {
+				err = fmt.Errorf("option rule %d %s: %w: %v", ix, name, ErrPolicyBadTemplate, err)
+			}
+			return parsed
+		}
+		compiled.nextServer = parse("NextServer", rule.NextServer)
+		compiled.bootFileName = parse("BootFileName", rule.BootFileName)
+		for _, option := range rule.Options {
+			if option.Name == "" && option.Code <= 0 {
+				return nil, fmt.Errorf("option rule %d: %w: option needs a name or code", ix, ErrPolicyBadValue)
+			}
+			compiled.data = append(compiled.data, parse("option "+optionKey(option), option.Data))
+		}
+		if err != nil {
+			return nil, err
+		}
+		policy.rules = append(policy.rules, compiled)
+	}
+	return policy, nil
+}
+
+// WithComponents returns a copy of the policy that takes node NIDs, Roles
+// and SubRoles from index. Call it with a fresh index every sync cycle.
+func (policy *OptionPolicy) WithComponents(index *ComponentIndex) *OptionPolicy {
+	bound := *policy
+	bound.components = index
+	return &bound
+}
+
+// Data returns the PolicyData of placement.
+func (policy *OptionPolicy) Data(placement Placement) PolicyData {
+	ethInterface := placement.Interface
+	data := PolicyData{
+		ID:       ethInterface.ID,
+		MACAddr:  placement.MAC.String(),
+		CompID:   xnametypes.NormalizeHMSCompID(ethInterface.CompID),
+		Type:     ethInterface.Type,
+		Desc:     ethInterface.Desc,
+		IPAddr:   placement.IPAddr.String(),
+		Network:  placement.Network,
+		SubnetID: placement.Subnet.ID,
+		CIDR:     placement.Subnet.CIDR,
+	}
+
+	hmsType := xnametypes.GetHMSType(data.CompID)
+	if data.Type == "" && hmsType != xnametypes.HMSTypeInvalid {
+		data.Type = hmsType.String()
+	}
+	// A NIC takes the role of the node it is in.
+	owner := data.CompID
+	if ownerTypes[hmsType] {
+		owner = xnametypes.GetHMSCompParent(owner)
+	}
+	if info, found := policy.components.Lookup(owner); found {
+		data.NID = info.NID
+		data.Role = info.Role
+		data.SubRole = info.SubRole
+	}
+	return data
+}
+
+// matches is true if rule applies to data.
+func (rule *compiledRule) matches(data PolicyData) bool {
+	switch {
+	case rule.Network != "" && !strings.EqualFold(rule.Network, data.Network),
+		rule.Type != "" && !strings.EqualFold(rule.Type, data.Type),
+		rule.Role != "" && !strings.EqualFold(rule.Role, data.Role),
+		rule.SubRole != "" && !strings.EqualFold(rule.SubRole, data.SubRole):
+		return false
+	case rule.Xname != "":
+		matched, _ := path.Match(rule.Xname, strings.ToLower(data.CompID))
+		return matched
+	}
+	return true
+}
+
+// Evaluate applies every matching rule to placement.
+func (policy *OptionPolicy) Evaluate(placement Placement) (set OptionSet, err error) {
+	data := policy.Data(placement)
+	var options []DHCPOption
+
+	for ix := range policy.rules {
+		rule := &policy.rules[ix]
+		if !rule.matches(data) {
+			continue
+		}
+		if rule.nextServer != nil {
+			if set.NextServer, err = executeOption(rule.nextServer, data); err != nil {
+				return
+			}
+		}
+		if rule.bootFileName != nil {
+			if set.BootFileName, err = executeOption(rule.bootFileName, data); err != nil {
+				return
+			}
+		}
+		for oix, option := range rule.Options {
+			if rule.data[oix] != nil {
+				if option.Data, err = executeOption(rule.data[oix], data); err != nil {
+					return
+				}
+			}
+			options = mergeOptions(options, []DHCPOption{option})
+		}
+	}
+
+	if set.NextServer != "" {
+		if addr, parseErr := netip.ParseAddr(set.NextServer); parseErr != nil || !addr.Is4() {
+			err = fmt.Errorf("next-server '%s' for %s: %w: not an IPv4 address", set.NextServer, data.ID,
+				ErrPolicyBadValue)
+			return
+		}
+	}
+	if strings.ContainsAny(set.BootFileName, "\"\\\r\n") {
+		err = fmt.Errorf("boot file name '%s' for %s: %w", set.BootFileName, data.ID, ErrPolicyBadValue)
+		return
+	}
+	for _, option := range options {
+		if option.Data != "" {
+			set.Options = append(set.Options, option)
+		}
+	}
+	return
+}
+
+// Options returns only the options of Evaluate(); it fits
+// KeaGenerator.Options.
+func (policy *OptionPolicy) Options(placement Placement) ([]DHCPOption, error) {
+	set, err := policy.Evaluate(placement)
+	return set.Options, err
+}
+
+// mergeOptions returns options with those of more added; each replaces
+// the option with the same key, in place.
+func mergeOptions(options []DHCPOption, more []DHCPOption) []DHCPOption {
+	merged := append([]DHCPOption(nil), options...)
+	for _, option := range more {
+		replaced := false
+		for ix := range merged {
+			if optionKey(merged[ix]) == optionKey(option) {
+				merged[ix] = option
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, option)
+		}
+	}
+	return merged
+}
+
+// optionKey identifies an option within its space.
+func optionKey(option DHCPOption) string {
+	if option.Name != "" {
+		return option.Space + "/" + strings.ToLower(option.Name)
+	}
+	return fmt.Sprintf("%s/%d", option.Space, option.Code)
+}
+
+func executeOption(optionTemplate *template.Template, data PolicyData) (string, error) {
+	var buf bytes.Buffer
+	if err := optionTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s template: %w: %v", optionTemplate.Name(), ErrPolicyBadTemplate, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
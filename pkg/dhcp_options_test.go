@@ -0,0 +1,145 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var testOptionRules = []OptionRule{
+	{Network: "NMN", Type: "Node", NextServer: "10.252.0.2", BootFileName: "ipxe.efi",
+		Options: []DHCPOption{{Name: "domain-name", Data: "nmn"}}},
+	{Network: "NMN", Role: "Compute", BootFileName: "ipxe-{{.NID}}.efi",
+		Options: []DHCPOption{{Name: "host-name", Data: "{{if .NID}}nid{{printf \"%06d\" .NID}}{{end}}"}}},
+	{Type: "MgmtSwitch", Options: []DHCPOption{{Code: 66, Data: "10.254.0.2"}, {Code: 43, Data: "01:04:{{.IPAddr}}"}}},
+	{Xname: "x3000c0w2*", Options: []DHCPOption{{Code: 43, Data: ""}, {Name: "DOMAIN-NAME", Data: "hmn"}}},
+}
+
+func testPlacement(t *testing.T, ethInterface sm.CompEthInterfaceV2, ipAddr string, network string) Placement {
+	hwAddr, err := mac.Parse(ethInterface.MACAddr)
+	if (err != nil) {
+		t.Fatalf("ERROR, mac.Parse(%s) failed: %v", ethInterface.MACAddr, err)
+	}
+	prefix := netip.MustParsePrefix("10.0.0.0/8")
+	return Placement{
+		Interface: ethInterface,
+		Subnet:    &Subnet{ID: 10, CIDR: prefix.String(), prefix: prefix},
+		MAC:       hwAddr,
+		IPAddr:    netip.MustParseAddr(ipAddr),
+		Network:   network,
+	}
+}
+
+func TestOptionPolicy(t *testing.T) {
+	policy, err := NewOptionPolicy(testOptionRules)
+	if (err != nil) {
+		t.Fatalf("ERROR, NewOptionPolicy() failed: %v", err)
+	}
+	policy = policy.WithComponents(NewComponentIndex([]base.Component{
+		{ID: "x1000c0s0b0n0", Type: "Node", NID: "123", Role: "Compute"},
+	}, nil))
+
+	tests := []struct {
+		ethInterface sm.CompEthInterfaceV2
+		ipAddr       string
+		network      string
+		expected     OptionSet
+	}{
+		// Type comes from the ComponentID if the interface has none.
+		{sm.CompEthInterfaceV2{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01", CompID: "x3000c0s1b0n0"},
+			"10.252.1.11", "NMN",
+			OptionSet{NextServer: "10.252.0.2", BootFileName: "ipxe.efi",
+				Options: []DHCPOption{{Name: "domain-name", Data: "nmn"}}}},
+		// Later rules override earlier ones; a NIC gets its node's role.
+		{sm.CompEthInterfaceV2{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02", CompID: "x1000c0s0b0n0i0",
+			Type: "Node"},
+			"10.252.1.12", "NMN",
+			OptionSet{NextServer: "10.252.0.2", BootFileName: "ipxe-123.efi",
+				Options: []DHCPOption{{Name: "domain-name", Data: "nmn"}, {Name: "host-name", Data: "nid000123"}}}},
+		// BMCs match no rule.
+		{sm.CompEthInterfaceV2{ID: "a4bf01000003", MACAddr: "a4:bf:01:00:00:03", CompID: "x3000c0s1b0"},
+			"10.254.1.11", "HMN", OptionSet{}},
+		// An option that templates to nothing is left out.
+		{sm.CompEthInterfaceV2{ID: "a4bf01000004", MACAddr: "a4:bf:01:00:00:04", CompID: "x3000c0w1"},
+			"10.254.0.5", "HMN",
+			OptionSet{Options: []DHCPOption{{Code: 66, Data: "10.254.0.2"}, {Code: 43, Data: "01:04:10.254.0.5"}}}},
+		{sm.CompEthInterfaceV2{ID: "a4bf01000005", MACAddr: "a4:bf:01:00:00:05", CompID: "x3000c0w22"},
+			"10.254.0.6", "HMN",
+			OptionSet{Options: []DHCPOption{{Code: 66, Data: "10.254.0.2"}, {Name: "DOMAIN-NAME", Data: "hmn"}}}},
+	}
+
+	for _, test := range tests {
+		set, err := policy.Evaluate(testPlacement(t, test.ethInterface, test.ipAddr, test.network))
+		if (err != nil || !reflect.DeepEqual(set, test.expected)) {
+			t.Errorf("ERROR, Evaluate(%s) returned %v, error: %v; expected %v", test.ethInterface.ID, set, err,
+				test.expected)
+		}
+	}
+
+	data := policy.Data(testPlacement(t, tests[1].ethInterface, "10.252.1.12", "NMN"))
+	if (data.CompID != "x1000c0s0b0n0i0" || data.Type != "Node" || data.NID != 123 || data.SubnetID != 10 ||
+		data.CIDR != "10.0.0.0/8") {
+		t.Errorf("ERROR, unexpected PolicyData %v", data)
+	}
+}
+
+func TestOptionPolicyErrors(t *testing.T) {
+	badRules := [][]OptionRule{
+		{{Xname: "x3000[c0"}},
+		{{NextServer: "{{.Bogus"}},
+		{{Options: []DHCPOption{{Data: "x"}}}},
+	}
+	for _, rules := range badRules {
+		if _, err := NewOptionPolicy(rules); (err == nil) {
+			t.Errorf("ERROR, NewOptionPolicy(%v) accepted bad rules", rules)
+		}
+	}
+
+	placement := testPlacement(t, sm.CompEthInterfaceV2{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01"},
+		"10.252.1.11", "NMN")
+	badSets := [][]OptionRule{
+		{{NextServer: "fd00::2"}},
+		{{BootFileName: "ipxe\".efi"}},
+		{{Options: []DHCPOption{{Name: "host-name", Data: "{{.Bogus}}"}}}},
+	}
+	for _, rules := range badSets {
+		policy, err := NewOptionPolicy(rules)
+		if (err != nil) {
+			t.Fatalf("ERROR, NewOptionPolicy(%v) failed: %v", rules, err)
+		}
+		if _, err = policy.Evaluate(placement); (!errors.Is(err, ErrPolicyBadValue) &&
+			!errors.Is(err, ErrPolicyBadTemplate)) {
+			t.Errorf("ERROR, Evaluate() with %v returned %v", rules, err)
+		}
+	}
+	if (!base.IsHMSErrorClass(ErrPolicyBadValue, PolicyErrClassBadValue) ||
+		base.IsHMSErrorClass(ErrPolicyBadValue, PolicyErrClassBadTemplate)) {
+		t.Errorf("ERROR, policy errors share a class")
+	}
+}
@@ -0,0 +1,192 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// DhcpdGenerator renders ISC dhcpd host declarations from HSM interfaces,
+// one per interface address, placed in subnets the same way as by
+// KeaGenerator. Subnet declarations, pools and options stay in the
+// hand-written dhcpd.conf, which includes the generated file.
+type DhcpdGenerator struct {
+	Subnets *SubnetTable
+
+	// Optional. Names hosts, see KeaGenerator.Namer.
+	Namer *Namer
+
+	// Optional. Sets the options of hosts, and the next-server and filename
+	// of IPv4 hosts; DHCPv6 has neither. Option data goes in as is, so it
+	// must be in dhcpd syntax, with strings quoted, and options need a name.
+	// With IPv6, options are in the dhcp6 space, e.g. "option
+	// dhcp6.bootfile-url".
+	Policy *OptionPolicy
+
+	// Optional. Render for dhcpd -6: IPv6 subnets and fixed-address6.
+	IPv6 bool
+}
+
+// WriteHosts writes a host declaration for every address of ethInterfaces
+// of the generator's IP version to w, grouped under a comment per subnet
+// in subnet ID order, and by IP address within a subnet:
+//
+//	# subnet 10: 10.252.0.0/17 (NMN)
+//	host a4bf012e7fb1-10 {
+//	  hardware ethernet a4:bf:01:2e:7f:b1;
+//	  fixed-address 10.252.1.5;
+//	  option host-name "x3000c0s1b0n0";
+//	  next-server 10.252.0.2;
+//	  filename "ipxe.efi";
+//	  option domain-name "nmn";
+//	}
+//
+// Declarations are named after the interface ID and subnet ID, so names
+// are unique and stable. Addresses left out are returned in unplaced, see
+// SubnetTable.Place().
+func (gen *DhcpdGenerator) WriteHosts(w io.Writer, ethInterfaces []sm.CompEthInterfaceV2) (unplaced []Unplaced,
+	err error) {
+	family, fixedAddress := ipv4Family, "fixed-address"
+	if gen.IPv6 {
+		family, fixedAddress = ipv6Family, "fixed-address6"
+	}
+	subnets, hosts, unplaced, err := placeHosts(gen.Subnets, gen.Namer, ethInterfaces, family)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]OptionSet, len(hosts))
+	if gen.Policy != nil {
+		for ix, host := range hosts {
+			if sets[ix], err = gen.Policy.Evaluate(host.Placement); err != nil {
+				return unplaced, fmt.Errorf("option policy for %s (%s): %w", host.Interface.ID, host.IPAddr, err)
+			}
+			for _, option := range sets[ix].Options {
+				if _, err = dhcpdOption(option, gen.IPv6); err != nil {
+					return unplaced, fmt.Errorf("option policy for %s (%s): %w", host.Interface.ID, host.IPAddr,
+						err)
+				}
+			}
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	for ix, subnet := range subnets {
+		if ix > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "# subnet %d: %s%s\n", subnet.ID, subnet.CIDR, networkSuffix(subnet.Network))
+		for hix, host := range hosts {
+			if host.Subnet != subnet {
+				continue
+			}
+			fmt.Fprintf(out, "host %s-%d {\n", host.Interface.ID, subnet.ID)
+			fmt.Fprintf(out, "  hardware ethernet %s;\n", host.MAC)
+			fmt.Fprintf(out, "  %s %s;\n", fixedAddress, host.IPAddr)
+			// DHCPv6 has no host-name option; clients send their FQDN.
+			if host.FQDN != "" && !gen.IPv6 {
+				fmt.Fprintf(out, "  option host-name \"%s\";\n", host.shortName())
+			}
+			if sets[hix].NextServer != "" && !gen.IPv6 {
+				fmt.Fprintf(out, "  next-server %s;\n", sets[hix].NextServer)
+			}
+			if sets[hix].BootFileName != "" && !gen.IPv6 {
+				fmt.Fprintf(out, "  filename \"%s\";\n", sets[hix].BootFileName)
+			}
+			for _, option := range sets[hix].Options {
+				line, _ := dhcpdOption(option, gen.IPv6)
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+			fmt.Fprintln(out, "}")
+		}
+	}
+	return unplaced, out.Flush()
+}
+
+// dhcpdOption renders option as a dhcpd option statement. dhcpd only knows
+// an option by code once dhcpd.conf declares it, and then by the declared
+// name, so options without a name are refused; so is data that would end
+// the statement early, and an option of the other IP version's space.
+// DHCPv6 options are qualified with their space, dhcp6 by default.
+func dhcpdOption(option DHCPOption, ipv6 bool) (string, error) {
+	space, otherSpace, version := "dhcp4", "dhcp6", "DHCPv4"
+	if ipv6 {
+		space, otherSpace, version = otherSpace, space, "DHCPv6"
+	}
+	if option.Space != "" {
+		space = option.Space
+	}
+	name := option.Name
+	switch {
+	case name == "":
+		return "", fmt.Errorf("option %s: %w: dhcpd options need a name", optionKey(option), ErrPolicyBadValue)
+	case !validOptionName(name) || !validOptionName(space):
+		return "", fmt.Errorf("option %s: %w: invalid name", optionKey(option), ErrPolicyBadValue)
+	case space == otherSpace:
+		return "", fmt.Errorf("option %s: %w: not a %s option", optionKey(option), ErrPolicyBadValue, version)
+	case strings.ContainsAny(option.Data, ";{}\r\n"):
+		return "", fmt.Errorf("option %s data '%s': %w", optionKey(option), option.Data, ErrPolicyBadValue)
+	}
+	if space != "dhcp4" {
+		name = space + "." + name
+	}
+	return fmt.Sprintf("option %s %s;", name, option.Data), nil
+}
+
+// validOptionName is true for option and option space names made of
+// letters, digits, hyphens and underscores.
+func validOptionName(name string) bool {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// networkSuffix annotates subnet comments with the subnet's network.
+func networkSuffix(network string) string {
+	if network == "" {
+		return ""
+	}
+	return " (" + network + ")"
+}
+
+func (helper *DNSDHCPHelper) WriteDhcpdHosts(w io.Writer, gen *DhcpdGenerator) (unplaced []Unplaced, err error) {
+	return helper.WriteDhcpdHostsCtx(context.Background(), w, gen)
+}
+
+// WriteDhcpdHostsCtx runs gen.WriteHosts() over every interface in HSM.
+func (helper *DNSDHCPHelper) WriteDhcpdHostsCtx(ctx context.Context, w io.Writer, gen *DhcpdGenerator) (
+	unplaced []Unplaced, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	return gen.WriteHosts(w, ethInterfaces)
+}
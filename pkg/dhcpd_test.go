@@ -0,0 +1,149 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestDhcpdWriteHosts(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 20, CIDR: "10.254.0.0/17", Network: "HMN"},
+		{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"},
+		{ID: 30, CIDR: "fd00::/64"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	// The order of the input doesn't matter.
+	gen := &DhcpdGenerator{Subnets: table}
+	var out, reversed bytes.Buffer
+	unplaced, err := gen.WriteHosts(&out, keaTestInterfaces)
+	if (err != nil || len(unplaced) != 1 || unplaced[0].Reason != UnplacedNoSubnet) {
+		t.Fatalf("ERROR, WriteHosts() returned %v, error: %v", unplaced, err)
+	}
+	expected := `# subnet 10: 10.252.0.0/17 (NMN)
+host a4bf01000001-10 {
+  hardware ethernet a4:bf:01:00:00:01;
+  fixed-address 10.252.1.11;
+  option host-name "x3000c0s1b0n0";
+}
+host a4bf01000002-10 {
+  hardware ethernet a4:bf:01:00:00:02;
+  fixed-address 10.252.1.12;
+  option host-name "x3000c0s2b0n0";
+}
+
+# subnet 20: 10.254.0.0/17 (HMN)
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected output:\n%s", out.String())
+	}
+
+	ethInterfaces := []sm.CompEthInterfaceV2{keaTestInterfaces[2], keaTestInterfaces[1], keaTestInterfaces[0]}
+	if _, err = gen.WriteHosts(&reversed, ethInterfaces); (err != nil || reversed.String() != out.String()) {
+		t.Errorf("ERROR, output depends on the input order:\n%s", reversed.String())
+	}
+
+	gen6 := &DhcpdGenerator{Subnets: table, IPv6: true}
+	out.Reset()
+	unplaced, err = gen6.WriteHosts(&out, keaTestInterfaces)
+	expected = `# subnet 30: fd00::/64
+host a4bf01000001-30 {
+  hardware ethernet a4:bf:01:00:00:01;
+  fixed-address6 fd00::11;
+}
+`
+	if (err != nil || len(unplaced) != 0 || out.String() != expected) {
+		t.Errorf("ERROR, WriteHosts() for IPv6 returned %v, error: %v, output:\n%s", unplaced, err, out.String())
+	}
+
+	// The policy sets next-server, filename and options of IPv4 hosts.
+	gen.Policy, err = NewOptionPolicy([]OptionRule{{Xname: "x3000c0s1*", NextServer: "10.252.0.2",
+		BootFileName: "ipxe.efi", Options: []DHCPOption{{Name: "domain-name", Data: `"nmn"`},
+			{Name: "vendor-class", Space: "cray", Data: "01:02"}}}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewOptionPolicy() failed: %v", err)
+	}
+	out.Reset()
+	if _, err = gen.WriteHosts(&out, keaTestInterfaces[1:2]); (err != nil) {
+		t.Fatalf("ERROR, WriteHosts() failed: %v", err)
+	}
+	expected = `# subnet 10: 10.252.0.0/17 (NMN)
+host a4bf01000001-10 {
+  hardware ethernet a4:bf:01:00:00:01;
+  fixed-address 10.252.1.11;
+  option host-name "x3000c0s1b0n0";
+  next-server 10.252.0.2;
+  filename "ipxe.efi";
+  option domain-name "nmn";
+  option cray.vendor-class 01:02;
+}
+
+# subnet 20: 10.254.0.0/17 (HMN)
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected output:\n%s", out.String())
+	}
+
+	// Options dhcpd can't take are refused rather than left out.
+	for _, option := range([]DHCPOption{{Code: 43, Data: "01:02"}, {Name: "domain-name", Data: `"nmn"; deny booting`},
+		{Name: "domain name", Data: `"nmn"`}}) {
+		gen.Policy, _ = NewOptionPolicy([]OptionRule{{Options: []DHCPOption{option}}})
+		if _, err = gen.WriteHosts(&out, keaTestInterfaces[1:2]); (!errors.Is(err, ErrPolicyBadValue)) {
+			t.Errorf("ERROR, WriteHosts() accepted option %v: %v", option, err)
+		}
+	}
+
+	// With IPv6, options are DHCPv6 ones; there is no next-server or
+	// filename.
+	gen6.Policy, err = NewOptionPolicy([]OptionRule{{NextServer: "10.252.0.2", BootFileName: "ipxe.efi",
+		Options: []DHCPOption{{Name: "bootfile-url", Data: `"tftp://[fd00::2]/ipxe.efi"`}}}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewOptionPolicy() failed: %v", err)
+	}
+	out.Reset()
+	expected = `# subnet 30: fd00::/64
+host a4bf01000001-30 {
+  hardware ethernet a4:bf:01:00:00:01;
+  fixed-address6 fd00::11;
+  option dhcp6.bootfile-url "tftp://[fd00::2]/ipxe.efi";
+}
+`
+	if _, err = gen6.WriteHosts(&out, keaTestInterfaces); (err != nil || out.String() != expected) {
+		t.Errorf("ERROR, WriteHosts() for IPv6 returned error: %v, output:\n%s", err, out.String())
+	}
+	gen6.Policy, _ = NewOptionPolicy([]OptionRule{{Options: []DHCPOption{{Name: "domain-name", Space: "dhcp4",
+		Data: `"nmn"`}}}})
+	if _, err = gen6.WriteHosts(&out, keaTestInterfaces); (!errors.Is(err, ErrPolicyBadValue)) {
+		t.Errorf("ERROR, WriteHosts() for IPv6 accepted a DHCPv4 option: %v", err)
+	}
+
+	if _, err = (&DhcpdGenerator{}).WriteHosts(&out, keaTestInterfaces); (err == nil) {
+		t.Errorf("ERROR, expected an error without subnets")
+	}
+}
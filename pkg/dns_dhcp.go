@@ -1,6 +1,6 @@
 // MIT License
 //
-// (C) Copyright [2019,2022,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2019,2022,2025-2026] Hewlett Packard Enterprise Development LP
 //
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,193 +23,588 @@
 package dns_dhcp
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
 )
 
 type DNSDHCPHelper struct {
-    HSMURL     string
-    HTTPClient *retryablehttp.Client
+	HSMURL     string
+	HTTPClient *retryablehttp.Client
+
+	// Per-instance settings, see the With* options.
+	serviceName    string
+	basePath       string
+	headers        http.Header
+	requestTimeout time.Duration
+	tokenSource    TokenSource
+	versionState   *apiVersionState
+	networks       *NetworkTable
+	strictNetworks bool
+
+	ipConflictPolicy IPConflictPolicy
+	ipConflictWarn   func(conflict IPConflict)
+
+	// Set by an option given a bad value; fails every request.
+	configErr error
 }
 
-var serviceName string
+const defaultHSMBasePath = "/hsm/v2"
 
-func NewDHCPDNSHelper(HSMURL string, HTTPClient *retryablehttp.Client) (helper DNSDHCPHelper) {
-    // We're locking to a version of the HSM API (Curently V2)
-    // to ensure we can handle that version's payload. Cut off
-    // any additional URL after 'hsm' (e.g. /hsm/v2/<stuff>)
-    url := strings.Split(HSMURL, "/hsm")
-    helper.HSMURL = url[0]
+// New creates a helper for the HSM at hsmURL, configured by opts. Any path
+// after 'hsm' in hsmURL (e.g. /hsm/v2/<stuff>) is cut off; the API path is
+// set with WithBasePath() or WithAPIVersion() and defaults to /hsm/v2.
+//
+// Everything set through opts belongs to the returned helper only, so
+// several helpers with different settings can be used side by side.
+func New(hsmURL string, opts ...Option) *DNSDHCPHelper {
+	helper := &DNSDHCPHelper{
+		HSMURL:   strings.Split(hsmURL, "/hsm")[0],
+		basePath: defaultHSMBasePath,
+		headers:  http.Header{},
+	}
+	for _, opt := range opts {
+		opt(helper)
+	}
+
+	if helper.HTTPClient == nil {
+		helper.HTTPClient = retryablehttp.NewClient()
+		// Hand back the last response once retries are exhausted so a
+		// persistent 5xx is reported with HSM's status and problem details.
+		helper.HTTPClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	}
+	if helper.serviceName == "" {
+		helper.serviceName = defaultServiceName()
+	}
 
-    if HTTPClient != nil {
-        helper.HTTPClient = HTTPClient
-    } else {
-        helper.HTTPClient = retryablehttp.NewClient()
-    }
+	return helper
+}
 
-    if (serviceName == "") {
-        var err error
-        serviceName, err = os.Hostname()
-        if (err != nil) {
-            serviceName = "DNS_DHCP"
-        }
-    }
+func defaultServiceName() string {
+	name, err := base.GetServiceInstanceName()
+	if err != nil || name == "" {
+		return "DNS_DHCP"
+	}
+	return name
+}
 
-    return
+func NewDHCPDNSHelper(HSMURL string, HTTPClient *retryablehttp.Client) (helper DNSDHCPHelper) {
+	return *New(HSMURL, WithHTTPClient(HTTPClient))
 }
 
 func NewDHCPDNSHelperInstance(HSMURL string, HTTPClient *retryablehttp.Client, svcName string) (helper DNSDHCPHelper) {
-	serviceName = svcName
-	return NewDHCPDNSHelper(HSMURL, HTTPClient)
+	return *New(HSMURL, WithHTTPClient(HTTPClient), WithServiceName(svcName))
+}
+
+// hsmURL returns the full URL for an HSM API path such as
+// "/Inventory/EthernetInterfaces".
+func (helper *DNSDHCPHelper) hsmURL(path string) string {
+	return helper.HSMURL + helper.currentBasePath() + path
+}
+
+// cancelOnClose releases a request's timeout context once its response
+// body has been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
 }
 
-func rtGet(helper *DNSDHCPHelper, url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if (err != nil) {
-		return nil, err
+// transportError is what doRequest() returns when the server couldn't be
+// reached.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// rtRequest issues a request to HSM, see doRequest(). Failing to reach HSM
+// is an ErrUnavailable HMSError.
+func rtRequest(ctx context.Context, helper *DNSDHCPHelper, method string, url string,
+	payload []byte) (*http.Response, error) {
+	if helper.configErr != nil {
+		return nil, helper.configErr
+	}
+	rsp, err := doRequest(ctx, helper, "HSM", method, url, payload)
+	var tErr *transportError
+	if errors.As(err, &tErr) {
+		return nil, newHSMTransportError(method, url, tErr.err)
+	}
+	return rsp, err
+}
+
+// doRequest issues a request to server (named in errors) through the
+// helper's retryable client. The request is bound to ctx, so cancelling ctx or hitting its deadline
+// aborts both the in-flight request and any pending retries. When that
+// happens the returned error wraps ctx.Err(), so callers can tell a
+// context.DeadlineExceeded or context.Canceled apart from an HTTP failure
+// with errors.Is().
+//
+// Every request carries the helper's User-Agent and default headers, and
+// is additionally bounded by its request timeout, if one was set. If the
+// server couldn't be reached, the error is a *transportError. A token the
+// server refuses with 401 is dropped from the token source.
+func doRequest(ctx context.Context, helper *DNSDHCPHelper, server string, method string, url string,
+	payload []byte) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if helper.requestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, helper.requestTimeout)
+	}
+
+	var body interface{}
+	if payload != nil {
+		body = payload
+	}
+	rtReq, rtErr := retryablehttp.NewRequestWithContext(ctx, method, url, body)
+	if rtErr != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to construct request: %w", rtErr)
+	}
+	for key, vals := range helper.headers {
+		for _, val := range vals {
+			rtReq.Header.Add(key, val)
+		}
 	}
-	base.SetHTTPUserAgent(req, serviceName)
-	rtReq, rtErr := retryablehttp.FromRequest(req)
-	if (rtErr != nil) {
-		return nil, rtErr
+	if payload != nil {
+		rtReq.Header.Set("Content-Type", "application/json")
 	}
+	serviceName := helper.serviceName
+	if serviceName == "" {
+		serviceName = defaultServiceName()
+	}
+	base.SetHTTPUserAgent(rtReq.Request, serviceName)
+	token, authErr := setAuthHeader(ctx, helper, rtReq.Request, serviceName)
+	if authErr != nil {
+		cancel()
+		return nil, authErr
+	}
+
 	rsp, rspErr := helper.HTTPClient.Do(rtReq)
-	if (rspErr != nil) {
-		return nil, rspErr
+	if rspErr != nil {
+		if rsp != nil && rsp.Body != nil {
+			rsp.Body.Close()
+		}
+		cancel()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("%s request to %s aborted: %w", method, server, ctxErr)
+		}
+		return nil, &transportError{err: rspErr}
+	}
+	if rsp.StatusCode == http.StatusUnauthorized {
+		invalidateToken(helper, token)
+	}
+	if rsp.Body != nil {
+		rsp.Body = cancelOnClose{ReadCloser: rsp.Body, cancel: cancel}
+	} else {
+		cancel()
 	}
 	return rsp, nil
 }
 
-func (helper *DNSDHCPHelper) GetUnknownComponents() (unknownComponents []sm.CompEthInterfaceV2, err error) {
-    url := fmt.Sprintf("%s/hsm/v2/Inventory/EthernetInterfaces?ComponentID", helper.HSMURL)
+func rtGet(ctx context.Context, helper *DNSDHCPHelper, url string) (*http.Response, error) {
+	return rtRequest(ctx, helper, "GET", url, nil)
+}
 
-    response, err := rtGet(helper, url)
-    if err != nil {
-        return
-    }
+// readBody drains and closes the body of an HSM response. If ctx expired
+// while the body was being read, the context error is returned instead of
+// the (less useful) read error.
+func readBody(ctx context.Context, response *http.Response) ([]byte, error) {
+	return readServerBody(ctx, "HSM", response)
+}
 
-    jsonBytes, err := ioutil.ReadAll(response.Body)
-    defer response.Body.Close()
+// readServerBody is readBody() for a response from server.
+func readServerBody(ctx context.Context, server string, response *http.Response) ([]byte, error) {
+	if response.Body == nil {
+		return nil, nil
+	}
+	defer response.Body.Close()
+	bodyBytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("reading %s response aborted: %w", server, ctxErr)
+		}
+	}
+	return bodyBytes, err
+}
 
-    if response.StatusCode != http.StatusOK {
-        err = fmt.Errorf("unexpected status code from HSM: %d", response.StatusCode)
-        return
-    }
+// ethInterfaceID returns the HSM EthernetInterface ID for a MAC address in
+// any notation mac.Parse() understands. HSM IDs are the lower-case MAC
+// without separators, so an ID passed in comes back unchanged.
+func ethInterfaceID(macOrID string) (string, error) {
+	m, err := mac.Parse(macOrID)
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address or interface ID '%s': %w", macOrID, err)
+	}
+	return m.HSMID(), nil
+}
 
-    if err != nil {
-        return
-    }
+// ethInterfaceURL returns the URL of a single EthernetInterface.
+func (helper *DNSDHCPHelper) ethInterfaceURL(macOrID string) (string, error) {
+	id, err := ethInterfaceID(macOrID)
+	if err != nil {
+		return "", err
+	}
+	return helper.hsmURL("/Inventory/EthernetInterfaces/" + id), nil
+}
 
-    err = json.Unmarshal(jsonBytes, &unknownComponents)
+func (helper *DNSDHCPHelper) getEthernetInterfaces(ctx context.Context, version string, url string) (
+	ethInterfaces []sm.CompEthInterfaceV2, err error) {
+	response, err := rtGet(ctx, helper, url)
+	if err != nil {
+		return
+	}
 
-    return
-}
+	jsonBytes, err := readBody(ctx, response)
 
-func (helper *DNSDHCPHelper) GetAllEthernetInterfaces() (unknownComponents []sm.CompEthInterfaceV2, err error) {
-    url := fmt.Sprintf("%s/hsm/v2/Inventory/EthernetInterfaces", helper.HSMURL)
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
 
-    response, err := helper.HTTPClient.Get(url)
-    if err != nil {
-        return
-    }
+	if err != nil {
+		return
+	}
 
-    jsonBytes, err := ioutil.ReadAll(response.Body)
-    defer response.Body.Close()
+	err = unmarshalEthInterfaces(version, jsonBytes, &ethInterfaces)
 
-    if response.StatusCode != http.StatusOK {
-        err = fmt.Errorf("unexpected status code from HSM: %d", response.StatusCode)
-        return
-    }
+	return
+}
 
-    if err != nil {
-        return
-    }
+func (helper *DNSDHCPHelper) GetUnknownComponents() (unknownComponents []sm.CompEthInterfaceV2, err error) {
+	return helper.GetUnknownComponentsCtx(context.Background())
+}
 
-    err = json.Unmarshal(jsonBytes, &unknownComponents)
+// GetUnknownComponentsCtx is GetUnknownComponents bound to ctx.
+func (helper *DNSDHCPHelper) GetUnknownComponentsCtx(ctx context.Context) (
+	unknownComponents []sm.CompEthInterfaceV2, err error) {
+	return helper.QueryEthernetInterfacesCtx(ctx, EthernetInterfaceFilter{ComponentID: []string{""}})
+}
 
-    return
+func (helper *DNSDHCPHelper) GetAllEthernetInterfaces() (unknownComponents []sm.CompEthInterfaceV2, err error) {
+	return helper.GetAllEthernetInterfacesCtx(context.Background())
+}
+
+// GetAllEthernetInterfacesCtx is GetAllEthernetInterfaces bound to ctx.
+func (helper *DNSDHCPHelper) GetAllEthernetInterfacesCtx(ctx context.Context) (
+	ethInterfaces []sm.CompEthInterfaceV2, err error) {
+	return helper.QueryEthernetInterfacesCtx(ctx, EthernetInterfaceFilter{})
 }
 
 func (helper *DNSDHCPHelper) AddNewEthernetInterface(newInterface sm.CompEthInterfaceV2, patchIfConflict bool) (
-    err error) {
-    payloadBytes, marshalErr := json.Marshal(newInterface)
-    if marshalErr != nil {
-        err = fmt.Errorf("failed to marshal interface: %w", marshalErr)
-        return
-    }
-
-    url := fmt.Sprintf("%s/hsm/v2/Inventory/EthernetInterfaces", helper.HSMURL)
-
-    request, requestErr := retryablehttp.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-    if requestErr != nil {
-        err = fmt.Errorf("failed to construct request: %w", requestErr)
-        return
-    }
-    request.Header.Set("Content-Type", "application/json")
-
-    response, doErr := helper.HTTPClient.Do(request)
-    if doErr != nil {
-        err = fmt.Errorf("failed to execute POST request: %w", doErr)
-        return
-    }
-
-    if (response.Body != nil) {
-        _, _ = ioutil.ReadAll(response.Body)
-        defer response.Body.Close()
-    }
-
-    if response.StatusCode == http.StatusConflict {
-        if patchIfConflict {
-            err = helper.PatchEthernetInterface(newInterface)
-        } else {
-            err = fmt.Errorf("failed to add new interface because it already exists")
-        }
-    } else if response.StatusCode != http.StatusCreated {
-        err = fmt.Errorf("unexpected status code (%d): %s", response.StatusCode, response.Status)
-    }
-
-    return
+	err error) {
+	return helper.AddNewEthernetInterfaceCtx(context.Background(), newInterface, patchIfConflict)
+}
+
+// AddNewEthernetInterfaceCtx is AddNewEthernetInterface bound to ctx. The
+// follow-up PATCH issued on a conflict shares the same context.
+func (helper *DNSDHCPHelper) AddNewEthernetInterfaceCtx(ctx context.Context, newInterface sm.CompEthInterfaceV2,
+	patchIfConflict bool) (err error) {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+
+	// HSM keys interfaces on the MAC, so only accept one that can belong to
+	// a NIC, and send it in canonical form.
+	m, err := mac.ParseUnicast(newInterface.MACAddr)
+	if err != nil {
+		err = fmt.Errorf("invalid MAC address '%s': %w", newInterface.MACAddr, err)
+		return
+	}
+	newInterface.MACAddr = m.String()
+	newInterface.ID = m.HSMID()
+
+	if newInterface.IPAddrs, err = helper.attributeNetworks(newInterface.IPAddrs); err != nil {
+		return
+	}
+	if err = helper.checkIPConflicts(ctx, newInterface); err != nil {
+		return
+	}
+
+	payloadBytes, marshalErr := marshalEthInterface(version, newInterface)
+	if marshalErr != nil {
+		err = fmt.Errorf("failed to marshal interface: %w", marshalErr)
+		return
+	}
+
+	url := helper.hsmURL("/Inventory/EthernetInterfaces")
+
+	response, err := rtRequest(ctx, helper, "POST", url, payloadBytes)
+	if err != nil {
+		return
+	}
+	body, _ := readBody(ctx, response)
+
+	if response.StatusCode == http.StatusConflict && patchIfConflict {
+		err = helper.PatchEthernetInterfaceCtx(ctx, newInterface)
+	} else if response.StatusCode != http.StatusCreated {
+		err = newHSMError(response, body)
+	}
+
+	return
 }
 
 func (helper *DNSDHCPHelper) PatchEthernetInterface(theInterface sm.CompEthInterfaceV2) (err error) {
-    payloadBytes, marshalErr := json.Marshal(theInterface)
-    if marshalErr != nil {
-        err = fmt.Errorf("failed to marshal interface: %w", marshalErr)
-        return
-    }
-
-    macID := strings.ReplaceAll(theInterface.MACAddr, ":", "")
-    url := fmt.Sprintf("%s/hsm/v2/Inventory/EthernetInterfaces/%s", helper.HSMURL, macID)
-
-    request, requestErr := retryablehttp.NewRequest("PATCH", url, bytes.NewBuffer(payloadBytes))
-    if requestErr != nil {
-        err = fmt.Errorf("failed to construct request: %w", requestErr)
-        return
-    }
-    request.Header.Set("Content-Type", "application/json")
-
-    response, doErr := helper.HTTPClient.Do(request)
-    if doErr != nil {
-        err = fmt.Errorf("failed to execute PATCH request: %w", doErr)
-        return
-    }
-    if (response.Body != nil) {
-        _, _ = ioutil.ReadAll(response.Body)
-        defer response.Body.Close()
-    }
-
-    if response.StatusCode != http.StatusOK {
-        err = fmt.Errorf("unexpected status code (%d): %s", response.StatusCode, response.Status)
-    }
-
-    return
+	return helper.PatchEthernetInterfaceCtx(context.Background(), theInterface)
+}
+
+// PatchEthernetInterfaceCtx is PatchEthernetInterface bound to ctx.
+func (helper *DNSDHCPHelper) PatchEthernetInterfaceCtx(ctx context.Context, theInterface sm.CompEthInterfaceV2) (
+	err error) {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+
+	url, err := helper.ethInterfaceURL(theInterface.MACAddr)
+	if err != nil {
+		return
+	}
+	theInterface.MACAddr, _, _ = mac.Normalize(theInterface.MACAddr)
+
+	if theInterface.IPAddrs, err = helper.attributeNetworks(theInterface.IPAddrs); err != nil {
+		return
+	}
+
+	payloadBytes, marshalErr := marshalEthInterface(version, theInterface)
+	if marshalErr != nil {
+		err = fmt.Errorf("failed to marshal interface: %w", marshalErr)
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "PATCH", url, payloadBytes)
+	if err != nil {
+		return
+	}
+	body, _ := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, body)
+	}
+
+	return
+}
+
+func (helper *DNSDHCPHelper) GetEthernetInterface(macOrID string) (ethInterface sm.CompEthInterfaceV2, err error) {
+	return helper.GetEthernetInterfaceCtx(context.Background(), macOrID)
+}
+
+// GetEthernetInterfaceCtx returns the EthernetInterface with the given MAC
+// address or HSM ID.
+func (helper *DNSDHCPHelper) GetEthernetInterfaceCtx(ctx context.Context, macOrID string) (
+	ethInterface sm.CompEthInterfaceV2, err error) {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+
+	url, err := helper.ethInterfaceURL(macOrID)
+	if err != nil {
+		return
+	}
+
+	response, err := rtGet(ctx, helper, url)
+	if err != nil {
+		return
+	}
+
+	jsonBytes, err := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	err = unmarshalEthInterface(version, jsonBytes, &ethInterface)
+
+	return
+}
+
+// ethInterfacePatch is the wire form of sm.CompEthInterfaceV2Patch. Unset
+// fields are left out of the payload entirely instead of being sent as null.
+type ethInterfacePatch struct {
+	Desc    *string                `json:"Description,omitempty"`
+	CompID  *string                `json:"ComponentID,omitempty"`
+	IPAddrs *[]sm.IPAddressMapping `json:"IPAddresses,omitempty"`
+}
+
+func (helper *DNSDHCPHelper) PatchEthernetInterfaceFields(macOrID string, patch sm.CompEthInterfaceV2Patch) (
+	ethInterface sm.CompEthInterfaceV2, err error) {
+	return helper.PatchEthernetInterfaceFieldsCtx(context.Background(), macOrID, patch)
+}
+
+// PatchEthernetInterfaceFieldsCtx updates only the fields set (non-nil) in
+// patch and returns the interface as HSM has it afterwards. Unlike
+// PatchEthernetInterface, empty or read-only fields are never sent, so e.g.
+// changing ComponentID leaves the IPAddresses list alone. A patch with no
+// fields set just returns the current interface.
+func (helper *DNSDHCPHelper) PatchEthernetInterfaceFieldsCtx(ctx context.Context, macOrID string,
+	patch sm.CompEthInterfaceV2Patch) (ethInterface sm.CompEthInterfaceV2, err error) {
+	if patch.Desc == nil && patch.CompID == nil && patch.IPAddrs == nil {
+		return helper.GetEthernetInterfaceCtx(ctx, macOrID)
+	}
+
+	if patch.IPAddrs != nil {
+		ipAddrs, attrErr := helper.attributeNetworks(*patch.IPAddrs)
+		if attrErr != nil {
+			err = attrErr
+			return
+		}
+		patch.IPAddrs = &ipAddrs
+	}
+
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+
+	payloadBytes, marshalErr := marshalEthInterfacePatch(version, patch)
+	if marshalErr != nil {
+		err = fmt.Errorf("failed to marshal interface patch: %w", marshalErr)
+		return
+	}
+
+	url, err := helper.ethInterfaceURL(macOrID)
+	if err != nil {
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "PATCH", url, payloadBytes)
+	if err != nil {
+		return
+	}
+
+	jsonBytes, err := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(jsonBytes) == 0 {
+		// Nothing was echoed back, fetch the result.
+		return helper.GetEthernetInterfaceCtx(ctx, macOrID)
+	}
+	err = unmarshalEthInterface(version, jsonBytes, &ethInterface)
+
+	return
+}
+
+func (helper *DNSDHCPHelper) DeleteEthernetInterface(macOrID string) (err error) {
+	return helper.DeleteEthernetInterfaceCtx(context.Background(), macOrID)
+}
+
+// DeleteEthernetInterfaceCtx removes the EthernetInterface with the given MAC
+// address or HSM ID from HSM.
+func (helper *DNSDHCPHelper) DeleteEthernetInterfaceCtx(ctx context.Context, macOrID string) (err error) {
+	if _, err = helper.negotiateAPIVersion(ctx); err != nil {
+		return
+	}
+
+	url, err := helper.ethInterfaceURL(macOrID)
+	if err != nil {
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "DELETE", url, nil)
+	if err != nil {
+		return
+	}
+	body, _ := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, body)
+	}
+
+	return
+}
+
+func (helper *DNSDHCPHelper) DeleteEthernetInterfaces(filter EthernetInterfaceFilter, dryRun bool) (
+	removed []sm.CompEthInterfaceV2, err error) {
+	return helper.DeleteEthernetInterfacesCtx(context.Background(), filter, dryRun)
+}
+
+// DeleteEthernetInterfacesCtx removes every EthernetInterface matching
+// filter and returns the interfaces that were removed. With dryRun set
+// nothing is deleted and the interfaces that would have been removed are
+// returned instead.
+//
+// An empty filter is refused rather than wiping out every interface in HSM.
+// Interfaces that disappear between the query and the delete are skipped.
+// If a delete fails, the interfaces removed so far are returned along with
+// the error.
+func (helper *DNSDHCPHelper) DeleteEthernetInterfacesCtx(ctx context.Context, filter EthernetInterfaceFilter,
+	dryRun bool) (removed []sm.CompEthInterfaceV2, err error) {
+	if len(filter.Values()) == 0 {
+		err = fmt.Errorf("refusing to delete EthernetInterfaces with an empty filter")
+		return
+	}
+
+	matches, err := helper.QueryEthernetInterfacesCtx(ctx, filter)
+	if err != nil {
+		return
+	}
+	if dryRun {
+		removed = matches
+		return
+	}
+
+	for _, ethInterface := range matches {
+		id := ethInterface.ID
+		if id == "" {
+			id = ethInterface.MACAddr
+		}
+		url, urlErr := helper.ethInterfaceURL(id)
+		if urlErr != nil {
+			err = urlErr
+			return
+		}
+
+		response, doErr := rtRequest(ctx, helper, "DELETE", url, nil)
+		if doErr != nil {
+			err = doErr
+			return
+		}
+		body, _ := readBody(ctx, response)
+
+		if response.StatusCode == http.StatusNotFound {
+			continue
+		} else if response.StatusCode != http.StatusOK {
+			err = newHSMError(response, body)
+			return
+		}
+		removed = append(removed, ethInterface)
+	}
+
+	return
 }
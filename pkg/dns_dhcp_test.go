@@ -1,6 +1,6 @@
 // MIT License
 // 
-// (C) Copyright [2021-2022,2025] Hewlett Packard Enterprise Development LP
+// (C) Copyright [2021-2022,2025-2026] Hewlett Packard Enterprise Development LP
 // 
 // Permission is hereby granted, free of charge, to any person obtaining a
 // copy of this software and associated documentation files (the "Software"),
@@ -23,13 +23,18 @@
 package dns_dhcp
 
 import (
+	"context"
+	"errors"
 	"log"
 	"testing"
+	"time"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"encoding/json"
 	"io/ioutil"
+	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 )
 
@@ -46,7 +51,7 @@ func TestNewDHCPDNSHelper(t *testing.T) {
 		t.Errorf("ERROR, New func didn't create HSM url.")
 	}
 	hname, _ := os.Hostname()
-	if (serviceName != hname) {
+	if (hlp.serviceName != hname) {
 		t.Errorf("ERROR, New func didn't set service/host name.")
 	}
 
@@ -56,11 +61,13 @@ func TestNewDHCPDNSHelper(t *testing.T) {
 		t.Errorf("ERROR, New func didn't create the correct HSM url.")
 	}
 
-	serviceName = ""
-	hlp = NewDHCPDNSHelperInstance(url, nil, "XYZZY")
-	if (serviceName != "XYZZY") {
+	hlp2 := NewDHCPDNSHelperInstance(url, nil, "XYZZY")
+	if (hlp2.serviceName != "XYZZY") {
 		t.Errorf("ERROR, NewInstance func didn't set service/host name.")
 	}
+	if (hlp.serviceName != hname) {
+		t.Errorf("ERROR, NewInstance func changed another helper's service name.")
+	}
 }
 
 func hasUserAgentHeader(r *http.Request) bool {
@@ -187,6 +194,7 @@ func TestAddNewEthernetInterface(t *testing.T) {
 	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
 	gotUA = false
 	ethi.ID = "CCC"
+	ethi.MACAddr = "A4-BF-01-2E-7F-CC"
 	err := hlp.AddNewEthernetInterface(ethi, false)
 	if (err != nil) {
 		t.Errorf("ERROR, AddNewEthernetInterface() error: %v", err)
@@ -196,6 +204,21 @@ func TestAddNewEthernetInterface(t *testing.T) {
 	}
 	if (len(ethComps) != 3) {
 		t.Errorf("ERROR, AddNewEthernetInterface() didn't add anything.")
+	} else if (ethComps[2].ID != "a4bf012e7fcc" || ethComps[2].MACAddr != "a4:bf:01:2e:7f:cc") {
+		t.Errorf("ERROR, AddNewEthernetInterface() didn't normalize the MAC, sent ID '%s' MAC '%s'",
+			ethComps[2].ID, ethComps[2].MACAddr)
+	}
+
+	for _, bad := range []string{"", "a4:bf:01:2e:7f", "ff:ff:ff:ff:ff:ff", "01:00:5e:00:00:01",
+		"00:00:00:00:00:00"} {
+		ethi.MACAddr = bad
+		err = hlp.AddNewEthernetInterface(ethi, false)
+		if (err == nil) {
+			t.Errorf("ERROR, AddNewEthernetInterface() accepted MAC '%s'", bad)
+		}
+	}
+	if (len(ethComps) != 3) {
+		t.Errorf("ERROR, AddNewEthernetInterface() sent an invalid MAC to HSM.")
 	}
 }
 
@@ -207,6 +230,7 @@ func TestPatchEthernetInterface(t *testing.T) {
 	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
 	gotUA = false
 	ethi.ID = "BBB"
+	ethi.MACAddr = "a4:bf:01:2e:7f:bb"
 	ethi.Type = "None"
 	err := hlp.PatchEthernetInterface(ethi)
 	if (err != nil) {
@@ -225,4 +249,227 @@ func TestPatchEthernetInterface(t *testing.T) {
 	}
 }
 
+func handleUnavailable(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+func TestCtxDeadlineExceeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleUnavailable))
+	defer srv.Close()
+
+	// HSM keeps answering 503, so without a deadline the retryable client
+	// would back off and retry for a long time.
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	hlp.HTTPClient.RetryMax = 100
+	hlp.HTTPClient.RetryWaitMin = 50 * time.Millisecond
+	hlp.HTTPClient.RetryWaitMax = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := hlp.GetAllEthernetInterfacesCtx(ctx)
+	if (err == nil) {
+		t.Fatalf("ERROR, GetAllEthernetInterfacesCtx() didn't fail.")
+	}
+	if (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected context.DeadlineExceeded, got: %v", err)
+	}
+	if (time.Since(start) > 2*time.Second) {
+		t.Errorf("ERROR, request wasn't aborted at the deadline.")
+	}
+
+	var ethi sm.CompEthInterfaceV2
+	ethi.MACAddr = "a4:bf:01:2e:7f:b1"
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel2()
+	err = hlp.AddNewEthernetInterfaceCtx(ctx2, ethi, true)
+	if (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected context.DeadlineExceeded from POST, got: %v", err)
+	}
+}
+
+func TestCtxCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleUnk))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var ethi sm.CompEthInterfaceV2
+	ethi.MACAddr = "a4:bf:01:2e:7f:b1"
+	err := hlp.PatchEthernetInterfaceCtx(ctx, ethi)
+	if (!errors.Is(err, context.Canceled)) {
+		t.Errorf("ERROR, expected context.Canceled, got: %v", err)
+	}
+	if (errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, cancellation reported as a deadline: %v", err)
+	}
+
+	_, err = hlp.GetUnknownComponentsCtx(context.Background())
+	if (err != nil) {
+		t.Errorf("ERROR, GetUnknownComponentsCtx() error: %v", err)
+	}
+}
+
+var delComps []sm.CompEthInterfaceV2
+
+func handleDelete(w http.ResponseWriter, req *http.Request) {
+	const ethPath = "/hsm/v2/Inventory/EthernetInterfaces"
+	if (req.Method == "GET" && req.URL.Path != ethPath) {
+		id := strings.TrimPrefix(req.URL.Path, ethPath+"/")
+		for _, eee := range(delComps) {
+			if (eee.ID == id) {
+				ba, _ := json.Marshal(eee)
+				w.WriteHeader(http.StatusOK)
+				w.Write(ba)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	} else if (req.Method == "GET") {
+		var list []sm.CompEthInterfaceV2
+		cids, filtered := req.URL.Query()["ComponentID"]
+		for _, eee := range(delComps) {
+			if (!filtered || eee.CompID == cids[0]) {
+				list = append(list, eee)
+			}
+		}
+		ba, _ := json.Marshal(list)
+		w.WriteHeader(http.StatusOK)
+		w.Write(ba)
+	} else if (req.Method == "DELETE") {
+		id := strings.TrimPrefix(req.URL.Path, ethPath+"/")
+		for ix, eee := range(delComps) {
+			if (eee.ID == id) {
+				delComps = append(delComps[:ix], delComps[ix+1:]...)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDeleteEthernetInterface(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleDelete))
+	defer srv.Close()
+
+	delComps = []sm.CompEthInterfaceV2{
+		{ID: "a4bf012e7fb1", MACAddr: "a4:bf:01:2e:7f:b1", CompID: "x3000c0s1b0n0"},
+		{ID: "a4bf012e7fb2", MACAddr: "a4:bf:01:2e:7f:b2"},
+	}
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+
+	err := hlp.DeleteEthernetInterface("a4:bf:01:2e:7f:b1")
+	if (err != nil) {
+		t.Errorf("ERROR, DeleteEthernetInterface() error: %v", err)
+	}
+	if (len(delComps) != 1 || delComps[0].ID != "a4bf012e7fb2") {
+		t.Errorf("ERROR, DeleteEthernetInterface() removed the wrong entry: %v", delComps)
+	}
+	err = hlp.DeleteEthernetInterface("a4bf012e7fb1")
+	if (err == nil) {
+		t.Errorf("ERROR, DeleteEthernetInterface() didn't fail on missing interface.")
+	}
+}
+
+func TestDeleteEthernetInterfaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleDelete))
+	defer srv.Close()
+
+	delComps = []sm.CompEthInterfaceV2{
+		{ID: "a4bf012e7fb1", MACAddr: "a4:bf:01:2e:7f:b1", CompID: "x3000c0s1b0n0"},
+		{ID: "a4bf012e7fb2", MACAddr: "a4:bf:01:2e:7f:b2"},
+		{ID: "a4bf012e7fb3", MACAddr: "a4:bf:01:2e:7f:b3"},
+	}
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	unknown := EthernetInterfaceFilter{ComponentID: []string{""}}
+
+	_, err := hlp.DeleteEthernetInterfaces(EthernetInterfaceFilter{}, false)
+	if (err == nil) {
+		t.Errorf("ERROR, DeleteEthernetInterfaces() accepted an empty filter.")
+	}
+
+	removed, err := hlp.DeleteEthernetInterfaces(unknown, true)
+	if (err != nil) {
+		t.Errorf("ERROR, DeleteEthernetInterfaces() dry run error: %v", err)
+	}
+	if (len(removed) != 2 || len(delComps) != 3) {
+		t.Errorf("ERROR, dry run reported %d, left %d interfaces.", len(removed), len(delComps))
+	}
+
+	removed, err = hlp.DeleteEthernetInterfaces(unknown, false)
+	if (err != nil) {
+		t.Errorf("ERROR, DeleteEthernetInterfaces() error: %v", err)
+	}
+	if (len(removed) != 2 || len(delComps) != 1 || delComps[0].ID != "a4bf012e7fb1") {
+		t.Errorf("ERROR, removed %d, left %v", len(removed), delComps)
+	}
+}
+
+var patchBody map[string]interface{}
+
+func handlePatchFields(w http.ResponseWriter, req *http.Request) {
+	eee := sm.CompEthInterfaceV2{
+		ID:      "a4bf012e7fb1",
+		MACAddr: "a4:bf:01:2e:7f:b1",
+		CompID:  "x3000c0s1b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}},
+	}
+	if (req.URL.Path != "/hsm/v2/Inventory/EthernetInterfaces/a4bf012e7fb1") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if (req.Method == "PATCH") {
+		patchBody = nil
+		body, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(body, &patchBody)
+		if cid, ok := patchBody["ComponentID"]; ok {
+			eee.CompID = cid.(string)
+		}
+	}
+	ba, _ := json.Marshal(eee)
+	w.WriteHeader(http.StatusOK)
+	w.Write(ba)
+}
+
+func TestPatchEthernetInterfaceFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handlePatchFields))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	cid := "x3000c0s2b0n0"
+	eee, err := hlp.PatchEthernetInterfaceFields("a4:bf:01:2e:7f:b1",
+		sm.CompEthInterfaceV2Patch{CompID: &cid})
+	if (err != nil) {
+		t.Errorf("ERROR, PatchEthernetInterfaceFields() error: %v", err)
+	}
+	if (len(patchBody) != 1 || patchBody["ComponentID"] != cid) {
+		t.Errorf("ERROR, PatchEthernetInterfaceFields() sent %v", patchBody)
+	}
+	if (eee.CompID != cid || len(eee.IPAddrs) != 1) {
+		t.Errorf("ERROR, PatchEthernetInterfaceFields() returned %v", eee)
+	}
+
+	empty := ""
+	_, err = hlp.PatchEthernetInterfaceFields("a4bf012e7fb1", sm.CompEthInterfaceV2Patch{Desc: &empty})
+	if (err != nil) {
+		t.Errorf("ERROR, PatchEthernetInterfaceFields() error: %v", err)
+	}
+	if desc, ok := patchBody["Description"]; (len(patchBody) != 1 || !ok || desc != "") {
+		t.Errorf("ERROR, explicitly empty Description not sent: %v", patchBody)
+	}
+
+	patchBody = nil
+	eee, err = hlp.PatchEthernetInterfaceFields("a4bf012e7fb1", sm.CompEthInterfaceV2Patch{})
+	if (err != nil || patchBody != nil || eee.ID != "a4bf012e7fb1") {
+		t.Errorf("ERROR, empty patch wasn't a plain GET: %v %v %v", err, patchBody, eee)
+	}
 
+	_, err = hlp.PatchEthernetInterfaceFields("a4bf012e7fb2", sm.CompEthInterfaceV2Patch{CompID: &cid})
+	if (!base.IsHMSErrorClass(err, HSMErrClassNotFound)) {
+		t.Errorf("ERROR, expected a not-found HMSError, got: %v", err)
+	}
+}
@@ -0,0 +1,617 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package dns_dhcptest provides an in-memory stand-in for HSM's
+// EthernetInterfaces API, plus the read-only component data the helper
+// uses, for use in consumers' unit tests.
+//
+// The fake is served over a loopback httptest server, so code under test
+// talks to it through a real dns_dhcp.DNSDHCPHelper (see Fake.Client())
+// and sees the same requests, status codes and errors it would get from
+// HSM:
+//
+//	fake := dns_dhcptest.NewFake(seedInterfaces...)
+//	defer fake.Close()
+//	client := fake.Client()
+//	...
+//	fake.AddFault(dns_dhcptest.Fault{Method: "PATCH", StatusCode: http.StatusServiceUnavailable, Times: 1})
+//
+// FakeKea does the same for a Kea Control Agent and dns_dhcp.KeaClient.
+package dns_dhcptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/hashicorp/go-retryablehttp"
+
+	dns_dhcp "github.com/Cray-HPE/hms-dns-dhcp/pkg"
+)
+
+const ethInterfacesPath = "/hsm/v2/Inventory/EthernetInterfaces"
+const readyPath = "/hsm/v2/service/ready"
+const componentsPath = "/hsm/v2/State/Components"
+const nodeMapsPath = "/hsm/v2/Defaults/NodeMaps"
+const redfishEndpointsPath = "/hsm/v2/Inventory/RedfishEndpoints"
+const componentEndpointsPath = "/hsm/v2/Inventory/ComponentEndpoints"
+
+// Fault makes matching requests fail or slow down. A fault with a zero
+// StatusCode only adds Latency; otherwise the request is answered with
+// StatusCode and an RFC 7807 body carrying Detail, without touching the
+// fake's state.
+type Fault struct {
+	// Method to match, "" matches any.
+	Method string
+	// Path prefix to match, e.g. "/hsm/v2/Inventory/EthernetInterfaces/a4bf012e7fb1".
+	// "" matches any.
+	PathPrefix string
+
+	StatusCode int
+	Detail     string
+	Latency    time.Duration
+
+	// Number of requests the fault applies to. 0 means until ClearFaults().
+	Times int
+}
+
+// Request is a record of a request served by the fake.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// Fake is an in-memory HSM EthernetInterfaces service. It enforces HSM's
+// rules: IDs are derived from the MAC address, ComponentIDs are normalized
+// and Type is derived from them, a duplicate POST is a 409, a PATCH or
+// DELETE of a missing interface is a 404, and LastUpdate is stamped on
+// every write.
+type Fake struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	ifaces        map[string]sm.CompEthInterfaceV2
+	components    []base.Component
+	nodeMaps      []sm.NodeMap
+	rfEndpoints   []sm.RedfishEndpoint
+	compEndpoints []sm.ComponentEndpoint
+	faults        []*Fault
+	latency       time.Duration
+	requests      []Request
+
+	// Clock used for LastUpdate, may be replaced before use.
+	Now func() time.Time
+}
+
+// NewFake starts a fake HSM holding the given interfaces. Seed interfaces
+// are taken as-is, except that a missing ID is derived from the MAC.
+func NewFake(seed ...sm.CompEthInterfaceV2) *Fake {
+	fake := &Fake{
+		ifaces: map[string]sm.CompEthInterfaceV2{},
+		Now:    time.Now,
+	}
+	for _, ethInterface := range seed {
+		if ethInterface.ID == "" {
+			ethInterface.ID = macToID(ethInterface.MACAddr)
+		}
+		fake.ifaces[ethInterface.ID] = ethInterface
+	}
+	fake.server = httptest.NewServer(fake)
+	return fake
+}
+
+// Close shuts the fake's server down.
+func (fake *Fake) Close() {
+	fake.server.Close()
+}
+
+// URL is the base URL of the fake HSM.
+func (fake *Fake) URL() string {
+	return fake.server.URL
+}
+
+// Client returns a helper talking to the fake. Its HTTP client doesn't
+// retry, so injected faults are seen straight away; pass WithHTTPClient()
+// to change that.
+func (fake *Fake) Client(opts ...dns_dhcp.Option) *dns_dhcp.DNSDHCPHelper {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	opts = append([]dns_dhcp.Option{dns_dhcp.WithHTTPClient(client),
+		dns_dhcp.WithServiceName("dns_dhcptest")}, opts...)
+	return dns_dhcp.New(fake.URL(), opts...)
+}
+
+// Interfaces returns a copy of every interface held, sorted by ID.
+func (fake *Fake) Interfaces() []sm.CompEthInterfaceV2 {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.sortedLocked(nil)
+}
+
+// Interface returns the interface with the given ID.
+func (fake *Fake) Interface(id string) (sm.CompEthInterfaceV2, bool) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	ethInterface, ok := fake.ifaces[id]
+	return copyInterface(ethInterface), ok
+}
+
+// SetComponents sets the State/Components collection served. Queries
+// filter on the type parameter only.
+func (fake *Fake) SetComponents(components ...base.Component) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.components = append([]base.Component(nil), components...)
+}
+
+// SetNodeMaps sets the Defaults/NodeMaps collection served.
+func (fake *Fake) SetNodeMaps(nodeMaps ...sm.NodeMap) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.nodeMaps = append([]sm.NodeMap(nil), nodeMaps...)
+}
+
+// SetRedfishEndpoints sets the Inventory/RedfishEndpoints collection served.
+func (fake *Fake) SetRedfishEndpoints(endpoints ...sm.RedfishEndpoint) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.rfEndpoints = append([]sm.RedfishEndpoint(nil), endpoints...)
+}
+
+// SetComponentEndpoints sets the Inventory/ComponentEndpoints collection
+// served.
+func (fake *Fake) SetComponentEndpoints(endpoints ...sm.ComponentEndpoint) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.compEndpoints = append([]sm.ComponentEndpoint(nil), endpoints...)
+}
+
+// SetLatency delays every request by d.
+func (fake *Fake) SetLatency(d time.Duration) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.latency = d
+}
+
+// AddFault injects a fault, see Fault.
+func (fake *Fake) AddFault(fault Fault) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.faults = append(fake.faults, &fault)
+}
+
+// ClearFaults removes every injected fault and the latency set with
+// SetLatency().
+func (fake *Fake) ClearFaults() {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.faults = nil
+	fake.latency = 0
+}
+
+// Requests returns the requests served so far, oldest first.
+func (fake *Fake) Requests() []Request {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]Request(nil), fake.requests...)
+}
+
+// macToID returns the HSM ID of a MAC address in any notation mac.Parse()
+// understands. Anything else only loses its colons.
+func macToID(macAddr string) string {
+	if m, err := mac.Parse(macAddr); err == nil {
+		return m.HSMID()
+	}
+	return strings.ReplaceAll(strings.ToLower(macAddr), ":", "")
+}
+
+func copyInterface(ethInterface sm.CompEthInterfaceV2) sm.CompEthInterfaceV2 {
+	if ethInterface.IPAddrs != nil {
+		ethInterface.IPAddrs = append([]sm.IPAddressMapping{}, ethInterface.IPAddrs...)
+	}
+	return ethInterface
+}
+
+func (fake *Fake) stamp() string {
+	return fake.Now().UTC().Format(time.RFC3339)
+}
+
+// ServeHTTP implements http.Handler.
+func (fake *Fake) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+
+	fake.mu.Lock()
+	fake.requests = append(fake.requests, Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query(),
+		Body:   body,
+	})
+	delay := fake.latency
+	fault := fake.matchFaultLocked(req)
+	if fault != nil {
+		delay += fault.Latency
+	}
+	fake.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	if fault != nil && fault.StatusCode != 0 {
+		base.SendProblemDetailsGeneric(w, fault.StatusCode, fault.Detail)
+		return
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if req.URL.Path == readyPath && req.Method == "GET" {
+		sendJSON(w, http.StatusOK, map[string]string{"code": "0", "message": "HSM is healthy"})
+		return
+	}
+	if req.URL.Path == componentsPath && req.Method == "GET" {
+		fake.doComponents(w, req)
+		return
+	}
+	if req.URL.Path == nodeMapsPath && req.Method == "GET" {
+		nodeMaps := sm.NodeMapArray{NodeMaps: []*sm.NodeMap{}}
+		for ix := range fake.nodeMaps {
+			nodeMaps.NodeMaps = append(nodeMaps.NodeMaps, &fake.nodeMaps[ix])
+		}
+		sendJSON(w, http.StatusOK, nodeMaps)
+		return
+	}
+	if req.URL.Path == redfishEndpointsPath && req.Method == "GET" {
+		endpoints := sm.RedfishEndpointArray{RedfishEndpoints: []*sm.RedfishEndpoint{}}
+		for ix := range fake.rfEndpoints {
+			endpoints.RedfishEndpoints = append(endpoints.RedfishEndpoints, &fake.rfEndpoints[ix])
+		}
+		sendJSON(w, http.StatusOK, endpoints)
+		return
+	}
+	if req.URL.Path == componentEndpointsPath && req.Method == "GET" {
+		endpoints := sm.ComponentEndpointArray{ComponentEndpoints: []*sm.ComponentEndpoint{}}
+		for ix := range fake.compEndpoints {
+			endpoints.ComponentEndpoints = append(endpoints.ComponentEndpoints, &fake.compEndpoints[ix])
+		}
+		sendJSON(w, http.StatusOK, endpoints)
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, ethInterfacesPath) {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "no such resource")
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(req.URL.Path, ethInterfacesPath), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		fake.doQuery(w, req)
+	case len(parts) == 0 && req.Method == "POST":
+		fake.doPost(w, body)
+	case len(parts) == 1 && req.Method == "GET":
+		fake.doGet(w, parts[0])
+	case len(parts) == 1 && req.Method == "PATCH":
+		fake.doPatch(w, parts[0], body)
+	case len(parts) == 1 && req.Method == "DELETE":
+		fake.doDelete(w, parts[0])
+	case len(parts) == 2 && parts[1] == "IPAddresses" && req.Method == "GET":
+		fake.doIPList(w, parts[0])
+	case len(parts) == 2 && parts[1] == "IPAddresses" && req.Method == "POST":
+		fake.doIPAdd(w, parts[0], body)
+	case len(parts) == 3 && parts[1] == "IPAddresses" && req.Method == "PATCH":
+		fake.doIPPatch(w, parts[0], parts[2], body)
+	case len(parts) == 3 && parts[1] == "IPAddresses" && req.Method == "DELETE":
+		fake.doIPDelete(w, parts[0], parts[2])
+	default:
+		base.SendProblemDetailsGeneric(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (fake *Fake) matchFaultLocked(req *http.Request) *Fault {
+	for ix, fault := range fake.faults {
+		if fault.Method != "" && fault.Method != req.Method {
+			continue
+		}
+		if !strings.HasPrefix(req.URL.Path, fault.PathPrefix) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				fake.faults = append(fake.faults[:ix], fake.faults[ix+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func sendJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func (fake *Fake) sortedLocked(match func(sm.CompEthInterfaceV2) bool) []sm.CompEthInterfaceV2 {
+	list := []sm.CompEthInterfaceV2{}
+	for _, ethInterface := range fake.ifaces {
+		if match == nil || match(ethInterface) {
+			list = append(list, copyInterface(ethInterface))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func anyOf(vals []string, match func(string) bool) bool {
+	if len(vals) == 0 {
+		return true
+	}
+	for _, val := range vals {
+		if match(val) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fake *Fake) doQuery(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	var olderThan, newerThan time.Time
+	for key, dest := range map[string]*time.Time{"OlderThan": &olderThan, "NewerThan": &newerThan} {
+		if val := query.Get(key); val != "" {
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				base.SendProblemDetailsGeneric(w, http.StatusBadRequest,
+					fmt.Sprintf("bad query param: Invalid time format for %s", key))
+				return
+			}
+			*dest = t
+		}
+	}
+
+	list := fake.sortedLocked(func(ethInterface sm.CompEthInterfaceV2) bool {
+		lastUpdate, _ := time.Parse(time.RFC3339, ethInterface.LastUpdate)
+		return anyOf(query["MACAddress"], func(mac string) bool {
+			return strings.EqualFold(mac, ethInterface.MACAddr)
+		}) && anyOf(query["IPAddress"], func(ip string) bool {
+			for _, ipm := range ethInterface.IPAddrs {
+				if ipm.IPAddr == ip {
+					return true
+				}
+			}
+			return false
+		}) && anyOf(query["Network"], func(network string) bool {
+			for _, ipm := range ethInterface.IPAddrs {
+				if strings.EqualFold(ipm.Network, network) {
+					return true
+				}
+			}
+			return false
+		}) && anyOf(query["ComponentID"], func(compID string) bool {
+			return strings.EqualFold(compID, ethInterface.CompID)
+		}) && anyOf(query["Type"], func(hmsType string) bool {
+			return strings.EqualFold(hmsType, ethInterface.Type)
+		}) && (olderThan.IsZero() || lastUpdate.Before(olderThan)) &&
+			(newerThan.IsZero() || lastUpdate.After(newerThan))
+	})
+	sendJSON(w, http.StatusOK, list)
+}
+
+func (fake *Fake) doComponents(w http.ResponseWriter, req *http.Request) {
+	types := req.URL.Query()["type"]
+	components := base.ComponentArray{Components: []*base.Component{}}
+	for ix := range fake.components {
+		component := &fake.components[ix]
+		if anyOf(types, func(t string) bool { return strings.EqualFold(t, component.Type) }) {
+			components.Components = append(components.Components, component)
+		}
+	}
+	sendJSON(w, http.StatusOK, components)
+}
+
+func (fake *Fake) doGet(w http.ResponseWriter, id string) {
+	ethInterface, ok := fake.ifaces[strings.ToLower(id)]
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such component ethernet interface: "+id)
+		return
+	}
+	sendJSON(w, http.StatusOK, copyInterface(ethInterface))
+}
+
+func (fake *Fake) doPost(w http.ResponseWriter, body []byte) {
+	var in sm.CompEthInterfaceV2
+	if err := json.Unmarshal(body, &in); err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "error decoding JSON "+err.Error())
+		return
+	}
+	ethInterface, err := sm.NewCompEthInterfaceV2(in.Desc, in.MACAddr, in.CompID, in.IPAddrs)
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := fake.ifaces[ethInterface.ID]; ok {
+		base.SendProblemDetailsGeneric(w, http.StatusConflict,
+			"operation would conflict with an existing component ethernet interface that has the same MAC address.")
+		return
+	}
+	ethInterface.LastUpdate = fake.stamp()
+	fake.ifaces[ethInterface.ID] = *ethInterface
+	sendJSON(w, http.StatusCreated, []map[string]string{{"URI": ethInterfacesPath + "/" + ethInterface.ID}})
+}
+
+func (fake *Fake) doPatch(w http.ResponseWriter, id string, body []byte) {
+	ethInterface, ok := fake.ifaces[strings.ToLower(id)]
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such component ethernet interface: "+id)
+		return
+	}
+	var patch sm.CompEthInterfaceV2Patch
+	if err := json.Unmarshal(body, &patch); err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "error decoding JSON "+err.Error())
+		return
+	}
+	if patch.Desc == nil && patch.CompID == nil && patch.IPAddrs == nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Request must have at least one patch field.")
+		return
+	}
+
+	desc, compID, ipAddrs := ethInterface.Desc, ethInterface.CompID, ethInterface.IPAddrs
+	if patch.Desc != nil {
+		desc = *patch.Desc
+	}
+	if patch.CompID != nil {
+		compID = *patch.CompID
+	}
+	if patch.IPAddrs != nil {
+		ipAddrs = *patch.IPAddrs
+	}
+	// Re-validate (and re-derive Type) the same way a POST would.
+	updated, err := sm.NewCompEthInterfaceV2(desc, ethInterface.MACAddr, compID, ipAddrs)
+	if err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.ID = ethInterface.ID
+	updated.LastUpdate = fake.stamp()
+	fake.ifaces[updated.ID] = *updated
+	sendJSON(w, http.StatusOK, copyInterface(*updated))
+}
+
+func (fake *Fake) doDelete(w http.ResponseWriter, id string) {
+	id = strings.ToLower(id)
+	if _, ok := fake.ifaces[id]; !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "no such component ethernet interface")
+		return
+	}
+	delete(fake.ifaces, id)
+	sendJSON(w, http.StatusOK, map[string]string{"code": "0", "message": "deleted 1 entry"})
+}
+
+func (fake *Fake) doIPList(w http.ResponseWriter, id string) {
+	ethInterface, ok := fake.ifaces[strings.ToLower(id)]
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such component ethernet interface: "+id)
+		return
+	}
+	ipAddrs := append([]sm.IPAddressMapping{}, ethInterface.IPAddrs...)
+	sendJSON(w, http.StatusOK, ipAddrs)
+}
+
+func (fake *Fake) doIPAdd(w http.ResponseWriter, id string, body []byte) {
+	ethInterface, ok := fake.ifaces[strings.ToLower(id)]
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such component ethernet interface: "+id)
+		return
+	}
+	var ipm sm.IPAddressMapping
+	if err := json.Unmarshal(body, &ipm); err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "error decoding JSON "+err.Error())
+		return
+	}
+	if err := ipm.Verify(); err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, cur := range ethInterface.IPAddrs {
+		if cur.IPAddr == ipm.IPAddr {
+			base.SendProblemDetailsGeneric(w, http.StatusConflict,
+				"operation would conflict with an existing IP Address on the same ethernet interface.")
+			return
+		}
+	}
+	ethInterface.IPAddrs = append(append([]sm.IPAddressMapping{}, ethInterface.IPAddrs...), ipm)
+	ethInterface.LastUpdate = fake.stamp()
+	fake.ifaces[ethInterface.ID] = ethInterface
+	sendJSON(w, http.StatusCreated,
+		[]map[string]string{{"URI": ethInterfacesPath + "/" + ethInterface.ID + "/IPAddresses/" + ipm.IPAddr}})
+}
+
+func (fake *Fake) findIPLocked(w http.ResponseWriter, id string, ip string) (sm.CompEthInterfaceV2, int) {
+	ethInterface, ok := fake.ifaces[strings.ToLower(id)]
+	if !ok {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such component ethernet interface: "+id)
+		return ethInterface, -1
+	}
+	for ix, cur := range ethInterface.IPAddrs {
+		if cur.IPAddr == ip {
+			return ethInterface, ix
+		}
+	}
+	base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such IP address: "+ip)
+	return ethInterface, -1
+}
+
+func (fake *Fake) doIPPatch(w http.ResponseWriter, id string, ip string, body []byte) {
+	ethInterface, ix := fake.findIPLocked(w, id, ip)
+	if ix < 0 {
+		return
+	}
+	var patch sm.IPAddressMappingPatch
+	if err := json.Unmarshal(body, &patch); err != nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "error decoding JSON "+err.Error())
+		return
+	}
+	if patch.Network == nil {
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "Request must have at least one patch field.")
+		return
+	}
+	ethInterface.IPAddrs = append([]sm.IPAddressMapping{}, ethInterface.IPAddrs...)
+	ethInterface.IPAddrs[ix].Network = *patch.Network
+	ethInterface.LastUpdate = fake.stamp()
+	fake.ifaces[ethInterface.ID] = ethInterface
+	sendJSON(w, http.StatusOK, ethInterface.IPAddrs[ix])
+}
+
+func (fake *Fake) doIPDelete(w http.ResponseWriter, id string, ip string) {
+	ethInterface, ix := fake.findIPLocked(w, id, ip)
+	if ix < 0 {
+		return
+	}
+	ipAddrs := append([]sm.IPAddressMapping{}, ethInterface.IPAddrs[:ix]...)
+	ethInterface.IPAddrs = append(ipAddrs, ethInterface.IPAddrs[ix+1:]...)
+	ethInterface.LastUpdate = fake.stamp()
+	fake.ifaces[ethInterface.ID] = ethInterface
+	sendJSON(w, http.StatusOK, map[string]string{"code": "0", "message": "deleted 1 entry"})
+}
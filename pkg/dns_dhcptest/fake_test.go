@@ -0,0 +1,373 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcptest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+
+	dns_dhcp "github.com/Cray-HPE/hms-dns-dhcp/pkg"
+)
+
+var fakeSeed = []sm.CompEthInterfaceV2{
+	{MACAddr: "a4:bf:01:2e:7f:b1", CompID: "x3000c0s1b0n0", Type: "Node",
+		LastUpdate: "2026-01-01T00:00:00Z",
+		IPAddrs:    []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}}},
+	{MACAddr: "a4:bf:01:2e:7f:b2", LastUpdate: "2026-06-01T00:00:00Z"},
+}
+
+func TestFakeSemantics(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+	fake.Now = func() time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }
+
+	var client dns_dhcp.Client = fake.Client()
+
+	unk, err := client.GetUnknownComponents()
+	if (err != nil || len(unk) != 1 || unk[0].ID != "a4bf012e7fb2") {
+		t.Errorf("ERROR, GetUnknownComponents() returned %v, %v", unk, err)
+	}
+
+	// POST derives the ID from the MAC, normalizes the xname and derives Type.
+	err = client.AddNewEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "A4:BF:01:2E:7F:B3",
+		CompID: "x3000c0s02b0n0"}, false)
+	if (err != nil) {
+		t.Errorf("ERROR, AddNewEthernetInterface() error: %v", err)
+	}
+	eee, ok := fake.Interface("a4bf012e7fb3")
+	if (!ok || eee.CompID != "x3000c0s2b0n0" || eee.Type != "Node" || eee.LastUpdate != "2026-10-16T00:00:00Z") {
+		t.Errorf("ERROR, POSTed interface stored as %v", eee)
+	}
+
+	err = client.AddNewEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b3"}, false)
+	if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassConflict)) {
+		t.Errorf("ERROR, expected ErrConflict on duplicate POST, got: %v", err)
+	}
+	err = client.AddNewEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b4",
+		CompID: "not-an-xname"}, false)
+	if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassBadRequest)) {
+		t.Errorf("ERROR, expected ErrBadRequest on POST with a bad xname, got: %v", err)
+	}
+	err = client.PatchEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:ff", Desc: "x"})
+	if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassNotFound)) {
+		t.Errorf("ERROR, expected ErrNotFound on PATCH of missing interface, got: %v", err)
+	}
+
+	// Conflict patching goes through the PATCH path.
+	err = client.AddNewEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b2",
+		CompID: "x3000c0s3b0n0"}, true)
+	if (err != nil) {
+		t.Errorf("ERROR, AddNewEthernetInterface() with patch error: %v", err)
+	}
+	eee, _ = fake.Interface("a4bf012e7fb2")
+	if (eee.CompID != "x3000c0s3b0n0" || eee.Type != "Node") {
+		t.Errorf("ERROR, conflict wasn't patched: %v", eee)
+	}
+
+	list, err := client.QueryEthernetInterfaces(dns_dhcp.EthernetInterfaceFilter{Network: []string{"nmn"}})
+	if (err != nil || len(list) != 1 || list[0].ID != "a4bf012e7fb1") {
+		t.Errorf("ERROR, Network query returned %v, %v", list, err)
+	}
+	list, err = client.QueryEthernetInterfaces(dns_dhcp.EthernetInterfaceFilter{
+		OlderThan: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)})
+	if (err != nil || len(list) != 1 || list[0].ID != "a4bf012e7fb1") {
+		t.Errorf("ERROR, OlderThan query returned %v, %v", list, err)
+	}
+
+	err = client.AddIPAddress("a4:bf:01:2e:7f:b1", sm.IPAddressMapping{IPAddr: "10.252.1.5"})
+	if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassConflict)) {
+		t.Errorf("ERROR, expected ErrConflict on duplicate IP, got: %v", err)
+	}
+	err = client.DeleteIPAddress("a4:bf:01:2e:7f:b1", "10.252.1.5")
+	if (err != nil) {
+		t.Errorf("ERROR, DeleteIPAddress() error: %v", err)
+	}
+	if eee, _ = fake.Interface("a4bf012e7fb1"); (len(eee.IPAddrs) != 0) {
+		t.Errorf("ERROR, IP not removed: %v", eee)
+	}
+
+	err = client.DeleteEthernetInterface("a4:bf:01:2e:7f:b1")
+	if (err != nil || len(fake.Interfaces()) != 2) {
+		t.Errorf("ERROR, DeleteEthernetInterface() left %d, %v", len(fake.Interfaces()), err)
+	}
+}
+
+func TestFakeSeedIDs(t *testing.T) {
+	fake := NewFake(sm.CompEthInterfaceV2{MACAddr: "A4-BF-01-2E-7F-C1"},
+		sm.CompEthInterfaceV2{MACAddr: "a4bf.012e.7fc2"}, sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:c3"})
+	defer fake.Close()
+
+	for _, id := range []string{"a4bf012e7fc1", "a4bf012e7fc2", "a4bf012e7fc3"} {
+		if _, ok := fake.Interface(id); (!ok) {
+			t.Errorf("ERROR, seeded interface %s not found", id)
+		}
+	}
+}
+
+func TestFakeFaults(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+	client := fake.Client()
+
+	fake.AddFault(Fault{Method: "GET", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	_, err := client.GetAllEthernetInterfaces()
+	if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, expected ErrUnavailable, got: %v", err)
+	}
+	_, err = client.GetAllEthernetInterfaces()
+	if (err != nil) {
+		t.Errorf("ERROR, one-shot fault persisted: %v", err)
+	}
+
+	fake.AddFault(Fault{PathPrefix: "/hsm/v2/Inventory/EthernetInterfaces/a4bf012e7fb2",
+		StatusCode: http.StatusInternalServerError, Detail: "boom"})
+	_, err = client.GetEthernetInterface("a4bf012e7fb1")
+	if (err != nil) {
+		t.Errorf("ERROR, fault matched the wrong path: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = client.GetEthernetInterface("a4bf012e7fb2")
+		if (!dns_dhcp.IsHSMErrorClass(err, dns_dhcp.HSMErrClassUnexpectedStatus)) {
+			t.Errorf("ERROR, expected unexpected-status error, got: %v", err)
+		}
+	}
+	fake.ClearFaults()
+
+	fake.SetLatency(500 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetAllEthernetInterfacesCtx(ctx)
+	if (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected context.DeadlineExceeded, got: %v", err)
+	}
+	fake.ClearFaults()
+
+	nReqs := len(fake.Requests())
+	if (nReqs != 6 || fake.Requests()[0].Method != "GET") {
+		t.Errorf("ERROR, expected 6 recorded requests, got %d", nReqs)
+	}
+}
+
+func TestFakeComponents(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+	client := fake.Client()
+
+	index, err := client.NewComponentCache().Load(context.Background())
+	if (err != nil || index.Len() != 0) {
+		t.Errorf("ERROR, expected an empty index, got %d nodes, error: %v", index.Len(), err)
+	}
+
+	fake.SetComponents(
+		base.Component{ID: "x3000c0s1b0n0", Type: "Node", NID: "100001", Role: "Management"},
+		base.Component{ID: "x3000c0s1b0", Type: "NodeBMC"})
+	fake.SetNodeMaps(sm.NodeMap{ID: "x3000c0s2b0n0", NID: 2, Role: "Compute"})
+
+	components, err := client.GetNodeComponents()
+	if (err != nil || len(components) != 1 || components[0].ID != "x3000c0s1b0n0") {
+		t.Errorf("ERROR, GetNodeComponents() returned %v, error: %v", components, err)
+	}
+	nodeMaps, err := client.GetNodeMaps()
+	if (err != nil || len(nodeMaps) != 1 || nodeMaps[0].NID != 2) {
+		t.Errorf("ERROR, GetNodeMaps() returned %v, error: %v", nodeMaps, err)
+	}
+}
+
+func TestFakeResolveUnknownComponents(t *testing.T) {
+	fake := NewFake(
+		sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:00:00:01"},
+		sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:00:00:02",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.2"}}},
+		sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:00:00:03"},
+		sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:00:00:04", CompID: "x3000c0s4b0", Type: "NodeBMC"})
+	defer fake.Close()
+	client := fake.Client()
+
+	var endpoints []sm.RedfishEndpoint
+	for _, epd := range []rf.RedfishEPDescription{
+		{ID: "x3000c0s1b0", Type: "NodeBMC", MACAddr: "a4:bf:01:00:00:01"},
+		{ID: "x3000c0s2b0", Type: "NodeBMC", IPAddr: "10.254.1.2"},
+		{ID: "x3000c0s4b0", Type: "NodeBMC", MACAddr: "a4:bf:01:00:00:04"},
+	} {
+		endpoints = append(endpoints, sm.RedfishEndpoint{RedfishEPDescription: epd})
+	}
+	fake.SetRedfishEndpoints(endpoints...)
+
+	matches, err := client.ResolveUnknownComponents()
+	if (err != nil || len(matches) != 3) {
+		t.Fatalf("ERROR, ResolveUnknownComponents() returned %d matches, error: %v", len(matches), err)
+	}
+
+	applied, err := client.ApplyComponentMatches(matches, dns_dhcp.MatchHigh)
+	if (err != nil || len(applied) != 1 || applied[0].Interface.CompID != "x3000c0s1b0") {
+		t.Errorf("ERROR, ApplyComponentMatches() applied %v, error: %v", applied, err)
+	}
+	applied, err = client.ApplyComponentMatches(matches, dns_dhcp.MatchNone)
+	if (err != nil || len(applied) != 2) {
+		t.Errorf("ERROR, ApplyComponentMatches() applied %d matches, error: %v", len(applied), err)
+	}
+	eee, _ := fake.Interface("a4bf01000002")
+	if (eee.CompID != "x3000c0s2b0" || eee.Type != "NodeBMC") {
+		t.Errorf("ERROR, interface not updated: %v", eee)
+	}
+	if eee, _ = fake.Interface("a4bf01000003"); (eee.CompID != "") {
+		t.Errorf("ERROR, unmatched interface was updated: %v", eee)
+	}
+}
+
+func TestFakeStaleInterfaces(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+	client := fake.Client()
+	fake.SetComponents(base.Component{ID: "x3000c0s1b0", Type: "NodeBMC"})
+
+	report, err := client.AnalyzeStaleInterfaces(30 * 24 * time.Hour)
+	if (err != nil || report.Total != 2 || report.StaleCount != 2) {
+		t.Fatalf("ERROR, AnalyzeStaleInterfaces() returned %v, error: %v", report, err)
+	}
+	if (report.ByCategory[string(dns_dhcp.CategoryMissingComponent)] != 1) {
+		t.Errorf("ERROR, node interface not reported as missing its component: %v", report.ByCategory)
+	}
+
+	pruned, err := client.PruneStaleInterfaces(report, dns_dhcp.PruneOptions{MinAge: 30 * 24 * time.Hour,
+		Categories: []dns_dhcp.InterfaceCategory{dns_dhcp.CategoryNoComponent}, Execute: true})
+	if (err != nil || len(pruned) != 1 || len(fake.Interfaces()) != 1) {
+		t.Errorf("ERROR, PruneStaleInterfaces() pruned %v, error: %v", pruned, err)
+	}
+}
+
+func TestFakeIPConflictCheck(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+
+	newInterface := sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b9",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}}}
+
+	var warned []dns_dhcp.IPConflict
+	client := fake.Client(dns_dhcp.WithIPConflictCheck(dns_dhcp.IPConflictWarn, func(conflict dns_dhcp.IPConflict) {
+		warned = append(warned, conflict)
+	}))
+	err := client.AddNewEthernetInterface(newInterface, false)
+	if (err != nil || len(warned) != 1 || len(warned[0].Claims) != 2) {
+		t.Errorf("ERROR, warn policy returned %v, warned %v", err, warned)
+	}
+
+	conflicts, err := client.FindIPConflicts()
+	if (err != nil || len(conflicts) != 1 || conflicts[0].Claims[0].CompID != "x3000c0s1b0n0") {
+		t.Errorf("ERROR, FindIPConflicts() returned %v, error: %v", conflicts, err)
+	}
+
+	client = fake.Client(dns_dhcp.WithIPConflictCheck(dns_dhcp.IPConflictRefuse, nil))
+	newInterface.MACAddr = "a4:bf:01:2e:7f:ba"
+	err = client.AddNewEthernetInterface(newInterface, false)
+	var conflictErr *dns_dhcp.IPConflictError
+	if (!errors.Is(err, dns_dhcp.ErrIPConflict) || !errors.As(err, &conflictErr) ||
+		len(conflictErr.Conflicts[0].Claims) != 3) {
+		t.Errorf("ERROR, refuse policy returned %v", err)
+	}
+	if _, ok := fake.Interface("a4bf012e7fba"); (ok) {
+		t.Errorf("ERROR, refused interface was added.")
+	}
+
+	// Re-adding an interface with its own address isn't a conflict.
+	client.DeleteEthernetInterface("a4bf012e7fb9")
+	err = client.AddNewEthernetInterface(fakeSeed[0], true)
+	if (err != nil) {
+		t.Errorf("ERROR, interface conflicted with itself: %v", err)
+	}
+}
+
+func TestFakeCheckTopology(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+	fake.SetComponents(base.Component{ID: "x3000c0s1b0n0", Type: "Node"},
+		base.Component{ID: "x3000c0s1b0", Type: "NodeBMC"})
+
+	client := fake.Client()
+	violations, err := client.CheckTopology(nil)
+	if (err != nil || len(violations) != 1 || violations[0].CompID != "x3000c0s1b0" ||
+		violations[0].Rule != "nodebmc-hmn-ip") {
+		t.Fatalf("ERROR, CheckTopology() returned %v, error: %v", violations, err)
+	}
+
+	err = client.AddNewEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:c1", CompID: "x3000c0s1b0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "HMN"}}}, false)
+	if (err != nil) {
+		t.Fatalf("ERROR, AddNewEthernetInterface() error: %v", err)
+	}
+	violations, err = client.CheckTopology(nil)
+	if (err != nil || len(violations) != 0) {
+		t.Errorf("ERROR, CheckTopology() returned %v, error: %v", violations, err)
+	}
+}
+
+func TestFakeGenerateKeaDhcp4(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+
+	table, err := dns_dhcp.NewSubnetTable([]dns_dhcp.Subnet{{ID: 1, CIDR: "10.252.0.0/17", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	config, unplaced, err := fake.Client().GenerateKeaDhcp4(&dns_dhcp.KeaGenerator{Subnets: table})
+	if (err != nil || len(unplaced) != 0 || len(config.Subnet4) != 1) {
+		t.Fatalf("ERROR, GenerateKeaDhcp4() returned %v, %v, error: %v", config, unplaced, err)
+	}
+	reservations := config.Subnet4[0].Reservations
+	if (len(reservations) != 1 || reservations[0].HWAddress != "a4:bf:01:2e:7f:b1" ||
+		reservations[0].IPAddress != "10.252.1.5" || reservations[0].Hostname != "x3000c0s1b0n0") {
+		t.Errorf("ERROR, unexpected reservations %v", reservations)
+	}
+}
+
+func TestFakeWriteHosts(t *testing.T) {
+	fake := NewFake(fakeSeed...)
+	defer fake.Close()
+
+	table, err := dns_dhcp.NewSubnetTable([]dns_dhcp.Subnet{{ID: 1, CIDR: "10.252.0.0/17", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	unplaced, err := fake.Client().WriteDhcpdHosts(&out, &dns_dhcp.DhcpdGenerator{Subnets: table})
+	if (err != nil || len(unplaced) != 0 || !strings.Contains(out.String(), "fixed-address 10.252.1.5;")) {
+		t.Errorf("ERROR, WriteDhcpdHosts() returned %v, error: %v, output:\n%s", unplaced, err, out.String())
+	}
+
+	out.Reset()
+	unplaced, err = fake.Client().WriteDnsmasqHosts(&out, &dns_dhcp.DnsmasqGenerator{Subnets: table})
+	if (err != nil || len(unplaced) != 0 ||
+		!strings.Contains(out.String(), "dhcp-host=a4:bf:01:2e:7f:b1,10.252.1.5,x3000c0s1b0n0\n")) {
+		t.Errorf("ERROR, WriteDnsmasqHosts() returned %v, error: %v, output:\n%s", unplaced, err, out.String())
+	}
+}
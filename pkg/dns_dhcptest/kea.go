@@ -0,0 +1,432 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcptest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	dns_dhcp "github.com/Cray-HPE/hms-dns-dhcp/pkg"
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+)
+
+// KeaFault makes matching Kea commands fail. A zero StatusCode answers
+// with Result and Text in a normal 200 response; otherwise the agent
+// itself fails the request with StatusCode.
+type KeaFault struct {
+	// Command to match, "" matches any.
+	Command string
+
+	Result     int
+	Text       string
+	StatusCode int
+
+	// Number of commands the fault applies to. 0 means until ClearFaults().
+	Times int
+}
+
+// KeaCommand is a record of a command served by the fake.
+type KeaCommand struct {
+	Command   string
+	Service   []string
+	Arguments json.RawMessage
+}
+
+// FakeKea is an in-memory Kea Control Agent in front of a DHCPv4 server
+// with the lease_cmds and host_cmds hooks loaded, and a DHCPv6 server that
+// only answers lease6-get-all and lease6-del. It holds a config, leases
+// and reservations, and answers the commands dns_dhcp.KeaClient sends the
+// way Kea does, including result 3 for empty results and for deleting
+// something that isn't there.
+type FakeKea struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	config   json.RawMessage
+	written  string
+	leases4  []dns_dhcp.KeaLease4
+	leases6  []dns_dhcp.KeaLease6
+	hosts    []dns_dhcp.KeaHost
+	faults   []*KeaFault
+	commands []KeaCommand
+}
+
+// NewFakeKea starts a fake Kea Control Agent whose server runs config.
+func NewFakeKea(config json.RawMessage) *FakeKea {
+	fake := &FakeKea{config: config}
+	fake.server = httptest.NewServer(fake)
+	return fake
+}
+
+// Close shuts the fake's server down.
+func (fake *FakeKea) Close() {
+	fake.server.Close()
+}
+
+// URL is the URL of the fake Control Agent.
+func (fake *FakeKea) URL() string {
+	return fake.server.URL
+}
+
+// Client returns a Kea client talking to the fake. Like Fake.Client(), it
+// doesn't retry.
+func (fake *FakeKea) Client(opts ...dns_dhcp.Option) *dns_dhcp.KeaClient {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	opts = append([]dns_dhcp.Option{dns_dhcp.WithHTTPClient(client),
+		dns_dhcp.WithServiceName("dns_dhcptest")}, opts...)
+	return dns_dhcp.NewKeaClient(fake.URL(), opts...)
+}
+
+// Config returns the running config and the file config-write last wrote
+// it to.
+func (fake *FakeKea) Config() (json.RawMessage, string) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.config, fake.written
+}
+
+// SetLeases4 replaces the leases held.
+func (fake *FakeKea) SetLeases4(leases ...dns_dhcp.KeaLease4) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.leases4 = append([]dns_dhcp.KeaLease4(nil), leases...)
+}
+
+// Leases4 returns the leases held.
+func (fake *FakeKea) Leases4() []dns_dhcp.KeaLease4 {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]dns_dhcp.KeaLease4(nil), fake.leases4...)
+}
+
+// SetLeases6 replaces the DHCPv6 leases held.
+func (fake *FakeKea) SetLeases6(leases ...dns_dhcp.KeaLease6) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.leases6 = append([]dns_dhcp.KeaLease6(nil), leases...)
+}
+
+// Leases6 returns the DHCPv6 leases held.
+func (fake *FakeKea) Leases6() []dns_dhcp.KeaLease6 {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]dns_dhcp.KeaLease6(nil), fake.leases6...)
+}
+
+// Hosts returns the reservations held, sorted by subnet and IP address.
+func (fake *FakeKea) Hosts() []dns_dhcp.KeaHost {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]dns_dhcp.KeaHost(nil), fake.hosts...)
+}
+
+// AddFault injects a fault, see KeaFault.
+func (fake *FakeKea) AddFault(fault KeaFault) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.faults = append(fake.faults, &fault)
+}
+
+// ClearFaults removes every injected fault.
+func (fake *FakeKea) ClearFaults() {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.faults = nil
+}
+
+// Commands returns the commands served so far, oldest first.
+func (fake *FakeKea) Commands() []KeaCommand {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]KeaCommand(nil), fake.commands...)
+}
+
+func keaReply(w http.ResponseWriter, result int, text string, arguments interface{}) {
+	response := map[string]interface{}{"result": result, "text": text}
+	if arguments != nil {
+		response["arguments"] = arguments
+	}
+	sendJSON(w, http.StatusOK, []interface{}{response})
+}
+
+// ServeHTTP implements http.Handler.
+func (fake *FakeKea) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	var cmd KeaCommand
+	var raw struct {
+		Command   string          `json:"command"`
+		Service   []string        `json:"service"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if req.Method != "POST" || json.Unmarshal(body, &raw) != nil {
+		sendJSON(w, http.StatusOK, map[string]interface{}{"result": 1, "text": "invalid command"})
+		return
+	}
+	cmd = KeaCommand{Command: raw.Command, Service: raw.Service, Arguments: raw.Arguments}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.commands = append(fake.commands, cmd)
+
+	if fault := fake.matchFaultLocked(cmd.Command); fault != nil {
+		if fault.StatusCode != 0 {
+			http.Error(w, fault.Text, fault.StatusCode)
+			return
+		}
+		keaReply(w, fault.Result, fault.Text, nil)
+		return
+	}
+	var args map[string]json.RawMessage
+	json.Unmarshal(cmd.Arguments, &args)
+	if len(cmd.Service) == 1 && cmd.Service[0] == "dhcp6" {
+		fake.serveDhcp6(w, cmd.Command, args)
+		return
+	}
+	if len(cmd.Service) != 1 || cmd.Service[0] != "dhcp4" {
+		sendJSON(w, http.StatusOK, map[string]interface{}{"result": 1,
+			"text": "forwarding to services other than dhcp4 and dhcp6 is not supported"})
+		return
+	}
+
+	switch cmd.Command {
+	case "config-get":
+		keaReply(w, 0, "", fake.config)
+	case "config-test":
+		fake.doConfigTest(w, cmd.Arguments, false)
+	case "config-set":
+		fake.doConfigTest(w, cmd.Arguments, true)
+	case "config-write":
+		var filename string
+		json.Unmarshal(args["filename"], &filename)
+		if filename == "" {
+			filename = "/etc/kea/kea-dhcp4.conf"
+		}
+		fake.written = filename
+		keaReply(w, 0, "Configuration written to "+filename+" successful",
+			map[string]interface{}{"filename": filename, "size": len(fake.config)})
+	case "lease4-get-all":
+		fake.doLeaseGet(w, func(lease dns_dhcp.KeaLease4) bool {
+			var subnets []int
+			json.Unmarshal(args["subnets"], &subnets)
+			return len(subnets) == 0 || containsInt(subnets, lease.SubnetID)
+		})
+	case "lease4-get-by-hw-address":
+		var hwAddr string
+		json.Unmarshal(args["hw-address"], &hwAddr)
+		fake.doLeaseGet(w, func(lease dns_dhcp.KeaLease4) bool {
+			return sameMAC(lease.HWAddress, hwAddr)
+		})
+	case "lease4-del":
+		var ipAddr string
+		json.Unmarshal(args["ip-address"], &ipAddr)
+		for ix, lease := range fake.leases4 {
+			if lease.IPAddress == ipAddr {
+				fake.leases4 = append(fake.leases4[:ix], fake.leases4[ix+1:]...)
+				keaReply(w, 0, "IPv4 lease deleted.", nil)
+				return
+			}
+		}
+		keaReply(w, 3, "IPv4 lease not found.", nil)
+	case "reservation-add":
+		fake.doReservationAdd(w, args["reservation"])
+	case "reservation-del":
+		var subnetID int
+		var ipAddr, idType, identifier string
+		json.Unmarshal(args["subnet-id"], &subnetID)
+		json.Unmarshal(args["ip-address"], &ipAddr)
+		json.Unmarshal(args["identifier-type"], &idType)
+		json.Unmarshal(args["identifier"], &identifier)
+		if ipAddr == "" && idType != "hw-address" {
+			keaReply(w, 1, "missing parameter 'ip-address'", nil)
+			return
+		}
+		for ix, host := range fake.hosts {
+			if host.SubnetID == subnetID && ((ipAddr != "" && host.IPAddress == ipAddr) ||
+				(ipAddr == "" && sameMAC(host.HWAddress, identifier))) {
+				fake.hosts = append(fake.hosts[:ix], fake.hosts[ix+1:]...)
+				keaReply(w, 0, "Host deleted.", nil)
+				return
+			}
+		}
+		keaReply(w, 3, "Host not deleted (not found).", nil)
+	case "reservation-get-all":
+		var subnetID int
+		json.Unmarshal(args["subnet-id"], &subnetID)
+		hosts := []map[string]interface{}{}
+		for _, host := range fake.hosts {
+			if host.SubnetID == subnetID {
+				hosts = append(hosts, keaHostReported(host))
+			}
+		}
+		if len(hosts) == 0 {
+			keaReply(w, 3, "0 IPv4 host(s) found.", map[string]interface{}{"hosts": hosts})
+			return
+		}
+		keaReply(w, 0, "", map[string]interface{}{"hosts": hosts})
+	default:
+		keaReply(w, 2, "'"+cmd.Command+"' command not supported.", nil)
+	}
+}
+
+// keaHostReported returns host the way Kea reports it, with the defaults
+// of the fields it wasn't given.
+func keaHostReported(host dns_dhcp.KeaHost) map[string]interface{} {
+	reported := map[string]interface{}{
+		"boot-file-name":  "",
+		"client-classes":  []string{},
+		"hostname":        "",
+		"next-server":     "0.0.0.0",
+		"option-data":     []dns_dhcp.DHCPOption{},
+		"server-hostname": "",
+	}
+	ba, _ := json.Marshal(host)
+	json.Unmarshal(ba, &reported)
+	return reported
+}
+
+// serveDhcp6 answers the commands the fake's DHCPv6 server knows.
+func (fake *FakeKea) serveDhcp6(w http.ResponseWriter, command string, args map[string]json.RawMessage) {
+	switch command {
+	case "lease6-get-all":
+		var subnets []int
+		json.Unmarshal(args["subnets"], &subnets)
+		leases := []dns_dhcp.KeaLease6{}
+		for _, lease := range fake.leases6 {
+			if len(subnets) == 0 || containsInt(subnets, lease.SubnetID) {
+				leases = append(leases, lease)
+			}
+		}
+		if len(leases) == 0 {
+			keaReply(w, 3, "0 IPv6 lease(s) found.", map[string]interface{}{"leases": leases})
+			return
+		}
+		keaReply(w, 0, "", map[string]interface{}{"leases": leases})
+	case "lease6-del":
+		var ipAddr string
+		json.Unmarshal(args["ip-address"], &ipAddr)
+		for ix, lease := range fake.leases6 {
+			if lease.IPAddress == ipAddr {
+				fake.leases6 = append(fake.leases6[:ix], fake.leases6[ix+1:]...)
+				keaReply(w, 0, "IPv6 lease deleted.", nil)
+				return
+			}
+		}
+		keaReply(w, 3, "IPv6 lease not found.", nil)
+	default:
+		keaReply(w, 2, "'"+command+"' command not supported.", nil)
+	}
+}
+
+func (fake *FakeKea) matchFaultLocked(command string) *KeaFault {
+	for ix, fault := range fake.faults {
+		if fault.Command != "" && fault.Command != command {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				fake.faults = append(fake.faults[:ix], fake.faults[ix+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+// doConfigTest accepts any config with a Dhcp4 object.
+func (fake *FakeKea) doConfigTest(w http.ResponseWriter, config json.RawMessage, apply bool) {
+	var top map[string]json.RawMessage
+	if json.Unmarshal(config, &top) != nil || top["Dhcp4"] == nil {
+		keaReply(w, 1, "missing mandatory 'Dhcp4' parameter", nil)
+		return
+	}
+	if !apply {
+		keaReply(w, 0, "Configuration seems sane.", nil)
+		return
+	}
+	fake.config = config
+	keaReply(w, 0, "Configuration successful.", nil)
+}
+
+func (fake *FakeKea) doLeaseGet(w http.ResponseWriter, match func(dns_dhcp.KeaLease4) bool) {
+	leases := []dns_dhcp.KeaLease4{}
+	for _, lease := range fake.leases4 {
+		if match(lease) {
+			leases = append(leases, lease)
+		}
+	}
+	if len(leases) == 0 {
+		keaReply(w, 3, "0 IPv4 lease(s) found.", map[string]interface{}{"leases": leases})
+		return
+	}
+	keaReply(w, 0, "", map[string]interface{}{"leases": leases})
+}
+
+func (fake *FakeKea) doReservationAdd(w http.ResponseWriter, body json.RawMessage) {
+	var host dns_dhcp.KeaHost
+	if json.Unmarshal(body, &host) != nil || host.SubnetID == 0 || host.HWAddress == "" {
+		keaReply(w, 1, "missing parameter 'reservation'", nil)
+		return
+	}
+	for _, cur := range fake.hosts {
+		if cur.SubnetID == host.SubnetID && (cur.IPAddress == host.IPAddress || sameMAC(cur.HWAddress, host.HWAddress)) {
+			keaReply(w, 1, "Database duplicate entry error", nil)
+			return
+		}
+	}
+	fake.hosts = append(fake.hosts, host)
+	sort.SliceStable(fake.hosts, func(i, j int) bool {
+		if fake.hosts[i].SubnetID != fake.hosts[j].SubnetID {
+			return fake.hosts[i].SubnetID < fake.hosts[j].SubnetID
+		}
+		return fake.hosts[i].IPAddress < fake.hosts[j].IPAddress
+	})
+	keaReply(w, 0, "Host added.", nil)
+}
+
+func sameMAC(a string, b string) bool {
+	macA, errA := mac.Parse(a)
+	macB, errB := mac.Parse(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return macA == macB
+}
+
+func containsInt(vals []int, val int) bool {
+	for _, cur := range vals {
+		if cur == val {
+			return true
+		}
+	}
+	return false
+}
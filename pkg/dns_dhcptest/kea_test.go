@@ -0,0 +1,290 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcptest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+
+	dns_dhcp "github.com/Cray-HPE/hms-dns-dhcp/pkg"
+)
+
+func TestFakeKea(t *testing.T) {
+	fake := NewFakeKea(json.RawMessage(`{"Dhcp4":{"subnet4":[]}}`))
+	defer fake.Close()
+	fake.SetLeases4(
+		dns_dhcp.KeaLease4{IPAddress: "10.252.1.5", HWAddress: "a4:bf:01:2e:7f:b1", SubnetID: 10},
+		dns_dhcp.KeaLease4{IPAddress: "10.254.1.5", HWAddress: "a4:bf:01:2e:7f:b2", SubnetID: 20})
+
+	client := fake.Client()
+	leases, err := client.Lease4GetAll(20)
+	if (err != nil || len(leases) != 1 || leases[0].IPAddress != "10.254.1.5") {
+		t.Errorf("ERROR, Lease4GetAll() returned %v, error: %v", leases, err)
+	}
+	leases, err = client.Lease4GetByHWAddress("a4bf012e7fb1")
+	if (err != nil || len(leases) != 1 || leases[0].IPAddress != "10.252.1.5") {
+		t.Errorf("ERROR, Lease4GetByHWAddress() returned %v, error: %v", leases, err)
+	}
+	if err = client.Lease4Del("10.252.1.5"); (err != nil || len(fake.Leases4()) != 1) {
+		t.Errorf("ERROR, Lease4Del() error: %v", err)
+	}
+	if err = client.Lease4Del("10.252.1.5"); (!errors.Is(err, dns_dhcp.ErrKeaEmpty)) {
+		t.Errorf("ERROR, expected ErrKeaEmpty, got %v", err)
+	}
+
+	reservation := dns_dhcp.KeaReservation{HWAddress: "a4:bf:01:2e:7f:b1", IPAddress: "10.252.1.5",
+		Hostname: "x3000c0s1b0n0"}
+	if err = client.ReservationAdd(10, reservation); (err != nil) {
+		t.Errorf("ERROR, ReservationAdd() error: %v", err)
+	}
+	if err = client.ReservationAdd(10, reservation); (!errors.Is(err, dns_dhcp.ErrKeaError)) {
+		t.Errorf("ERROR, expected a duplicate error, got %v", err)
+	}
+	hosts, err := client.ReservationGetAll(10)
+	if (err != nil || len(hosts) != 1 || hosts[0].Hostname != "x3000c0s1b0n0") {
+		t.Errorf("ERROR, ReservationGetAll() returned %v, error: %v", hosts, err)
+	}
+	if err = client.ReservationDel(10, "10.252.1.5"); (err != nil || len(fake.Hosts()) != 0) {
+		t.Errorf("ERROR, ReservationDel() error: %v", err)
+	}
+	client.ReservationAdd(10, reservation)
+	if err = client.ReservationDelByHWAddress(10, "A4-BF-01-2E-7F-B1"); (err != nil || len(fake.Hosts()) != 0) {
+		t.Errorf("ERROR, ReservationDelByHWAddress() error: %v", err)
+	}
+	if err = client.ReservationDelByHWAddress(10, "a4:bf:01:2e:7f:b1"); (!errors.Is(err, dns_dhcp.ErrKeaEmpty)) {
+		t.Errorf("ERROR, expected ErrKeaEmpty, got %v", err)
+	}
+
+	if err = client.ConfigTest(json.RawMessage(`{"Dhcp6":{}}`)); (!errors.Is(err, dns_dhcp.ErrKeaError)) {
+		t.Errorf("ERROR, expected config-test to fail, got %v", err)
+	}
+	newConfig := json.RawMessage(`{"Dhcp4":{"subnet4":[{"id":10,"subnet":"10.252.0.0/17"}]}}`)
+	if err = client.ConfigSet(newConfig); (err != nil) {
+		t.Errorf("ERROR, ConfigSet() error: %v", err)
+	}
+	written, err := client.ConfigWrite("")
+	config, file := fake.Config()
+	if (err != nil || written != file || string(config) != string(newConfig)) {
+		t.Errorf("ERROR, ConfigWrite() returned %s, error: %v; fake has %s in %s", written, err, config, file)
+	}
+
+	fake.AddFault(KeaFault{Command: "config-get", StatusCode: http.StatusUnauthorized, Text: "Unauthorized", Times: 1})
+	if _, err = client.ConfigGet(); (err == nil) {
+		t.Errorf("ERROR, expected the injected fault")
+	}
+	if _, err = client.ConfigGet(); (err != nil) {
+		t.Errorf("ERROR, fault wasn't cleared: %v", err)
+	}
+	if commands := fake.Commands(); (len(commands) != 16 || commands[0].Command != "lease4-get-all") {
+		t.Errorf("ERROR, unexpected commands %v", commands)
+	}
+}
+
+func TestFakeIngestKeaLeases4(t *testing.T) {
+	hsm := NewFake(fakeSeed...)
+	defer hsm.Close()
+	kea := NewFakeKea(nil)
+	defer kea.Close()
+	kea.SetLeases4(
+		// Moves the seeded interface to a new NMN address.
+		dns_dhcp.KeaLease4{IPAddress: "10.252.1.50", HWAddress: "a4:bf:01:2e:7f:b1", SubnetID: 10},
+		dns_dhcp.KeaLease4{IPAddress: "10.252.1.51", HWAddress: "a4:bf:01:2e:7f:c1", SubnetID: 10,
+			Hostname: "x3000c0s5b0n0"},
+		// A second lease in the same subnet doesn't replace the first.
+		dns_dhcp.KeaLease4{IPAddress: "10.252.1.60", HWAddress: "a4:bf:01:2e:7f:c1", SubnetID: 10},
+		dns_dhcp.KeaLease4{IPAddress: "10.252.1.52", HWAddress: "a4:bf:01:2e:7f:c2", SubnetID: 10,
+			State: dns_dhcp.KeaLeaseExpiredReclaimed})
+
+	table, err := dns_dhcp.NewSubnetTable([]dns_dhcp.Subnet{{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	ingester := &dns_dhcp.LeaseIngester{Subnets: table}
+	client := hsm.Client()
+
+	results, skipped, err := client.IngestKeaLeases4(kea.Client(), ingester)
+	if (err != nil || len(results) != 2 || len(skipped) != 1) {
+		t.Fatalf("ERROR, IngestKeaLeases4() returned %v, %v, error: %v", results, skipped, err)
+	}
+	if (results[0].Action != dns_dhcp.IngestUpdated || results[1].Action != dns_dhcp.IngestCreated) {
+		t.Errorf("ERROR, unexpected results %v", results)
+	}
+	moved, _ := hsm.Interface("a4bf012e7fb1")
+	if (moved.CompID != "x3000c0s1b0n0" || len(moved.IPAddrs) != 1 || moved.IPAddrs[0].IPAddr != "10.252.1.50") {
+		t.Errorf("ERROR, unexpected interface %v", moved)
+	}
+	created, _ := hsm.Interface("a4bf012e7fc1")
+	if (created.CompID != "x3000c0s5b0n0" || len(created.IPAddrs) != 2 || created.IPAddrs[0].Network != "NMN" ||
+		created.IPAddrs[1].IPAddr != "10.252.1.60") {
+		t.Errorf("ERROR, unexpected interface %v", created)
+	}
+
+	// Nothing changed, so nothing is written.
+	writes := func() int {
+		count := 0
+		for _, req := range hsm.Requests() {
+			if (req.Method != "GET") {
+				count++
+			}
+		}
+		return count
+	}
+	before := writes()
+	results, _, err = client.IngestKeaLeases4(kea.Client(), ingester)
+	if (err != nil || len(results) != 2 || results[0].Action != dns_dhcp.IngestUnchanged ||
+		results[1].Action != dns_dhcp.IngestUnchanged || writes() != before) {
+		t.Errorf("ERROR, second ingest returned %v, error: %v; %d writes", results, err, writes()-before)
+	}
+
+	hsm.AddFault(Fault{Method: "POST", StatusCode: http.StatusServiceUnavailable})
+	results, _, err = client.IngestLeases(ingester, []dns_dhcp.KeaLease4{
+		{IPAddress: "10.252.1.53", HWAddress: "a4:bf:01:2e:7f:c3", SubnetID: 10}})
+	if (err != nil || len(results) != 1 || results[0].Action != dns_dhcp.IngestFailed ||
+		!dns_dhcp.IsHSMErrorClass(results[0].Err, dns_dhcp.HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, expected a failed result, got %v, error: %v", results, err)
+	}
+}
+
+func TestFakeIngestKeaLeases6(t *testing.T) {
+	hsm := NewFake(fakeSeed...)
+	defer hsm.Close()
+	kea := NewFakeKea(nil)
+	defer kea.Close()
+	kea.SetLeases6(
+		dns_dhcp.KeaLease6{IPAddress: "fd00::5", DUID: "00:01:00:01:2b:3c:4d:5e:a4:bf:01:2e:7f:b1",
+			Type: dns_dhcp.KeaLeaseIANA, SubnetID: 60},
+		dns_dhcp.KeaLease6{IPAddress: "fd00::6", DUID: "00:03:00:01:a4:bf:01:2e:7f:c1",
+			Type: dns_dhcp.KeaLeaseIANA, SubnetID: 60})
+
+	table, err := dns_dhcp.NewSubnetTable([]dns_dhcp.Subnet{
+		{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"},
+		{ID: 60, CIDR: "fd00::/64", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	ingester := &dns_dhcp.LeaseIngester{Subnets: table}
+	client := hsm.Client()
+
+	results, _, err := client.IngestKeaLeases6(kea.Client(), ingester)
+	if (err != nil || len(results) != 2 || results[0].Action != dns_dhcp.IngestUpdated ||
+		results[1].Action != dns_dhcp.IngestCreated) {
+		t.Fatalf("ERROR, IngestKeaLeases6() returned %v, error: %v", results, err)
+	}
+	// The IPv6 address joins the IPv4 one on the same network.
+	dual, _ := hsm.Interface("a4bf012e7fb1")
+	if (len(dual.IPAddrs) != 2 || dual.IPAddrs[0].IPAddr != "10.252.1.5" || dual.IPAddrs[1].IPAddr != "fd00::5") {
+		t.Errorf("ERROR, unexpected interface %v", dual)
+	}
+
+	results, _, err = client.IngestKeaLeases6(kea.Client(), ingester)
+	if (err != nil || results[0].Action != dns_dhcp.IngestUnchanged || results[1].Action != dns_dhcp.IngestUnchanged) {
+		t.Errorf("ERROR, second ingest returned %v, error: %v", results, err)
+	}
+}
+
+func TestFakeReconcileKeaHosts(t *testing.T) {
+	hsm := NewFake(append([]sm.CompEthInterfaceV2{{MACAddr: "a4:bf:01:2e:7f:b4", CompID: "x3000c0s1b0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "HMN"}}}}, fakeSeed...)...)
+	defer hsm.Close()
+	kea := NewFakeKea(nil)
+	defer kea.Close()
+
+	table, err := dns_dhcp.NewSubnetTable([]dns_dhcp.Subnet{{ID: 1, CIDR: "10.252.0.0/17", Network: "NMN"},
+		{ID: 2, CIDR: "10.254.0.0/17", Network: "HMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	// A reservation HSM doesn't know about.
+	err = kea.Client().ReservationAdd(1, dns_dhcp.KeaReservation{HWAddress: "a4:bf:01:2e:7f:c1",
+		IPAddress: "10.252.1.9"})
+	if (err != nil) {
+		t.Fatalf("ERROR, ReservationAdd() failed: %v", err)
+	}
+
+	ctrl := &dns_dhcp.Controller{
+		HSM:     hsm.Client(),
+		Desired: dns_dhcp.DesiredKeaHosts(&dns_dhcp.KeaGenerator{Subnets: table}, 1),
+		Backend: &dns_dhcp.KeaHostBackend{Kea: kea.Client(), SubnetIDs: []int{1}},
+		Prune:   true,
+	}
+	stats, err := ctrl.RunOnce(context.Background())
+	hosts := kea.Hosts()
+	if (err != nil || stats.Created != 1 || stats.Deleted != 1 || len(hosts) != 1 ||
+		hosts[0].IPAddress != "10.252.1.5" || hosts[0].Hostname != "x3000c0s1b0n0") {
+		t.Fatalf("ERROR, RunOnce() returned %+v, error: %v, hosts %v", stats, err, hosts)
+	}
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || stats.Created+stats.Updated+stats.Deleted != 0) {
+		t.Errorf("ERROR, unchanged RunOnce() returned %+v, error: %v", stats, err)
+	}
+
+	// A new address in HSM replaces the reservation.
+	err = hsm.Client().PatchEthernetInterface(sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b1",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.6", Network: "NMN"}}})
+	if (err != nil) {
+		t.Fatalf("ERROR, PatchEthernetInterface() failed: %v", err)
+	}
+	stats, err = ctrl.RunOnce(context.Background())
+	hosts = kea.Hosts()
+	if (err != nil || stats.Updated != 1 || len(hosts) != 1 || hosts[0].IPAddress != "10.252.1.6") {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, hosts %v", stats, err, hosts)
+	}
+
+	// A reservation moved within its subnet is deleted at its old address,
+	// whichever host the diff says it replaces.
+	moved := dns_dhcp.KeaHost{KeaReservation: dns_dhcp.KeaReservation{HWAddress: "a4:bf:01:2e:7f:b1",
+		IPAddress: "10.252.1.7", Hostname: "x3000c0s1b0n0"}, SubnetID: 1}
+	item := dns_dhcp.StateItem{Key: "1/a4:bf:01:2e:7f:b1", Value: moved}
+	err = ctrl.Backend.Apply(context.Background(), dns_dhcp.StateDiff{Update: []dns_dhcp.StateItem{item},
+		Replaced: []dns_dhcp.StateItem{item}})
+	hosts = kea.Hosts()
+	if (err != nil || len(hosts) != 1 || hosts[0].IPAddress != "10.252.1.7") {
+		t.Errorf("ERROR, Apply() of a moved reservation returned %v, hosts %v", err, hosts)
+	}
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || stats.Updated != 1 ||
+		kea.Hosts()[0].IPAddress != "10.252.1.6") {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, hosts %v", stats, err, kea.Hosts())
+	}
+
+	kea.AddFault(KeaFault{Command: "reservation-get-all", Result: 1, Text: "boom"})
+	if stats, err = ctrl.RunOnce(context.Background()); (!errors.Is(err, dns_dhcp.ErrKeaError) || stats.Failures != 1) {
+		t.Errorf("ERROR, expected ErrKeaError, got %+v, %v", stats, err)
+	}
+	kea.ClearFaults()
+
+	// Without SubnetIDs, the backend takes every IPv4 subnet of the table.
+	ctrl.Desired = dns_dhcp.DesiredKeaHosts(&dns_dhcp.KeaGenerator{Subnets: table})
+	ctrl.Backend = &dns_dhcp.KeaHostBackend{Kea: kea.Client(), Subnets: table}
+	stats, err = ctrl.RunOnce(context.Background())
+	hosts = kea.Hosts()
+	if (err != nil || stats.Created != 1 || len(hosts) != 2 || hosts[1].SubnetID != 2) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, hosts %v", stats, err, hosts)
+	}
+	ctrl.Backend = &dns_dhcp.KeaHostBackend{Kea: kea.Client()}
+	if _, err = ctrl.RunOnce(context.Background()); (err == nil) {
+		t.Errorf("ERROR, RunOnce() ran without subnets")
+	}
+}
@@ -0,0 +1,123 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// DNS record types.
+const (
+	RecordA     = "A"
+	RecordAAAA  = "AAAA"
+	RecordPTR   = "PTR"
+	RecordCNAME = "CNAME"
+)
+
+// DNSRecord is one resource record. Names are fully qualified, without the
+// trailing dot.
+type DNSRecord struct {
+	Name  string `json:"Name"`
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// ReverseName returns the name of the PTR record of ipAddr, in in-addr.arpa
+// for IPv4 and in ip6.arpa (one label per nibble) for IPv6.
+func ReverseName(ipAddr string) (string, error) {
+	canonical, err := NormalizeIPAddr(ipAddr)
+	if err != nil {
+		return "", err
+	}
+	addr := netip.MustParseAddr(canonical)
+
+	var labels []string
+	if addr.Is4() {
+		octets := addr.As4()
+		for ix := len(octets) - 1; ix >= 0; ix-- {
+			labels = append(labels, fmt.Sprintf("%d", octets[ix]))
+		}
+		return strings.Join(labels, ".") + ".in-addr.arpa", nil
+	}
+	octets := addr.As16()
+	for ix := len(octets) - 1; ix >= 0; ix-- {
+		labels = append(labels, fmt.Sprintf("%x", octets[ix]&0x0f), fmt.Sprintf("%x", octets[ix]>>4))
+	}
+	return strings.Join(labels, ".") + ".ip6.arpa", nil
+}
+
+// Records returns the DNS records of ethInterface's names, see Names(): an
+// A or AAAA record and a PTR record for every named address, and a CNAME
+// for every qualified alias. Bare aliases, which only resolve through a
+// search domain, get no record. Records are sorted by name, type and
+// value, without duplicates.
+func (namer *Namer) Records(ethInterface sm.CompEthInterfaceV2) ([]DNSRecord, error) {
+	hostNames, err := namer.Names(ethInterface)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[DNSRecord]bool)
+	var records []DNSRecord
+	add := func(record DNSRecord) {
+		if !seen[record] {
+			seen[record] = true
+			records = append(records, record)
+		}
+	}
+	for _, hostName := range hostNames {
+		ipAddr, err := NormalizeIPAddr(hostName.IPAddr)
+		if err != nil {
+			return nil, err
+		}
+		reverse, _ := ReverseName(ipAddr)
+		recordType := RecordA
+		if netip.MustParseAddr(ipAddr).Is6() {
+			recordType = RecordAAAA
+		}
+
+		add(DNSRecord{Name: hostName.FQDN, Type: recordType, Value: ipAddr})
+		add(DNSRecord{Name: reverse, Type: RecordPTR, Value: hostName.FQDN})
+		for _, alias := range hostName.Aliases {
+			if !strings.Contains(alias, ".") {
+				continue
+			}
+			add(DNSRecord{Name: alias, Type: RecordCNAME, Value: hostName.FQDN})
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		return records[i].Value < records[j].Value
+	})
+	return records, nil
+}
@@ -0,0 +1,81 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestReverseName(t *testing.T) {
+	tests := []struct {
+		ipAddr   string
+		expected string
+	}{
+		{"10.252.1.5", "5.1.252.10.in-addr.arpa"},
+		{"::ffff:10.252.1.5", "5.1.252.10.in-addr.arpa"},
+		{"fd00:252::1:5", "5.0.0.0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.5.2.0.0.0.d.f.ip6.arpa"},
+		{"2001:DB8::567:89ab", "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+		{"bogus", ""},
+		{"fe80::1%eth0", ""},
+	}
+
+	for _, test := range tests {
+		name, err := ReverseName(test.ipAddr)
+		if ((err != nil) != (test.expected == "") || name != test.expected) {
+			t.Errorf("ERROR, ReverseName(%s) returned '%s', error: %v", test.ipAddr, name, err)
+		}
+	}
+}
+
+func TestNamerRecords(t *testing.T) {
+	namer := testNamer(t)
+
+	records, err := namer.Records(sm.CompEthInterfaceV2{
+		ID:     "a4bf012e7fb1",
+		CompID: "x3000c0s1b0n0",
+		IPAddrs: []sm.IPAddressMapping{
+			{IPAddr: "10.252.1.5", Network: "NMN"},
+			{IPAddr: "FD00:252::1:5", Network: "NMN"},
+			{IPAddr: "10.254.1.5", Network: "HMN"},
+		},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, Records() error: %v", err)
+	}
+	exp := []DNSRecord{
+		{Name: "5.0.0.0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.5.2.0.0.0.d.f.ip6.arpa", Type: RecordPTR,
+			Value: "ncn-m001.nmn"},
+		{Name: "5.1.252.10.in-addr.arpa", Type: RecordPTR, Value: "ncn-m001.nmn"},
+		{Name: "5.1.254.10.in-addr.arpa", Type: RecordPTR, Value: "ncn-m001.hmn.example.com"},
+		{Name: "ncn-m001.hmn.example.com", Type: RecordA, Value: "10.254.1.5"},
+		{Name: "ncn-m001.nmn", Type: RecordA, Value: "10.252.1.5"},
+		{Name: "ncn-m001.nmn", Type: RecordAAAA, Value: "fd00:252::1:5"},
+		{Name: "x3000c0s1b0n0.hmn.example.com", Type: RecordCNAME, Value: "ncn-m001.hmn.example.com"},
+		{Name: "x3000c0s1b0n0.nmn", Type: RecordCNAME, Value: "ncn-m001.nmn"},
+	}
+	if (!reflect.DeepEqual(records, exp)) {
+		t.Errorf("ERROR, Records() returned\n%v\nexpected\n%v", records, exp)
+	}
+}
@@ -0,0 +1,184 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// DnsmasqGenerator renders dnsmasq dhcp-host and host-record lines from HSM
+// interfaces, one of each per interface address, placed in subnets the
+// same way as by KeaGenerator. dnsmasq serves both IP versions from one
+// config, so the output covers the subnets of both.
+type DnsmasqGenerator struct {
+	Subnets *SubnetTable
+
+	// Optional. Names hosts, see KeaGenerator.Namer. The FQDN and aliases
+	// go in host-record; dhcp-host gets the FQDN's first label.
+	Namer *Namer
+
+	// Optional. Sets the options of hosts, and the boot file and
+	// next-server of IPv4 hosts. A host with any of them gets a tag named
+	// like its dhcpd declaration, see DhcpdGenerator, that its dhcp-boot
+	// and dhcp-option lines match. Named options go in as "option:<name>",
+	// or "option6:<name>" for IPv6 hosts, in dnsmasq's naming, and others
+	// by code; option data goes in as is.
+	Policy *OptionPolicy
+}
+
+// WriteHosts writes the lines of every address of ethInterfaces to w,
+// grouped under a comment per subnet in subnet ID order, and by IP address
+// within a subnet:
+//
+//	# subnet 10: 10.252.0.0/17 (NMN)
+//	dhcp-host=a4:bf:01:2e:7f:b1,10.252.1.5,x3000c0s1b0n0
+//	host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,10.252.1.5
+//
+// or, with options from the policy:
+//
+//	dhcp-host=a4:bf:01:2e:7f:b1,10.252.1.5,x3000c0s1b0n0,set:a4bf012e7fb1-10
+//	dhcp-boot=tag:a4bf012e7fb1-10,ipxe.efi,,10.252.0.2
+//	dhcp-option=tag:a4bf012e7fb1-10,option:domain-name,nmn
+//	host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,10.252.1.5
+//
+// IPv6 addresses are bracketed in dhcp-host. Hosts without a name get no
+// host-record. Addresses left out are returned in unplaced, see
+// SubnetTable.Place().
+func (gen *DnsmasqGenerator) WriteHosts(w io.Writer, ethInterfaces []sm.CompEthInterfaceV2) (unplaced []Unplaced,
+	err error) {
+	subnets, hosts, unplaced, err := placeHosts(gen.Subnets, gen.Namer, ethInterfaces, anyFamily)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := make([][]string, len(hosts))
+	if gen.Policy != nil {
+		for ix, host := range hosts {
+			set, err := gen.Policy.Evaluate(host.Placement)
+			if err == nil {
+				tagged[ix], err = dnsmasqTagged(fmt.Sprintf("%s-%d", host.Interface.ID, host.Subnet.ID), set,
+					host.IPAddr.Is6())
+			}
+			if err != nil {
+				return unplaced, fmt.Errorf("option policy for %s (%s): %w", host.Interface.ID, host.IPAddr, err)
+			}
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	for ix, subnet := range subnets {
+		if ix > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "# subnet %d: %s%s\n", subnet.ID, subnet.CIDR, networkSuffix(subnet.Network))
+		for hix, host := range hosts {
+			if host.Subnet != subnet {
+				continue
+			}
+			ipAddr := host.IPAddr.String()
+			if host.IPAddr.Is6() {
+				ipAddr = "[" + ipAddr + "]"
+			}
+			fields := []string{host.MAC.String(), ipAddr}
+			if host.FQDN != "" {
+				fields = append(fields, host.shortName())
+			}
+			if len(tagged[hix]) > 0 {
+				fields = append(fields, fmt.Sprintf("set:%s-%d", host.Interface.ID, subnet.ID))
+			}
+			fmt.Fprintf(out, "dhcp-host=%s\n", strings.Join(fields, ","))
+			for _, line := range tagged[hix] {
+				fmt.Fprintln(out, line)
+			}
+
+			if host.FQDN != "" {
+				names := append([]string{host.FQDN}, host.Aliases...)
+				fmt.Fprintf(out, "host-record=%s,%s\n", strings.Join(names, ","), host.IPAddr)
+			}
+		}
+	}
+	return unplaced, out.Flush()
+}
+
+// dnsmasqTagged renders the dhcp-boot and dhcp-option lines of set for
+// the hosts tagged tag. dnsmasq only sets the next-server along with a
+// boot file, and has no equivalent of Kea's option spaces, so those are
+// refused. For an IPv6 host, options are option6 ones and there is no
+// dhcp-boot, as DHCPv6 has no next-server or boot file.
+func dnsmasqTagged(tag string, set OptionSet, ipv6 bool) (lines []string, err error) {
+	space, prefix := "dhcp4", "option:"
+	if ipv6 {
+		space, prefix = "dhcp6", "option6:"
+		set.NextServer, set.BootFileName = "", ""
+	}
+	if set.NextServer != "" && set.BootFileName == "" {
+		return nil, fmt.Errorf("next-server '%s': %w: dnsmasq needs a boot file name with it", set.NextServer,
+			ErrPolicyBadValue)
+	}
+	if strings.ContainsAny(set.BootFileName, ",") {
+		return nil, fmt.Errorf("boot file name '%s': %w", set.BootFileName, ErrPolicyBadValue)
+	}
+	if set.BootFileName != "" {
+		lines = append(lines, fmt.Sprintf("dhcp-boot=tag:%s,%s,,%s", tag, set.BootFileName, set.NextServer))
+	}
+	for _, option := range set.Options {
+		key := strconv.Itoa(option.Code)
+		if option.Name != "" {
+			key = prefix + option.Name
+		} else if ipv6 {
+			key = prefix + key
+		}
+		switch {
+		case option.Space != "" && option.Space != space:
+			return nil, fmt.Errorf("option %s: %w: dnsmasq has no option spaces", optionKey(option),
+				ErrPolicyBadValue)
+		case option.Name != "" && !validOptionName(option.Name):
+			return nil, fmt.Errorf("option %s: %w: invalid name", optionKey(option), ErrPolicyBadValue)
+		case strings.ContainsAny(option.Data, "\r\n"):
+			return nil, fmt.Errorf("option %s data '%s': %w", optionKey(option), option.Data, ErrPolicyBadValue)
+		}
+		lines = append(lines, fmt.Sprintf("dhcp-option=tag:%s,%s,%s", tag, key, option.Data))
+	}
+	return
+}
+
+func (helper *DNSDHCPHelper) WriteDnsmasqHosts(w io.Writer, gen *DnsmasqGenerator) (unplaced []Unplaced,
+	err error) {
+	return helper.WriteDnsmasqHostsCtx(context.Background(), w, gen)
+}
+
+// WriteDnsmasqHostsCtx runs gen.WriteHosts() over every interface in HSM.
+func (helper *DNSDHCPHelper) WriteDnsmasqHostsCtx(ctx context.Context, w io.Writer, gen *DnsmasqGenerator) (
+	unplaced []Unplaced, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	return gen.WriteHosts(w, ethInterfaces)
+}
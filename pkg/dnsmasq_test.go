@@ -0,0 +1,133 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestDnsmasqWriteHosts(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 30, CIDR: "fd00::/64", Network: "NMN"},
+		{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	namer, err := NewNamer(NamingConfig{Domains: map[string]string{"NMN": "nmn"}, PrimaryNetwork: "NMN",
+		NIDFormat: "-"})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNamer() failed: %v", err)
+	}
+
+	ethInterfaces := append([]sm.CompEthInterfaceV2{
+		{ID: "a4bf01000004", MACAddr: "a4:bf:01:00:00:04", CompID: "x3000c0s4b0n0",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.12"}}},
+	}, keaTestInterfaces...)
+
+	gen := &DnsmasqGenerator{Subnets: table, Namer: namer}
+	var out bytes.Buffer
+	unplaced, err := gen.WriteHosts(&out, ethInterfaces)
+	if (err != nil || len(unplaced) != 2 || unplaced[0].Reason != UnplacedNoSubnet ||
+		unplaced[1].Reason != UnplacedDuplicateIP) {
+		t.Fatalf("ERROR, WriteHosts() returned %v, error: %v", unplaced, err)
+	}
+	expected := `# subnet 10: 10.252.0.0/17 (NMN)
+dhcp-host=a4:bf:01:00:00:01,10.252.1.11,x3000c0s1b0n0
+host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,10.252.1.11
+dhcp-host=a4:bf:01:00:00:02,10.252.1.12,x3000c0s2b0n0
+host-record=x3000c0s2b0n0.nmn,x3000c0s2b0n0,10.252.1.12
+
+# subnet 30: fd00::/64 (NMN)
+dhcp-host=a4:bf:01:00:00:01,[fd00::11],x3000c0s1b0n0
+host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,fd00::11
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected output:\n%s", out.String())
+	}
+
+	// Without a Namer, hosts are named after their component.
+	out.Reset()
+	ethInterfaces = []sm.CompEthInterfaceV2{keaTestInterfaces[1],
+		{ID: "a4bf01000005", MACAddr: "a4:bf:01:00:00:05", IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.15"}}}}
+	if _, err = (&DnsmasqGenerator{Subnets: table}).WriteHosts(&out, ethInterfaces); (err != nil) {
+		t.Fatalf("ERROR, WriteHosts() failed: %v", err)
+	}
+	expected = `# subnet 10: 10.252.0.0/17 (NMN)
+dhcp-host=a4:bf:01:00:00:01,10.252.1.11,x3000c0s1b0n0
+host-record=x3000c0s1b0n0,10.252.1.11
+dhcp-host=a4:bf:01:00:00:05,10.252.1.15
+
+# subnet 30: fd00::/64 (NMN)
+dhcp-host=a4:bf:01:00:00:01,[fd00::11],x3000c0s1b0n0
+host-record=x3000c0s1b0n0,fd00::11
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected output:\n%s", out.String())
+	}
+
+	// Options go on a tag per host; DHCPv6 has no boot file or
+	// next-server.
+	gen.Policy, err = NewOptionPolicy([]OptionRule{{Xname: "x3000c0s1*", NextServer: "10.252.0.2",
+		BootFileName: "ipxe.efi", Options: []DHCPOption{{Name: "domain-name", Data: "nmn"},
+			{Code: 43, Data: "01:02"}}}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewOptionPolicy() failed: %v", err)
+	}
+	out.Reset()
+	if _, err = gen.WriteHosts(&out, keaTestInterfaces[0:2]); (err != nil) {
+		t.Fatalf("ERROR, WriteHosts() failed: %v", err)
+	}
+	expected = `# subnet 10: 10.252.0.0/17 (NMN)
+dhcp-host=a4:bf:01:00:00:01,10.252.1.11,x3000c0s1b0n0,set:a4bf01000001-10
+dhcp-boot=tag:a4bf01000001-10,ipxe.efi,,10.252.0.2
+dhcp-option=tag:a4bf01000001-10,option:domain-name,nmn
+dhcp-option=tag:a4bf01000001-10,43,01:02
+host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,10.252.1.11
+dhcp-host=a4:bf:01:00:00:02,10.252.1.12,x3000c0s2b0n0
+host-record=x3000c0s2b0n0.nmn,x3000c0s2b0n0,10.252.1.12
+
+# subnet 30: fd00::/64 (NMN)
+dhcp-host=a4:bf:01:00:00:01,[fd00::11],x3000c0s1b0n0,set:a4bf01000001-30
+dhcp-option=tag:a4bf01000001-30,option6:domain-name,nmn
+dhcp-option=tag:a4bf01000001-30,option6:43,01:02
+host-record=x3000c0s1b0n0.nmn,x3000c0s1b0n0,fd00::11
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected output:\n%s", out.String())
+	}
+
+	// What dnsmasq can't take is refused rather than left out.
+	for _, rule := range([]OptionRule{{NextServer: "10.252.0.2"},
+		{Options: []DHCPOption{{Name: "vendor-class", Space: "cray", Data: "01:02"}}},
+		{Options: []DHCPOption{{Name: "bootfile-url", Space: "dhcp6", Data: "tftp://[fd00::2]/ipxe.efi"}}},
+		{Options: []DHCPOption{{Name: "domain-name", Data: "nmn\ndhcp-ignore=tag:!known"}}}}) {
+		gen.Policy, _ = NewOptionPolicy([]OptionRule{rule})
+		if _, err = gen.WriteHosts(&out, keaTestInterfaces[1:2]); (!errors.Is(err, ErrPolicyBadValue)) {
+			t.Errorf("ERROR, WriteHosts() accepted rule %v: %v", rule, err)
+		}
+	}
+}
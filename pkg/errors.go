@@ -0,0 +1,121 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// HMSError classes used for errors returned by HSM.
+const (
+	HSMErrClassBadRequest       = "hsm-bad-request"
+	HSMErrClassNotFound         = "hsm-not-found"
+	HSMErrClassConflict         = "hsm-conflict"
+	HSMErrClassUnavailable      = "hsm-unavailable"
+	HSMErrClassUnexpectedStatus = "hsm-unexpected-status"
+)
+
+// Sentinel errors, one per class. Errors returned for failed HSM requests
+// are children of these: bare *base.HMSErrors of the same class, so
+// base.IsHMSErrorClass() and base.GetHMSError() work on them directly, e.g.
+// base.IsHMSErrorClass(err, dns_dhcp.HSMErrClassNotFound). Use
+// IsHSMErrorClass() for errors that may have been wrapped since.
+//
+// The error's ProblemDetails are those HSM sent back, or generic ones
+// built from the status code if the body wasn't a problem document.
+// Problem.Status is the status code, 0 if HSM couldn't be reached at all,
+// and Problem.Instance the request URL unless HSM set one.
+var ErrBadRequest = base.NewHMSError(HSMErrClassBadRequest, "bad request")
+var ErrNotFound = base.NewHMSError(HSMErrClassNotFound, "not found")
+var ErrConflict = base.NewHMSError(HSMErrClassConflict, "already exists")
+var ErrUnavailable = base.NewHMSError(HSMErrClassUnavailable, "HSM unavailable")
+var ErrUnexpectedStatus = base.NewHMSError(HSMErrClassUnexpectedStatus, "unexpected status code")
+
+// IsHSMErrorClass returns true if err is, or wraps, an HMSError of the
+// given class.
+func IsHSMErrorClass(err error, class string) bool {
+	var hmsErr *base.HMSError
+	return errors.As(err, &hmsErr) && hmsErr.IsClass(class)
+}
+
+func sentinelForStatus(statusCode int) *base.HMSError {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	return ErrUnexpectedStatus
+}
+
+// newHSMError builds the error for an HSM response with an unexpected
+// status code. body is the (already read) response body, which HSM
+// normally fills with RFC 7807 problem details.
+func newHSMError(response *http.Response, body []byte) *base.HMSError {
+	var method, url string
+	if response.Request != nil {
+		method = response.Request.Method
+		url = response.Request.URL.String()
+	}
+
+	var problem base.ProblemDetails
+	jsonErr := json.Unmarshal(body, &problem)
+	if jsonErr != nil || (problem.Type == "" && problem.Title == "" && problem.Detail == "") {
+		problem = *base.NewProblemDetailsStatus(strings.TrimSpace(string(body)), response.StatusCode)
+	}
+	if problem.Status == 0 {
+		problem.Status = response.StatusCode
+	}
+	if problem.Instance == "" {
+		problem.Instance = url
+	}
+
+	sentinel := sentinelForStatus(response.StatusCode)
+	detail := problem.Detail
+	if detail == "" {
+		detail = sentinel.Message
+	}
+	hmsErr := sentinel.NewChild(fmt.Sprintf("HSM %s %s failed (%d %s): %s", method, url, response.StatusCode,
+		http.StatusText(response.StatusCode), detail))
+	hmsErr.AddProblem(&problem)
+	return hmsErr
+}
+
+// newHSMTransportError builds the error for an HSM request that never got
+// a usable response.
+func newHSMTransportError(method string, url string, err error) *base.HMSError {
+	hmsErr := ErrUnavailable.NewChild(fmt.Sprintf("HSM %s %s failed: %v", method, url, err))
+	hmsErr.AddProblem(&base.ProblemDetails{Type: base.ProblemDetailsHTTPStatusType, Detail: err.Error(),
+		Instance: url})
+	return hmsErr
+}
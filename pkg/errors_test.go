@@ -0,0 +1,116 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func handleErrors(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "GET":
+		base.SendProblemDetailsGeneric(w, http.StatusBadRequest, "bad query")
+	case "POST":
+		base.SendProblemDetailsGeneric(w, http.StatusConflict, "operation would conflict with an existing resource")
+	case "PATCH":
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "no such ethernet interface")
+	case "DELETE":
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("upstream is down"))
+	}
+}
+
+func TestHSMErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleErrors))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	hlp.HTTPClient.RetryMax = 0
+	ethi := sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b1"}
+
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (!base.IsHMSErrorClass(err, HSMErrClassBadRequest)) {
+		t.Errorf("ERROR, expected a bad request HMSError, got: %v", err)
+	}
+
+	err = hlp.AddNewEthernetInterface(ethi, false)
+	if (!base.IsHMSErrorClass(err, HSMErrClassConflict) || base.IsHMSErrorClass(err, HSMErrClassNotFound)) {
+		t.Errorf("ERROR, expected a conflict HMSError, got: %v", err)
+	}
+	hmsErr, ok := base.GetHMSError(err)
+	if (!ok) {
+		t.Fatalf("ERROR, expected an HMSError, got: %v", err)
+	}
+	if (hmsErr.GetProblem() == nil || hmsErr.GetProblem().Status != http.StatusConflict ||
+		hmsErr.GetProblem().Instance != srv.URL+"/hsm/v2/Inventory/EthernetInterfaces" ||
+		hmsErr.GetProblem().Detail != "operation would conflict with an existing resource") {
+		t.Errorf("ERROR, problem details not parsed: %v", hmsErr.GetProblem())
+	}
+	if (hmsErr.Error() != "HSM POST "+srv.URL+"/hsm/v2/Inventory/EthernetInterfaces failed (409 Conflict): "+
+		"operation would conflict with an existing resource") {
+		t.Errorf("ERROR, unexpected message: %s", hmsErr.Error())
+	}
+
+	// A conflict with patchIfConflict set surfaces the PATCH failure.
+	err = hlp.AddNewEthernetInterface(ethi, true)
+	if (!base.IsHMSErrorClass(err, HSMErrClassNotFound)) {
+		t.Errorf("ERROR, expected a not-found HMSError, got: %v", err)
+	}
+
+	err = hlp.DeleteEthernetInterface(ethi.MACAddr)
+	if (!base.IsHMSErrorClass(err, HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, expected an unavailable HMSError, got: %v", err)
+	}
+	if hmsErr, ok = base.GetHMSError(err); (!ok || hmsErr.GetProblem().Detail != "upstream is down" ||
+		hmsErr.GetProblem().Status != http.StatusServiceUnavailable) {
+		t.Errorf("ERROR, non-JSON body not carried in problem details: %v", err)
+	}
+
+	// Wrapped, the class is still found.
+	if (!IsHSMErrorClass(fmt.Errorf("deleting: %w", err), HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, IsHSMErrorClass() didn't look through wrapping")
+	}
+}
+
+func TestHSMTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleErrors))
+	url := srv.URL
+	srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(url, nil, expSvcName)
+	hlp.HTTPClient.RetryMax = 0
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (!base.IsHMSErrorClass(err, HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, expected an unavailable HMSError, got: %v", err)
+	}
+	if hmsErr, ok := base.GetHMSError(err); (!ok || hmsErr.GetProblem().Status != 0 ||
+		hmsErr.GetProblem().Detail == "") {
+		t.Errorf("ERROR, transport failure not reported: %v", err)
+	}
+}
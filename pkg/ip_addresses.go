@@ -0,0 +1,291 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// The IPAddresses sub-resource of an EthernetInterface lets single
+// IP address mappings be added, changed and removed without replacing the
+// whole IPAddresses list, so concurrent writers (DHCP, DNS, admins) don't
+// clobber each other's entries. All functions accept either the interface
+// MAC address or its HSM ID.
+//
+// The HSM v1 API has no such sub-resource. There, listing is emulated from
+// the interface's single IP address and the other operations fail with
+// ErrUnsupportedByAPIVersion.
+
+// NormalizeIPAddr checks that ipAddr is an IPv4 or IPv6 address an
+// interface can hold and returns it in canonical form: dotted quad for
+// IPv4, RFC 5952 (compressed, lower-case) for IPv6. IPv4-mapped IPv6
+// addresses become IPv4. Unspecified, multicast and zoned (fe80::1%eth0)
+// addresses are refused.
+func NormalizeIPAddr(ipAddr string) (string, error) {
+	addr, err := netip.ParseAddr(ipAddr)
+	switch {
+	case err != nil:
+		return "", fmt.Errorf("invalid IP address '%s': %w", ipAddr, sm.ErrCompEthInterfaceBadIPAddress)
+	case addr.Zone() != "":
+		return "", fmt.Errorf("IP address '%s' has a zone: %w", ipAddr, sm.ErrCompEthInterfaceBadIPAddress)
+	}
+	addr = addr.Unmap()
+	if addr.IsUnspecified() || addr.IsMulticast() {
+		return "", fmt.Errorf("IP address '%s' can't belong to an interface: %w", ipAddr,
+			sm.ErrCompEthInterfaceBadIPAddress)
+	}
+	return addr.String(), nil
+}
+
+// NormalizeIPAddressMappings returns a copy of ipAddrs with every address
+// normalized by NormalizeIPAddr().
+func NormalizeIPAddressMappings(ipAddrs []sm.IPAddressMapping) ([]sm.IPAddressMapping, error) {
+	if ipAddrs == nil {
+		return nil, nil
+	}
+	normalized := make([]sm.IPAddressMapping, len(ipAddrs))
+	for ix, ipm := range ipAddrs {
+		ipAddr, err := NormalizeIPAddr(ipm.IPAddr)
+		if err != nil {
+			return nil, err
+		}
+		normalized[ix] = sm.IPAddressMapping{IPAddr: ipAddr, Network: ipm.Network}
+	}
+	return normalized, nil
+}
+
+func ipAddressesURL(helper *DNSDHCPHelper, macOrID string) (string, error) {
+	ifaceURL, err := helper.ethInterfaceURL(macOrID)
+	if err != nil {
+		return "", err
+	}
+	return ifaceURL + "/IPAddresses", nil
+}
+
+func ipAddressURL(helper *DNSDHCPHelper, macOrID string, ipAddr string) (string, error) {
+	addrsURL, err := ipAddressesURL(helper, macOrID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", addrsURL, url.PathEscape(ipAddr)), nil
+}
+
+// requireIPAddressesResource fails if the HSM API in use has no IPAddresses
+// sub-resource.
+func (helper *DNSDHCPHelper) requireIPAddressesResource(ctx context.Context, operation string) error {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version == HSMAPIVersion1 {
+		return unsupportedByAPIVersion(operation, version)
+	}
+	return nil
+}
+
+func (helper *DNSDHCPHelper) ListIPAddresses(macOrID string) (ipAddrs []sm.IPAddressMapping, err error) {
+	return helper.ListIPAddressesCtx(context.Background(), macOrID)
+}
+
+// ListIPAddressesCtx returns the IP address mappings of an EthernetInterface.
+func (helper *DNSDHCPHelper) ListIPAddressesCtx(ctx context.Context, macOrID string) (
+	ipAddrs []sm.IPAddressMapping, err error) {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+	if version == HSMAPIVersion1 {
+		ethInterface, getErr := helper.GetEthernetInterfaceCtx(ctx, macOrID)
+		return ethInterface.IPAddrs, getErr
+	}
+
+	addrsURL, err := ipAddressesURL(helper, macOrID)
+	if err != nil {
+		return
+	}
+
+	response, err := rtGet(ctx, helper, addrsURL)
+	if err != nil {
+		return
+	}
+
+	jsonBytes, err := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(jsonBytes, &ipAddrs)
+
+	return
+}
+
+func (helper *DNSDHCPHelper) AddIPAddress(macOrID string, ipAddr sm.IPAddressMapping) (err error) {
+	return helper.AddIPAddressCtx(context.Background(), macOrID, ipAddr)
+}
+
+// AddIPAddressCtx adds a single IP address mapping to an EthernetInterface,
+// leaving any other mappings it already has in place.
+func (helper *DNSDHCPHelper) AddIPAddressCtx(ctx context.Context, macOrID string, ipAddr sm.IPAddressMapping) (
+	err error) {
+	if err = ipAddr.Verify(); err != nil {
+		return
+	}
+	attributed, err := helper.attributeNetworks([]sm.IPAddressMapping{ipAddr})
+	if err != nil {
+		return
+	}
+	ipAddr = attributed[0]
+	if err = helper.requireIPAddressesResource(ctx, "adding an IP address"); err != nil {
+		return
+	}
+
+	payloadBytes, marshalErr := json.Marshal(ipAddr)
+	if marshalErr != nil {
+		err = fmt.Errorf("failed to marshal IP address: %w", marshalErr)
+		return
+	}
+
+	addrsURL, err := ipAddressesURL(helper, macOrID)
+	if err != nil {
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "POST", addrsURL, payloadBytes)
+	if err != nil {
+		return
+	}
+	body, _ := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusCreated {
+		err = newHSMError(response, body)
+	}
+
+	return
+}
+
+func (helper *DNSDHCPHelper) PatchIPAddressNetwork(macOrID string, ipAddr string, network string) (
+	ipAddrMapping sm.IPAddressMapping, err error) {
+	return helper.PatchIPAddressNetworkCtx(context.Background(), macOrID, ipAddr, network)
+}
+
+// PatchIPAddressNetworkCtx sets the Network of one IP address mapping of an
+// EthernetInterface and returns the updated mapping. ipAddr is normalized
+// with NormalizeIPAddr() first, so any spelling of the address matches.
+// With a network table (see WithNetworkTable()), network is checked against
+// the table, or filled in from it if empty.
+func (helper *DNSDHCPHelper) PatchIPAddressNetworkCtx(ctx context.Context, macOrID string, ipAddr string,
+	network string) (ipAddrMapping sm.IPAddressMapping, err error) {
+	if ipAddr, err = NormalizeIPAddr(ipAddr); err != nil {
+		return
+	}
+	if err = helper.requireIPAddressesResource(ctx, "patching an IP address"); err != nil {
+		return
+	}
+	attributed, err := helper.attributeNetworks([]sm.IPAddressMapping{{IPAddr: ipAddr, Network: network}})
+	if err != nil {
+		return
+	}
+	network = attributed[0].Network
+
+	payloadBytes, marshalErr := json.Marshal(sm.IPAddressMappingPatch{Network: &network})
+	if marshalErr != nil {
+		err = fmt.Errorf("failed to marshal IP address patch: %w", marshalErr)
+		return
+	}
+
+	addrURL, err := ipAddressURL(helper, macOrID, ipAddr)
+	if err != nil {
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "PATCH", addrURL, payloadBytes)
+	if err != nil {
+		return
+	}
+
+	jsonBytes, err := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, jsonBytes)
+		return
+	}
+
+	if err != nil {
+		return
+	}
+
+	if len(jsonBytes) == 0 {
+		// Nothing was echoed back, report what was sent.
+		ipAddrMapping = sm.IPAddressMapping{IPAddr: ipAddr, Network: network}
+		return
+	}
+	err = json.Unmarshal(jsonBytes, &ipAddrMapping)
+
+	return
+}
+
+func (helper *DNSDHCPHelper) DeleteIPAddress(macOrID string, ipAddr string) (err error) {
+	return helper.DeleteIPAddressCtx(context.Background(), macOrID, ipAddr)
+}
+
+// DeleteIPAddressCtx removes one IP address mapping from an
+// EthernetInterface, leaving its other mappings in place. ipAddr is
+// normalized with NormalizeIPAddr() first.
+func (helper *DNSDHCPHelper) DeleteIPAddressCtx(ctx context.Context, macOrID string, ipAddr string) (err error) {
+	if ipAddr, err = NormalizeIPAddr(ipAddr); err != nil {
+		return
+	}
+	if err = helper.requireIPAddressesResource(ctx, "deleting an IP address"); err != nil {
+		return
+	}
+
+	addrURL, err := ipAddressURL(helper, macOrID, ipAddr)
+	if err != nil {
+		return
+	}
+
+	response, err := rtRequest(ctx, helper, "DELETE", addrURL, nil)
+	if err != nil {
+		return
+	}
+	body, _ := readBody(ctx, response)
+
+	if response.StatusCode != http.StatusOK {
+		err = newHSMError(response, body)
+	}
+
+	return
+}
@@ -0,0 +1,203 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+const ipAddrsPath = "/hsm/v2/Inventory/EthernetInterfaces/a4bf012e7fb1/IPAddresses"
+
+var ipAddrs []sm.IPAddressMapping
+
+func handleIPAddrs(w http.ResponseWriter, req *http.Request) {
+	if (!strings.HasPrefix(req.URL.Path, ipAddrsPath)) {
+		base.SendProblemDetailsGeneric(w, http.StatusNotFound, "No such interface")
+		return
+	}
+	ip := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, ipAddrsPath), "/")
+
+	if (req.Method == "GET" && ip == "") {
+		ba, _ := json.Marshal(ipAddrs)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(ba)
+	} else if (req.Method == "POST" && ip == "") {
+		var ipm sm.IPAddressMapping
+		body, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(body, &ipm)
+		for _, cur := range(ipAddrs) {
+			if (cur.IPAddr == ipm.IPAddr) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		ipAddrs = append(ipAddrs, ipm)
+		w.WriteHeader(http.StatusCreated)
+	} else if (req.Method == "PATCH") {
+		var patch sm.IPAddressMappingPatch
+		body, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(body, &patch)
+		for ix := range(ipAddrs) {
+			if (ipAddrs[ix].IPAddr == ip) {
+				ipAddrs[ix].Network = *patch.Network
+				ba, _ := json.Marshal(ipAddrs[ix])
+				w.WriteHeader(http.StatusOK)
+				w.Write(ba)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	} else if (req.Method == "DELETE") {
+		for ix := range(ipAddrs) {
+			if (ipAddrs[ix].IPAddr == ip) {
+				ipAddrs = append(ipAddrs[:ix], ipAddrs[ix+1:]...)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestIPAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleIPAddrs))
+	defer srv.Close()
+
+	ipAddrs = []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}}
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	mac := "a4:bf:01:2e:7f:b1"
+
+	err := hlp.AddIPAddress(mac, sm.IPAddressMapping{IPAddr: "10.254.1.5"})
+	if (err != nil) {
+		t.Errorf("ERROR, AddIPAddress() error: %v", err)
+	}
+	err = hlp.AddIPAddress(mac, sm.IPAddressMapping{IPAddr: "10.254.1.5"})
+	if (err == nil) {
+		t.Errorf("ERROR, AddIPAddress() didn't fail on duplicate.")
+	}
+	err = hlp.AddIPAddress(mac, sm.IPAddressMapping{})
+	if (err != sm.ErrCompEthInterfaceBadIPAddress) {
+		t.Errorf("ERROR, AddIPAddress() accepted an empty IP: %v", err)
+	}
+
+	ipm, err := hlp.PatchIPAddressNetwork("a4bf012e7fb1", "10.254.1.5", "HMN")
+	if (err != nil) {
+		t.Errorf("ERROR, PatchIPAddressNetwork() error: %v", err)
+	}
+	if (ipm.IPAddr != "10.254.1.5" || ipm.Network != "HMN") {
+		t.Errorf("ERROR, PatchIPAddressNetwork() returned %v", ipm)
+	}
+
+	list, err := hlp.ListIPAddresses(mac)
+	if (err != nil) {
+		t.Errorf("ERROR, ListIPAddresses() error: %v", err)
+	}
+	if (len(list) != 2 || list[0].Network != "NMN" || list[1].Network != "HMN") {
+		t.Errorf("ERROR, ListIPAddresses() returned %v", list)
+	}
+
+	err = hlp.DeleteIPAddress(mac, "10.252.1.5")
+	if (err != nil) {
+		t.Errorf("ERROR, DeleteIPAddress() error: %v", err)
+	}
+	err = hlp.DeleteIPAddress(mac, "10.252.1.5")
+	if (err == nil) {
+		t.Errorf("ERROR, DeleteIPAddress() didn't fail on missing IP.")
+	}
+	if (len(ipAddrs) != 1 || ipAddrs[0].IPAddr != "10.254.1.5") {
+		t.Errorf("ERROR, DeleteIPAddress() removed the wrong entry: %v", ipAddrs)
+	}
+
+	// HSM stores addresses in canonical form, so other spellings have to
+	// be normalized before they go in the URL.
+	err = hlp.AddIPAddress(mac, sm.IPAddressMapping{IPAddr: "fd00:252::1:5"})
+	if (err != nil) {
+		t.Errorf("ERROR, AddIPAddress() error: %v", err)
+	}
+	ipm, err = hlp.PatchIPAddressNetwork(mac, "FD00:252::0001:5", "NMN")
+	if (err != nil || ipm.IPAddr != "fd00:252::1:5" || ipm.Network != "NMN") {
+		t.Errorf("ERROR, PatchIPAddressNetwork() returned %v, error: %v", ipm, err)
+	}
+	err = hlp.DeleteIPAddress(mac, "fd00:252:0:0:0:0:1:5")
+	if (err != nil || len(ipAddrs) != 1) {
+		t.Errorf("ERROR, DeleteIPAddress() left %v, error: %v", ipAddrs, err)
+	}
+	err = hlp.DeleteIPAddress(mac, "fd00:252::1:5%eth0")
+	if (!errors.Is(err, sm.ErrCompEthInterfaceBadIPAddress)) {
+		t.Errorf("ERROR, DeleteIPAddress() accepted an address with a zone: %v", err)
+	}
+
+	_, err = hlp.ListIPAddresses("a4:bf:01:2e:7f:ff")
+	if (err == nil) {
+		t.Errorf("ERROR, ListIPAddresses() didn't fail on missing interface.")
+	}
+}
+
+func TestNormalizeIPAddr(t *testing.T) {
+	tests := []struct {
+		ipAddr   string
+		expected string
+	}{
+		{"10.252.1.5", "10.252.1.5"},
+		{"::ffff:10.252.1.5", "10.252.1.5"},
+		{"FD00:0252:0000::0001", "fd00:252::1"},
+		{"2001:db8:0:0:1:0:0:1", "2001:db8::1:0:0:1"},
+		{"fe80::a6bf:1ff:fe2e:7fb1", "fe80::a6bf:1ff:fe2e:7fb1"},
+		{"fe80::1%eth0", ""},
+		{"0.0.0.0", ""},
+		{"::", ""},
+		{"ff02::1", ""},
+		{"224.0.0.1", ""},
+		{"10.252.1", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		ipAddr, err := NormalizeIPAddr(test.ipAddr)
+		if (ipAddr != test.expected || (err != nil) != (test.expected == "")) {
+			t.Errorf("ERROR, NormalizeIPAddr(%s) returned '%s', error: %v", test.ipAddr, ipAddr, err)
+		}
+		if (err != nil && !errors.Is(err, sm.ErrCompEthInterfaceBadIPAddress)) {
+			t.Errorf("ERROR, NormalizeIPAddr(%s) error doesn't wrap ErrCompEthInterfaceBadIPAddress: %v",
+				test.ipAddr, err)
+		}
+	}
+
+	ipAddrs, err := NormalizeIPAddressMappings([]sm.IPAddressMapping{{IPAddr: "FD00::5", Network: "NMN"}})
+	if (err != nil || ipAddrs[0] != (sm.IPAddressMapping{IPAddr: "fd00::5", Network: "NMN"})) {
+		t.Errorf("ERROR, NormalizeIPAddressMappings() returned %v, error: %v", ipAddrs, err)
+	}
+	if _, err = NormalizeIPAddressMappings([]sm.IPAddressMapping{{IPAddr: "fd00::5"}, {IPAddr: "x"}}); (err == nil) {
+		t.Errorf("ERROR, expected an error for a bad address")
+	}
+}
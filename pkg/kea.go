@@ -0,0 +1,246 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// KeaGenerator turns HSM interfaces into Kea host reservations, one per
+// interface address, grouped into the subnet4 entries of a Kea Dhcp4
+// config or the subnet6 entries of a Dhcp6 one. The output only depends
+// on its input, so regenerated configs diff cleanly.
+type KeaGenerator struct {
+	Subnets *SubnetTable
+
+	// Optional. Names reservations; an interface it can't name gets no
+	// hostname. Without a Namer, the hostname is the ComponentID, if that
+	// is a valid DNS name.
+	Namer *Namer
+
+	// Optional. Sets the options, and for DHCPv4 the next-server and boot
+	// file name, of each reservation.
+	Policy *OptionPolicy
+
+	// Optional. Returns more options of one reservation; they replace
+	// those from Policy with the same name or code.
+	Options func(placement Placement) ([]DHCPOption, error)
+}
+
+// KeaReservation is a Kea host reservation.
+type KeaReservation struct {
+	HWAddress      string       `json:"hw-address"`
+	IPAddress      string       `json:"ip-address"`
+	Hostname       string       `json:"hostname,omitempty"`
+	NextServer     string       `json:"next-server,omitempty"`
+	ServerHostname string       `json:"server-hostname,omitempty"`
+	BootFileName   string       `json:"boot-file-name,omitempty"`
+	OptionData     []DHCPOption `json:"option-data,omitempty"`
+}
+
+// KeaSubnet4 is an entry of Kea's Dhcp4.subnet4.
+type KeaSubnet4 struct {
+	ID           int              `json:"id"`
+	Subnet       string           `json:"subnet"`
+	OptionData   []DHCPOption     `json:"option-data,omitempty"`
+	Reservations []KeaReservation `json:"reservations"`
+}
+
+// KeaDhcp4Config is the generated part of a Kea Dhcp4 config.
+type KeaDhcp4Config struct {
+	Subnet4 []KeaSubnet4 `json:"subnet4"`
+}
+
+// KeaReservation6 is a Kea DHCPv6 host reservation.
+type KeaReservation6 struct {
+	HWAddress   string       `json:"hw-address"`
+	IPAddresses []string     `json:"ip-addresses"`
+	Hostname    string       `json:"hostname,omitempty"`
+	OptionData  []DHCPOption `json:"option-data,omitempty"`
+}
+
+// KeaSubnet6 is an entry of Kea's Dhcp6.subnet6.
+type KeaSubnet6 struct {
+	ID           int               `json:"id"`
+	Subnet       string            `json:"subnet"`
+	OptionData   []DHCPOption      `json:"option-data,omitempty"`
+	Reservations []KeaReservation6 `json:"reservations"`
+}
+
+// KeaDhcp6Config is the generated part of a Kea Dhcp6 config.
+type KeaDhcp6Config struct {
+	Subnet6 []KeaSubnet6 `json:"subnet6"`
+}
+
+// reservation builds the reservation of host.
+func (gen *KeaGenerator) reservation(host hostEntry) (KeaReservation, error) {
+	reservation := KeaReservation{
+		HWAddress: host.MAC.String(),
+		IPAddress: host.IPAddr.String(),
+		Hostname:  host.FQDN,
+	}
+	if gen.Policy != nil {
+		set, err := gen.Policy.Evaluate(host.Placement)
+		if err != nil {
+			return reservation, fmt.Errorf("option policy for %s (%s): %w", host.Interface.ID, host.IPAddr, err)
+		}
+		if host.IPAddr.Is4() {
+			reservation.NextServer = set.NextServer
+			reservation.BootFileName = set.BootFileName
+		}
+		reservation.OptionData = set.Options
+	}
+	if gen.Options != nil {
+		options, err := gen.Options(host.Placement)
+		if err != nil {
+			return reservation, fmt.Errorf("options for %s (%s): %w", host.Interface.ID, host.IPAddr, err)
+		}
+		reservation.OptionData = mergeOptions(reservation.OptionData, options)
+	}
+	return reservation, nil
+}
+
+// reservations places ethInterfaces and builds the reservations of the
+// subnets of one IP version, by subnet ID. It returns those subnets too.
+func (gen *KeaGenerator) reservations(ethInterfaces []sm.CompEthInterfaceV2, family ipFamily) (subnets []*Subnet,
+	bySubnet map[int][]KeaReservation, unplaced []Unplaced, err error) {
+	subnets, hosts, unplaced, err := placeHosts(gen.Subnets, gen.Namer, ethInterfaces, family)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	bySubnet = make(map[int][]KeaReservation)
+	for _, subnet := range subnets {
+		bySubnet[subnet.ID] = []KeaReservation{}
+	}
+	for _, host := range hosts {
+		reservation, resErr := gen.reservation(host)
+		if resErr != nil {
+			return nil, nil, unplaced, resErr
+		}
+		bySubnet[host.Subnet.ID] = append(bySubnet[host.Subnet.ID], reservation)
+	}
+	return subnets, bySubnet, unplaced, nil
+}
+
+// Dhcp4 generates a reservation for every IPv4 address of ethInterfaces, in
+// the subnet4 entry of its subnet. Every IPv4 subnet is listed, even
+// without reservations. Addresses left out are returned in unplaced, see
+// SubnetTable.Place(); an error is only returned if Options fails.
+func (gen *KeaGenerator) Dhcp4(ethInterfaces []sm.CompEthInterfaceV2) (config *KeaDhcp4Config,
+	unplaced []Unplaced, err error) {
+	subnets, bySubnet, unplaced, err := gen.reservations(ethInterfaces, ipv4Family)
+	if err != nil {
+		return nil, unplaced, err
+	}
+
+	config = &KeaDhcp4Config{Subnet4: []KeaSubnet4{}}
+	for _, subnet := range subnets {
+		config.Subnet4 = append(config.Subnet4, KeaSubnet4{
+			ID:           subnet.ID,
+			Subnet:       subnet.CIDR,
+			OptionData:   subnet.Options,
+			Reservations: bySubnet[subnet.ID],
+		})
+	}
+	return config, unplaced, nil
+}
+
+// Dhcp6 is Dhcp4 for IPv6 addresses and subnets. Reservations are keyed on
+// the hw-address, so the Kea server must be able to learn client MACs (see
+// its mac-sources setting).
+func (gen *KeaGenerator) Dhcp6(ethInterfaces []sm.CompEthInterfaceV2) (config *KeaDhcp6Config,
+	unplaced []Unplaced, err error) {
+	subnets, bySubnet, unplaced, err := gen.reservations(ethInterfaces, ipv6Family)
+	if err != nil {
+		return nil, unplaced, err
+	}
+
+	config = &KeaDhcp6Config{Subnet6: []KeaSubnet6{}}
+	for _, subnet := range subnets {
+		entry := KeaSubnet6{
+			ID:           subnet.ID,
+			Subnet:       subnet.CIDR,
+			OptionData:   subnet.Options,
+			Reservations: []KeaReservation6{},
+		}
+		for _, reservation := range bySubnet[subnet.ID] {
+			entry.Reservations = append(entry.Reservations, KeaReservation6{
+				HWAddress:   reservation.HWAddress,
+				IPAddresses: []string{reservation.IPAddress},
+				Hostname:    reservation.Hostname,
+				OptionData:  reservation.OptionData,
+			})
+		}
+		config.Subnet6 = append(config.Subnet6, entry)
+	}
+	return config, unplaced, nil
+}
+
+// WriteJSON writes config as {"Dhcp4": {"subnet4": [...]}}, indented.
+func (config *KeaDhcp4Config) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]*KeaDhcp4Config{"Dhcp4": config})
+}
+
+// WriteJSON writes config as {"Dhcp6": {"subnet6": [...]}}, indented.
+func (config *KeaDhcp6Config) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]*KeaDhcp6Config{"Dhcp6": config})
+}
+
+func (helper *DNSDHCPHelper) GenerateKeaDhcp4(gen *KeaGenerator) (config *KeaDhcp4Config, unplaced []Unplaced,
+	err error) {
+	return helper.GenerateKeaDhcp4Ctx(context.Background(), gen)
+}
+
+// GenerateKeaDhcp4Ctx runs gen over every interface in HSM.
+func (helper *DNSDHCPHelper) GenerateKeaDhcp4Ctx(ctx context.Context, gen *KeaGenerator) (
+	config *KeaDhcp4Config, unplaced []Unplaced, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	return gen.Dhcp4(ethInterfaces)
+}
+
+func (helper *DNSDHCPHelper) GenerateKeaDhcp6(gen *KeaGenerator) (config *KeaDhcp6Config, unplaced []Unplaced,
+	err error) {
+	return helper.GenerateKeaDhcp6Ctx(context.Background(), gen)
+}
+
+// GenerateKeaDhcp6Ctx runs gen.Dhcp6() over every interface in HSM.
+func (helper *DNSDHCPHelper) GenerateKeaDhcp6Ctx(ctx context.Context, gen *KeaGenerator) (
+	config *KeaDhcp6Config, unplaced []Unplaced, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	return gen.Dhcp6(ethInterfaces)
+}
@@ -0,0 +1,530 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+)
+
+// KeaClient sends commands to a Kea Control Agent, which forwards them to
+// the DHCP server. It is configured with the same options as the HSM
+// helper (WithHTTPClient(), WithServiceName(), WithHeader(),
+// WithTokenSource(), WithRequestTimeout(), ...), and makes its requests the
+// same way: retried, bound to the caller's context, and carrying the
+// service's User-Agent. Kea's basic auth can be set with
+// WithHeader("Authorization", "Basic ...").
+
+// HMSError classes used for errors returned by Kea. The result codes are
+// those of the Kea control channel.
+const (
+	KeaErrClassError       = "kea-error"       // result 1
+	KeaErrClassUnsupported = "kea-unsupported" // result 2
+	KeaErrClassEmpty       = "kea-empty"       // result 3, e.g. nothing to delete
+	KeaErrClassConflict    = "kea-conflict"    // result 4
+	KeaErrClassUnavailable = "kea-unavailable"
+)
+
+var ErrKeaError = base.NewHMSError(KeaErrClassError, "Kea command failed")
+var ErrKeaUnsupported = base.NewHMSError(KeaErrClassUnsupported, "Kea command not supported")
+var ErrKeaEmpty = base.NewHMSError(KeaErrClassEmpty, "Kea found nothing to act on")
+var ErrKeaConflict = base.NewHMSError(KeaErrClassConflict, "Kea command conflicts with current state")
+var ErrKeaUnavailable = base.NewHMSError(KeaErrClassUnavailable, "Kea Control Agent unavailable")
+
+// Kea result codes.
+const (
+	KeaResultSuccess     = 0
+	KeaResultError       = 1
+	KeaResultUnsupported = 2
+	KeaResultEmpty       = 3
+	KeaResultConflict    = 4
+)
+
+// KeaError is returned when a Kea command fails: with a non-zero result,
+// with an HTTP error from the Control Agent, or because the agent couldn't
+// be reached (StatusCode 0). It matches the sentinels above with
+// errors.Is().
+type KeaError struct {
+	*base.HMSError
+
+	Command    string
+	Service    string
+	Result     int
+	Text       string
+	StatusCode int
+
+	// Underlying transport error, if the agent couldn't be reached.
+	Err error
+}
+
+func (e *KeaError) Error() string {
+	msg := fmt.Sprintf("Kea %s", e.Command)
+	if e.Service != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Service)
+	}
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s failed: %v", msg, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s failed (%d %s): %s", msg, e.StatusCode, http.StatusText(e.StatusCode), e.Text)
+	}
+	return fmt.Sprintf("%s failed (result %d): %s", msg, e.Result, e.Text)
+}
+
+func (e *KeaError) Is(target error) bool {
+	hmserr, ok := base.GetHMSError(target)
+	return ok && e.HMSError.IsClass(hmserr.Class)
+}
+
+func (e *KeaError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.HMSError, e.Err}
+	}
+	return []error{e.HMSError}
+}
+
+func keaSentinelForResult(result int) *base.HMSError {
+	switch result {
+	case KeaResultUnsupported:
+		return ErrKeaUnsupported
+	case KeaResultEmpty:
+		return ErrKeaEmpty
+	case KeaResultConflict:
+		return ErrKeaConflict
+	}
+	return ErrKeaError
+}
+
+// KeaResponse is the response of one server to a command.
+type KeaResponse struct {
+	Result    int             `json:"result"`
+	Text      string          `json:"text,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// KeaLease4 is a DHCPv4 lease as returned by the lease4 commands.
+type KeaLease4 struct {
+	IPAddress     string `json:"ip-address"`
+	HWAddress     string `json:"hw-address"`
+	ClientID      string `json:"client-id,omitempty"`
+	ValidLifetime int64  `json:"valid-lft"`
+	CLTT          int64  `json:"cltt"`
+	SubnetID      int    `json:"subnet-id"`
+	FQDNFwd       bool   `json:"fqdn-fwd"`
+	FQDNRev       bool   `json:"fqdn-rev"`
+	Hostname      string `json:"hostname,omitempty"`
+	State         int    `json:"state"`
+}
+
+// Expires returns the time the lease runs out.
+func (lease KeaLease4) Expires() time.Time {
+	return time.Unix(lease.CLTT+lease.ValidLifetime, 0)
+}
+
+// Kea DHCPv6 lease types.
+const (
+	KeaLeaseIANA = "IA_NA"
+	KeaLeaseIATA = "IA_TA"
+	KeaLeaseIAPD = "IA_PD"
+)
+
+// KeaLease6 is a DHCPv6 lease as returned by the lease6 commands. The
+// client is identified by DUID and IAID; HWAddress is only set if the
+// server learned the client's MAC.
+type KeaLease6 struct {
+	IPAddress         string `json:"ip-address"`
+	DUID              string `json:"duid"`
+	IAID              uint32 `json:"iaid"`
+	HWAddress         string `json:"hw-address,omitempty"`
+	Type              string `json:"type"`
+	PrefixLen         int    `json:"prefix-len"`
+	ValidLifetime     int64  `json:"valid-lft"`
+	PreferredLifetime int64  `json:"preferred-lft"`
+	CLTT              int64  `json:"cltt"`
+	SubnetID          int    `json:"subnet-id"`
+	FQDNFwd           bool   `json:"fqdn-fwd"`
+	FQDNRev           bool   `json:"fqdn-rev"`
+	Hostname          string `json:"hostname,omitempty"`
+	State             int    `json:"state"`
+}
+
+// Expires returns the time the lease runs out.
+func (lease KeaLease6) Expires() time.Time {
+	return time.Unix(lease.CLTT+lease.ValidLifetime, 0)
+}
+
+// KeaHost is a host reservation as stored by Kea's host_cmds hook.
+type KeaHost struct {
+	KeaReservation
+	SubnetID int `json:"subnet-id"`
+}
+
+type KeaClient struct {
+	URL string
+	// The server commands go to, "dhcp4" unless set otherwise.
+	Service string
+	// The server lease6 commands go to, "dhcp6" unless set otherwise.
+	Service6 string
+
+	http *DNSDHCPHelper
+}
+
+// NewKeaClient creates a client for the Kea Control Agent at agentURL,
+// e.g. "http://kea:8000", sending commands to the dhcp4 service and lease6
+// commands to the dhcp6 one.
+func NewKeaClient(agentURL string, opts ...Option) *KeaClient {
+	return &KeaClient{
+		URL:      strings.TrimSuffix(agentURL, "/"),
+		Service:  "dhcp4",
+		Service6: "dhcp6",
+		http:     New(agentURL, opts...),
+	}
+}
+
+type keaCommand struct {
+	Command   string      `json:"command"`
+	Service   []string    `json:"service,omitempty"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+// CommandCtx sends command with arguments (nil for none) to the client's
+// service and returns the server's response. A non-zero result is
+// returned as a KeaError along with the response. Use this for commands
+// without a method of their own.
+func (client *KeaClient) CommandCtx(ctx context.Context, command string, arguments interface{}) (
+	response KeaResponse, err error) {
+	return client.serviceCommandCtx(ctx, client.Service, command, arguments)
+}
+
+// serviceCommandCtx is CommandCtx for any service.
+func (client *KeaClient) serviceCommandCtx(ctx context.Context, service string, command string,
+	arguments interface{}) (response KeaResponse, err error) {
+	payload, err := json.Marshal(keaCommand{Command: command, Service: []string{service}, Arguments: arguments})
+	if err != nil {
+		return
+	}
+
+	rsp, err := doRequest(ctx, client.http, "Kea", "POST", client.URL+"/", payload)
+	if err != nil {
+		var tErr *transportError
+		if errors.As(err, &tErr) {
+			err = &KeaError{HMSError: ErrKeaUnavailable.NewChild(""), Command: command, Service: service,
+				Err: tErr.err}
+		} else {
+			err = fmt.Errorf("Kea %s: %w", command, err)
+		}
+		return
+	}
+	body, err := readServerBody(ctx, "Kea", rsp)
+	if err != nil {
+		err = fmt.Errorf("Kea %s: %w", command, err)
+		return
+	}
+	if rsp.StatusCode != http.StatusOK {
+		sentinel := ErrKeaError
+		if sentinelForStatus(rsp.StatusCode) == ErrUnavailable {
+			sentinel = ErrKeaUnavailable
+		}
+		err = &KeaError{HMSError: sentinel.NewChild(""), Command: command, Service: service,
+			StatusCode: rsp.StatusCode, Text: strings.TrimSpace(string(body))}
+		return
+	}
+
+	// The agent answers with one response per service, or with a bare
+	// response when it handled the command (or rejected it) itself.
+	var responses []KeaResponse
+	if err = json.Unmarshal(body, &responses); err != nil {
+		responses = make([]KeaResponse, 1)
+		if err = json.Unmarshal(body, &responses[0]); err != nil {
+			err = fmt.Errorf("Kea %s: malformed response: %w", command, err)
+			return
+		}
+	}
+	if len(responses) == 0 {
+		err = fmt.Errorf("Kea %s: empty response", command)
+		return
+	}
+
+	response = responses[0]
+	if response.Result != KeaResultSuccess {
+		err = &KeaError{HMSError: keaSentinelForResult(response.Result).NewChild(""), Command: command,
+			Service: service, Result: response.Result, Text: response.Text}
+	}
+	return
+}
+
+func (client *KeaClient) Command(command string, arguments interface{}) (response KeaResponse, err error) {
+	return client.CommandCtx(context.Background(), command, arguments)
+}
+
+// commandCtx runs a command and decodes its arguments into result, if
+// given.
+func (client *KeaClient) commandCtx(ctx context.Context, command string, arguments interface{},
+	result interface{}) error {
+	return client.decodeCommandCtx(ctx, client.Service, command, arguments, result)
+}
+
+func (client *KeaClient) decodeCommandCtx(ctx context.Context, service string, command string,
+	arguments interface{}, result interface{}) error {
+	response, err := client.serviceCommandCtx(ctx, service, command, arguments)
+	if err != nil {
+		return err
+	}
+	if result != nil && len(response.Arguments) > 0 {
+		if err = json.Unmarshal(response.Arguments, result); err != nil {
+			return fmt.Errorf("Kea %s: malformed arguments: %w", command, err)
+		}
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+// Configuration
+///////////////////////////////////////////////////////////////////////////
+
+func (client *KeaClient) ConfigGet() (config json.RawMessage, err error) {
+	return client.ConfigGetCtx(context.Background())
+}
+
+// ConfigGetCtx returns the server's running config, e.g. {"Dhcp4": {...}}.
+func (client *KeaClient) ConfigGetCtx(ctx context.Context) (config json.RawMessage, err error) {
+	err = client.commandCtx(ctx, "config-get", nil, &config)
+	return
+}
+
+func (client *KeaClient) ConfigTest(config json.RawMessage) (err error) {
+	return client.ConfigTestCtx(context.Background(), config)
+}
+
+// ConfigTestCtx has the server check config without applying it. A config
+// it rejects fails with ErrKeaError and Kea's reason in the KeaError Text.
+func (client *KeaClient) ConfigTestCtx(ctx context.Context, config json.RawMessage) (err error) {
+	return client.commandCtx(ctx, "config-test", config, nil)
+}
+
+func (client *KeaClient) ConfigSet(config json.RawMessage) (err error) {
+	return client.ConfigSetCtx(context.Background(), config)
+}
+
+// ConfigSetCtx replaces the server's running config. It isn't saved; see
+// ConfigWriteCtx().
+func (client *KeaClient) ConfigSetCtx(ctx context.Context, config json.RawMessage) (err error) {
+	return client.commandCtx(ctx, "config-set", config, nil)
+}
+
+func (client *KeaClient) ConfigWrite(filename string) (written string, err error) {
+	return client.ConfigWriteCtx(context.Background(), filename)
+}
+
+// ConfigWriteCtx saves the running config to filename, or to the file it
+// was loaded from if filename is empty, and returns the file written.
+func (client *KeaClient) ConfigWriteCtx(ctx context.Context, filename string) (written string, err error) {
+	var arguments interface{}
+	if filename != "" {
+		arguments = map[string]string{"filename": filename}
+	}
+	var result struct {
+		Filename string `json:"filename"`
+	}
+	err = client.commandCtx(ctx, "config-write", arguments, &result)
+	return result.Filename, err
+}
+
+///////////////////////////////////////////////////////////////////////////
+// Leases (lease_cmds hook)
+///////////////////////////////////////////////////////////////////////////
+
+type keaLeases4 struct {
+	Leases []KeaLease4 `json:"leases"`
+}
+
+func (client *KeaClient) Lease4GetAll(subnetIDs ...int) (leases []KeaLease4, err error) {
+	return client.Lease4GetAllCtx(context.Background(), subnetIDs...)
+}
+
+// Lease4GetAllCtx returns the leases of the given subnets, or of all
+// subnets if none are given. No leases is not an error.
+func (client *KeaClient) Lease4GetAllCtx(ctx context.Context, subnetIDs ...int) (leases []KeaLease4, err error) {
+	var arguments interface{}
+	if len(subnetIDs) > 0 {
+		arguments = map[string][]int{"subnets": subnetIDs}
+	}
+	var result keaLeases4
+	err = client.commandCtx(ctx, "lease4-get-all", arguments, &result)
+	if errors.Is(err, ErrKeaEmpty) {
+		return nil, nil
+	}
+	return result.Leases, err
+}
+
+func (client *KeaClient) Lease4GetByHWAddress(hwAddr string) (leases []KeaLease4, err error) {
+	return client.Lease4GetByHWAddressCtx(context.Background(), hwAddr)
+}
+
+// Lease4GetByHWAddressCtx returns the leases of a MAC address, in any
+// notation mac.Parse() understands. No leases is not an error.
+func (client *KeaClient) Lease4GetByHWAddressCtx(ctx context.Context, hwAddr string) (leases []KeaLease4,
+	err error) {
+	hwMAC, _, err := normalizeKeaMAC(hwAddr)
+	if err != nil {
+		return
+	}
+	var result keaLeases4
+	err = client.commandCtx(ctx, "lease4-get-by-hw-address", map[string]string{"hw-address": hwMAC}, &result)
+	if errors.Is(err, ErrKeaEmpty) {
+		return nil, nil
+	}
+	return result.Leases, err
+}
+
+func (client *KeaClient) Lease4Del(ipAddr string) (err error) {
+	return client.Lease4DelCtx(context.Background(), ipAddr)
+}
+
+// Lease4DelCtx deletes the lease of ipAddr. Deleting a lease that doesn't
+// exist fails with ErrKeaEmpty.
+func (client *KeaClient) Lease4DelCtx(ctx context.Context, ipAddr string) (err error) {
+	return client.commandCtx(ctx, "lease4-del", map[string]string{"ip-address": ipAddr}, nil)
+}
+
+type keaLeases6 struct {
+	Leases []KeaLease6 `json:"leases"`
+}
+
+func (client *KeaClient) Lease6GetAll(subnetIDs ...int) (leases []KeaLease6, err error) {
+	return client.Lease6GetAllCtx(context.Background(), subnetIDs...)
+}
+
+// Lease6GetAllCtx returns the DHCPv6 leases of the given subnets, or of all
+// subnets if none are given, from the client's Service6. No leases is not
+// an error.
+func (client *KeaClient) Lease6GetAllCtx(ctx context.Context, subnetIDs ...int) (leases []KeaLease6, err error) {
+	var arguments interface{}
+	if len(subnetIDs) > 0 {
+		arguments = map[string][]int{"subnets": subnetIDs}
+	}
+	var result keaLeases6
+	err = client.decodeCommandCtx(ctx, client.Service6, "lease6-get-all", arguments, &result)
+	if errors.Is(err, ErrKeaEmpty) {
+		return nil, nil
+	}
+	return result.Leases, err
+}
+
+func (client *KeaClient) Lease6Del(ipAddr string) (err error) {
+	return client.Lease6DelCtx(context.Background(), ipAddr)
+}
+
+// Lease6DelCtx deletes the DHCPv6 lease of ipAddr. Deleting a lease that
+// doesn't exist fails with ErrKeaEmpty.
+func (client *KeaClient) Lease6DelCtx(ctx context.Context, ipAddr string) (err error) {
+	return client.decodeCommandCtx(ctx, client.Service6, "lease6-del", map[string]string{"ip-address": ipAddr}, nil)
+}
+
+///////////////////////////////////////////////////////////////////////////
+// Reservations (host_cmds hook)
+///////////////////////////////////////////////////////////////////////////
+
+func (client *KeaClient) ReservationAdd(subnetID int, reservation KeaReservation) (err error) {
+	return client.ReservationAddCtx(context.Background(), subnetID, reservation)
+}
+
+// ReservationAddCtx adds reservation to subnet subnetID. Kea refuses a
+// reservation whose address or MAC is already reserved in the subnet.
+func (client *KeaClient) ReservationAddCtx(ctx context.Context, subnetID int, reservation KeaReservation) (
+	err error) {
+	arguments := map[string]KeaHost{"reservation": {KeaReservation: reservation, SubnetID: subnetID}}
+	return client.commandCtx(ctx, "reservation-add", arguments, nil)
+}
+
+func (client *KeaClient) ReservationDel(subnetID int, ipAddr string) (err error) {
+	return client.ReservationDelCtx(context.Background(), subnetID, ipAddr)
+}
+
+// ReservationDelCtx deletes the reservation of ipAddr in subnet subnetID.
+// Deleting a reservation that doesn't exist fails with ErrKeaEmpty.
+func (client *KeaClient) ReservationDelCtx(ctx context.Context, subnetID int, ipAddr string) (err error) {
+	arguments := map[string]interface{}{"subnet-id": subnetID, "ip-address": ipAddr}
+	return client.reservationDelCtx(ctx, arguments)
+}
+
+func (client *KeaClient) ReservationDelByHWAddress(subnetID int, hwAddr string) (err error) {
+	return client.ReservationDelByHWAddressCtx(context.Background(), subnetID, hwAddr)
+}
+
+// ReservationDelByHWAddressCtx deletes the reservation of MAC hwAddr in
+// subnet subnetID, whatever its address. Deleting a reservation that
+// doesn't exist fails with ErrKeaEmpty.
+func (client *KeaClient) ReservationDelByHWAddressCtx(ctx context.Context, subnetID int, hwAddr string) (
+	err error) {
+	arguments := map[string]interface{}{"subnet-id": subnetID, "identifier-type": "hw-address",
+		"identifier": hwAddr}
+	return client.reservationDelCtx(ctx, arguments)
+}
+
+func (client *KeaClient) reservationDelCtx(ctx context.Context, arguments map[string]interface{}) (err error) {
+	err = client.commandCtx(ctx, "reservation-del", arguments, nil)
+
+	// Older Kea versions report a missing reservation as an error.
+	var keaErr *KeaError
+	if errors.As(err, &keaErr) && keaErr.Result == KeaResultError &&
+		strings.Contains(strings.ToLower(keaErr.Text), "not deleted") {
+		keaErr.HMSError = ErrKeaEmpty.NewChild("")
+	}
+	return
+}
+
+func (client *KeaClient) ReservationGetAll(subnetID int) (hosts []KeaHost, err error) {
+	return client.ReservationGetAllCtx(context.Background(), subnetID)
+}
+
+// ReservationGetAllCtx returns the reservations of subnet subnetID. No
+// reservations is not an error.
+func (client *KeaClient) ReservationGetAllCtx(ctx context.Context, subnetID int) (hosts []KeaHost, err error) {
+	var result struct {
+		Hosts []KeaHost `json:"hosts"`
+	}
+	err = client.commandCtx(ctx, "reservation-get-all", map[string]int{"subnet-id": subnetID}, &result)
+	if errors.Is(err, ErrKeaEmpty) {
+		return nil, nil
+	}
+	return result.Hosts, err
+}
+
+// normalizeKeaMAC returns a MAC address in the form Kea uses, and its HSM
+// ID.
+func normalizeKeaMAC(hwAddr string) (canonical string, hsmID string, err error) {
+	canonical, hsmID, err = mac.Normalize(hwAddr)
+	if err != nil {
+		err = fmt.Errorf("hw-address '%s': %w", hwAddr, err)
+	}
+	return
+}
@@ -0,0 +1,233 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// keaStandIn answers every command with reply and records the last
+// request.
+type keaStandIn struct {
+	status    int
+	reply     string
+	lastBody  map[string]interface{}
+	userAgent string
+	auth      string
+}
+
+func (kea *keaStandIn) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	kea.lastBody = nil
+	json.Unmarshal(body, &kea.lastBody)
+	kea.userAgent = req.Header.Get("User-Agent")
+	kea.auth = req.Header.Get("Authorization")
+	status := kea.status
+	if (status == 0) {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(kea.reply))
+}
+
+func newKeaTestClient(kea *keaStandIn, opts ...Option) (*KeaClient, func()) {
+	srv := httptest.NewServer(kea)
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryMax = 0
+	httpClient.Logger = nil
+	httpClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	opts = append([]Option{WithHTTPClient(httpClient), WithServiceName("kea-test")}, opts...)
+	return NewKeaClient(srv.URL+"/", opts...), srv.Close
+}
+
+func TestKeaCommand(t *testing.T) {
+	kea := &keaStandIn{reply: `[{"result":0,"text":"ok","arguments":{"Dhcp4":{"subnet4":[]}}}]`}
+	client, done := newKeaTestClient(kea, WithHeader("Authorization", "Basic a2VhOmtlYQ=="))
+	defer done()
+
+	config, err := client.ConfigGet()
+	if (err != nil || string(config) != `{"Dhcp4":{"subnet4":[]}}`) {
+		t.Errorf("ERROR, ConfigGet() returned %s, error: %v", config, err)
+	}
+	if (kea.lastBody["command"] != "config-get" || kea.lastBody["arguments"] != nil) {
+		t.Errorf("ERROR, unexpected command %v", kea.lastBody)
+	}
+	if service, _ := kea.lastBody["service"].([]interface{}); (len(service) != 1 || service[0] != "dhcp4") {
+		t.Errorf("ERROR, unexpected service %v", kea.lastBody["service"])
+	}
+	if (kea.userAgent != "kea-test" || kea.auth != "Basic a2VhOmtlYQ==") {
+		t.Errorf("ERROR, unexpected headers User-Agent '%s', Authorization '%s'", kea.userAgent, kea.auth)
+	}
+
+	// The agent's own answers aren't wrapped in an array.
+	kea.reply = `{"result":1,"text":"'bogus' command not supported."}`
+	_, err = client.Command("bogus", nil)
+	var keaErr *KeaError
+	if (!errors.Is(err, ErrKeaError) || !errors.As(err, &keaErr) || keaErr.Text != "'bogus' command not supported.") {
+		t.Errorf("ERROR, expected a Kea error, got %v", err)
+	}
+
+	tests := []struct {
+		status   int
+		reply    string
+		sentinel error
+	}{
+		{0, `[{"result":2,"text":"not supported"}]`, ErrKeaUnsupported},
+		{0, `[{"result":3,"text":"IPv4 lease not found."}]`, ErrKeaEmpty},
+		{0, `[{"result":4,"text":"conflict"}]`, ErrKeaConflict},
+		{http.StatusUnauthorized, `Unauthorized`, ErrKeaError},
+		{http.StatusServiceUnavailable, `down`, ErrKeaUnavailable},
+	}
+	for ix, test := range tests {
+		kea.status = test.status
+		kea.reply = test.reply
+		err = client.Lease4Del("10.252.1.5")
+		if (!errors.Is(err, test.sentinel)) {
+			t.Errorf("ERROR, test %d: expected %v, got %v", ix, test.sentinel, err)
+		}
+	}
+
+	kea.status = 0
+	kea.reply = `[{"result":0`
+	if _, err = client.ConfigGet(); (err == nil) {
+		t.Errorf("ERROR, expected an error for a malformed response")
+	}
+}
+
+func TestKeaLeasesAndReservations(t *testing.T) {
+	kea := &keaStandIn{reply: `[{"result":0,"text":"1 IPv4 lease(s) found.","arguments":{"leases":[
+		{"ip-address":"10.252.1.5","hw-address":"a4:bf:01:2e:7f:b1","valid-lft":3600,"cltt":1760000000,
+		"subnet-id":10,"hostname":"x3000c0s1b0n0","state":0}]}}]`}
+	client, done := newKeaTestClient(kea)
+	defer done()
+
+	leases, err := client.Lease4GetAll(10, 20)
+	if (err != nil || len(leases) != 1 || leases[0].SubnetID != 10 ||
+		!leases[0].Expires().Equal(time.Unix(1760003600, 0))) {
+		t.Errorf("ERROR, Lease4GetAll() returned %v, error: %v", leases, err)
+	}
+	if subnets := kea.lastBody["arguments"].(map[string]interface{})["subnets"].([]interface{}); (len(subnets) != 2) {
+		t.Errorf("ERROR, unexpected arguments %v", kea.lastBody["arguments"])
+	}
+
+	leases, err = client.Lease4GetByHWAddress("A4BF.012E.7FB1")
+	if (err != nil || len(leases) != 1 ||
+		kea.lastBody["arguments"].(map[string]interface{})["hw-address"] != "a4:bf:01:2e:7f:b1") {
+		t.Errorf("ERROR, Lease4GetByHWAddress() returned %v, error: %v; sent %v", leases, err, kea.lastBody)
+	}
+	if _, err = client.Lease4GetByHWAddress("bogus"); (err == nil) {
+		t.Errorf("ERROR, expected an error for a bad MAC")
+	}
+
+	// Nothing found is not an error.
+	kea.reply = `[{"result":3,"text":"0 IPv4 lease(s) found.","arguments":{"leases":[]}}]`
+	leases, err = client.Lease4GetAll()
+	if (err != nil || len(leases) != 0) {
+		t.Errorf("ERROR, Lease4GetAll() returned %v, error: %v", leases, err)
+	}
+
+	kea.reply = `[{"result":0,"text":"Host added."}]`
+	err = client.ReservationAdd(10, KeaReservation{HWAddress: "a4:bf:01:2e:7f:b1", IPAddress: "10.252.1.5"})
+	reservation := kea.lastBody["arguments"].(map[string]interface{})["reservation"].(map[string]interface{})
+	if (err != nil || reservation["subnet-id"] != float64(10) || reservation["ip-address"] != "10.252.1.5") {
+		t.Errorf("ERROR, ReservationAdd() error: %v; sent %v", err, kea.lastBody)
+	}
+
+	// Older Kea versions fail deleting a missing reservation with result 1.
+	kea.reply = `[{"result":1,"text":"Host not deleted (not found)."}]`
+	if err = client.ReservationDel(10, "10.252.1.5"); (!errors.Is(err, ErrKeaEmpty)) {
+		t.Errorf("ERROR, expected ErrKeaEmpty, got %v", err)
+	}
+
+	kea.reply = `[{"result":0,"text":"1 IPv4 host(s) found.","arguments":{"hosts":[
+		{"subnet-id":10,"hw-address":"a4:bf:01:2e:7f:b1","ip-address":"10.252.1.5","hostname":"nid000001"}]}}]`
+	hosts, err := client.ReservationGetAll(10)
+	if (err != nil || len(hosts) != 1 || hosts[0].SubnetID != 10 || hosts[0].Hostname != "nid000001") {
+		t.Errorf("ERROR, ReservationGetAll() returned %v, error: %v", hosts, err)
+	}
+
+	kea.reply = `[{"result":0,"text":"Configuration written to /tmp/kea.conf successful","arguments":{"filename":"/tmp/kea.conf","size":10}}]`
+	written, err := client.ConfigWrite("/tmp/kea.conf")
+	if (err != nil || written != "/tmp/kea.conf") {
+		t.Errorf("ERROR, ConfigWrite() returned %s, error: %v", written, err)
+	}
+}
+
+func TestKeaLeases6(t *testing.T) {
+	kea := &keaStandIn{reply: `[{"result":0,"arguments":{"leases":[{"ip-address":"fd00::5",
+		"duid":"00:03:00:01:a4:bf:01:2e:7f:b1","iaid":1,"type":"IA_NA","subnet-id":60}]}}]`}
+	client, done := newKeaTestClient(kea)
+	defer done()
+
+	leases, err := client.Lease6GetAll(60)
+	if (err != nil || len(leases) != 1 || leases[0].IAID != 1) {
+		t.Errorf("ERROR, Lease6GetAll() returned %v, error: %v", leases, err)
+	}
+	if service, _ := kea.lastBody["service"].([]interface{}); (len(service) != 1 || service[0] != "dhcp6") {
+		t.Errorf("ERROR, lease6-get-all went to %v", kea.lastBody["service"])
+	}
+
+	kea.reply = `[{"result":3,"text":"IPv6 lease not found."}]`
+	var keaErr *KeaError
+	err = client.Lease6Del("fd00::5")
+	if (!errors.Is(err, ErrKeaEmpty) || !errors.As(err, &keaErr) || keaErr.Service != "dhcp6") {
+		t.Errorf("ERROR, expected ErrKeaEmpty from dhcp6, got %v", err)
+	}
+}
+
+func TestKeaClientContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`[{"result":0}]`))
+	}))
+	defer srv.Close()
+
+	client := NewKeaClient(srv.URL)
+	client.http.HTTPClient.Logger = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := client.ConfigTestCtx(ctx, json.RawMessage(`{"Dhcp4":{}}`))
+	if (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected a deadline error, got %v", err)
+	}
+	if (err == nil || !strings.Contains(err.Error(), "request to Kea aborted")) {
+		t.Errorf("ERROR, aborted command doesn't name Kea: %v", err)
+	}
+
+	srv.Close()
+	err = NewKeaClient(srv.URL, WithHTTPClient(&retryablehttp.Client{HTTPClient: http.DefaultClient,
+		CheckRetry: retryablehttp.DefaultRetryPolicy, Backoff: retryablehttp.DefaultBackoff})).ConfigSet(
+		json.RawMessage(`{"Dhcp4":{}}`))
+	if (!errors.Is(err, ErrKeaUnavailable)) {
+		t.Errorf("ERROR, expected ErrKeaUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var keaTestInterfaces = []sm.CompEthInterfaceV2{
+	{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02", CompID: "x3000c0s2b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.12"}}},
+	{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01", CompID: "x3000c0s1b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.11"}, {IPAddr: "fd00::11"}}},
+	{ID: "a4bf01000003", MACAddr: "a4:bf:01:00:00:03",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.99.0.1"}}},
+}
+
+func TestKeaDhcp4(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 20, CIDR: "10.254.0.0/17", Network: "HMN"},
+		{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN",
+			Options: []DHCPOption{{Name: "routers", Data: "10.252.0.1"}}},
+		{ID: 30, CIDR: "fd00::/64"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	gen := &KeaGenerator{
+		Subnets: table,
+		Options: func(placement Placement) ([]DHCPOption, error) {
+			if (placement.Interface.CompID == "") {
+				return nil, nil
+			}
+			return []DHCPOption{{Name: "boot-file-name", Data: "ipxe.efi"}}, nil
+		},
+	}
+
+	// The order of the input doesn't matter.
+	for _, ethInterfaces := range [][]sm.CompEthInterfaceV2{keaTestInterfaces,
+		{keaTestInterfaces[2], keaTestInterfaces[1], keaTestInterfaces[0]}} {
+		config, unplaced, err := gen.Dhcp4(ethInterfaces)
+		if (err != nil) {
+			t.Fatalf("ERROR, Dhcp4() failed: %v", err)
+		}
+		if (len(unplaced) != 1 || unplaced[0].ID != "a4bf01000003" || unplaced[0].Reason != UnplacedNoSubnet) {
+			t.Errorf("ERROR, unexpected unplaced %v", unplaced)
+		}
+
+		var out bytes.Buffer
+		if err = config.WriteJSON(&out); (err != nil) {
+			t.Fatalf("ERROR, WriteJSON() failed: %v", err)
+		}
+		expected := `{
+  "Dhcp4": {
+    "subnet4": [
+      {
+        "id": 10,
+        "subnet": "10.252.0.0/17",
+        "option-data": [
+          {
+            "name": "routers",
+            "data": "10.252.0.1"
+          }
+        ],
+        "reservations": [
+          {
+            "hw-address": "a4:bf:01:00:00:01",
+            "ip-address": "10.252.1.11",
+            "hostname": "x3000c0s1b0n0",
+            "option-data": [
+              {
+                "name": "boot-file-name",
+                "data": "ipxe.efi"
+              }
+            ]
+          },
+          {
+            "hw-address": "a4:bf:01:00:00:02",
+            "ip-address": "10.252.1.12",
+            "hostname": "x3000c0s2b0n0",
+            "option-data": [
+              {
+                "name": "boot-file-name",
+                "data": "ipxe.efi"
+              }
+            ]
+          }
+        ]
+      },
+      {
+        "id": 20,
+        "subnet": "10.254.0.0/17",
+        "reservations": []
+      }
+    ]
+  }
+}
+`
+		if (out.String() != expected) {
+			t.Errorf("ERROR, unexpected config:\n%s", out.String())
+		}
+	}
+
+	errBoom := errors.New("boom")
+	gen.Options = func(placement Placement) ([]DHCPOption, error) { return nil, errBoom }
+	if _, _, err = gen.Dhcp4(keaTestInterfaces); (!errors.Is(err, errBoom)) {
+		t.Errorf("ERROR, expected the Options error, got %v", err)
+	}
+}
+
+func TestKeaDhcp4Namer(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	namer, err := NewNamer(NamingConfig{Domains: map[string]string{"NMN": "nmn.example.com"}, NIDFormat: "-"})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNamer() failed: %v", err)
+	}
+
+	gen := &KeaGenerator{Subnets: table, Namer: namer}
+	config, _, err := gen.Dhcp4(keaTestInterfaces)
+	if (err != nil) {
+		t.Fatalf("ERROR, Dhcp4() failed: %v", err)
+	}
+	reservations := config.Subnet4[0].Reservations
+	if (len(reservations) != 2 || reservations[0].Hostname != "x3000c0s1b0n0.nmn.example.com") {
+		t.Errorf("ERROR, unexpected reservations %v", reservations)
+	}
+}
+
+func TestKeaDhcp6(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 10, CIDR: "10.252.0.0/17"},
+		{ID: 60, CIDR: "fd00::/64", Network: "NMN", Options: []DHCPOption{{Name: "dns-servers", Data: "fd00::53"}}},
+		{ID: 70, CIDR: "fd00:1::/64"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	gen := &KeaGenerator{Subnets: table}
+	config, unplaced, err := gen.Dhcp6(keaTestInterfaces)
+	if (err != nil || len(unplaced) != 0) {
+		t.Fatalf("ERROR, Dhcp6() returned %v, error: %v", unplaced, err)
+	}
+
+	var out bytes.Buffer
+	if err = config.WriteJSON(&out); (err != nil) {
+		t.Fatalf("ERROR, WriteJSON() failed: %v", err)
+	}
+	expected := `{
+  "Dhcp6": {
+    "subnet6": [
+      {
+        "id": 60,
+        "subnet": "fd00::/64",
+        "option-data": [
+          {
+            "name": "dns-servers",
+            "data": "fd00::53"
+          }
+        ],
+        "reservations": [
+          {
+            "hw-address": "a4:bf:01:00:00:01",
+            "ip-addresses": [
+              "fd00::11"
+            ],
+            "hostname": "x3000c0s1b0n0"
+          }
+        ]
+      },
+      {
+        "id": 70,
+        "subnet": "fd00:1::/64",
+        "reservations": []
+      }
+    ]
+  }
+}
+`
+	if (out.String() != expected) {
+		t.Errorf("ERROR, unexpected config:\n%s", out.String())
+	}
+
+	// The DHCPv4 config doesn't see IPv6 subnets, and vice versa.
+	config4, _, err := gen.Dhcp4(keaTestInterfaces)
+	if (err != nil || len(config4.Subnet4) != 1 || len(config4.Subnet4[0].Reservations) != 2) {
+		t.Errorf("ERROR, Dhcp4() returned %v, error: %v", config4, err)
+	}
+}
+
+func TestKeaDhcp4Policy(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"}, {ID: 30, CIDR: "fd00::/64"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	policy, err := NewOptionPolicy([]OptionRule{{Type: "Node", NextServer: "10.252.0.2", BootFileName: "ipxe.efi",
+		Options: []DHCPOption{{Name: "domain-name", Data: "{{.Network}}"}, {Code: 224, Data: "a"}}}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewOptionPolicy() failed: %v", err)
+	}
+
+	// Options from the hook replace the policy's.
+	gen := &KeaGenerator{Subnets: table, Policy: policy,
+		Options: func(placement Placement) ([]DHCPOption, error) {
+			return []DHCPOption{{Code: 224, Data: "b"}}, nil
+		}}
+	config, _, err := gen.Dhcp4(keaTestInterfaces)
+	if (err != nil) {
+		t.Fatalf("ERROR, Dhcp4() failed: %v", err)
+	}
+	reservation := config.Subnet4[0].Reservations[0]
+	if (reservation.NextServer != "10.252.0.2" || reservation.BootFileName != "ipxe.efi" ||
+		!reflect.DeepEqual(reservation.OptionData, []DHCPOption{{Name: "domain-name", Data: "NMN"}, {Code: 224, Data: "b"}})) {
+		t.Errorf("ERROR, unexpected reservation %v", reservation)
+	}
+
+	// DHCPv6 reservations only get the options; the subnet has no network, so no domain-name.
+	config6, _, err := gen.Dhcp6(keaTestInterfaces)
+	if (err != nil || len(config6.Subnet6[0].Reservations) != 1 ||
+		!reflect.DeepEqual(config6.Subnet6[0].Reservations[0].OptionData, []DHCPOption{{Code: 224, Data: "b"}})) {
+		t.Errorf("ERROR, Dhcp6() returned %v, error: %v", config6, err)
+	}
+
+	gen.Policy, _ = NewOptionPolicy([]OptionRule{{NextServer: "{{.NID}}"}})
+	if _, _, err = gen.Dhcp4(keaTestInterfaces); (!errors.Is(err, ErrPolicyBadValue)) {
+		t.Errorf("ERROR, expected ErrPolicyBadValue, got %v", err)
+	}
+}
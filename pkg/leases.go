@@ -0,0 +1,636 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/netip"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Lease ingestion turns DHCP leases into HSM EthernetInterfaces: a lease's
+// MAC and IP address become an interface, its subnet gives the address's
+// Network, and a hostname that is an xname gives the ComponentID. DHCPv4
+// and DHCPv6 leases are read from lease4-get-all or lease6-get-all output
+// or from Kea's memfile CSV, and
+// written to HSM only where they change something, so ingesting the same
+// leases again writes nothing.
+
+// Reasons a lease was not ingested, in addition to the Unplaced* ones.
+const (
+	UnplacedExpiredLease     = "expired-lease"
+	UnplacedInactiveLease    = "inactive-lease"
+	UnplacedPrefixDelegation = "prefix-delegation"
+)
+
+// Kea lease states.
+const (
+	KeaLeaseDefault          = 0
+	KeaLeaseDeclined         = 1
+	KeaLeaseExpiredReclaimed = 2
+)
+
+// Ingest actions.
+const (
+	IngestCreated   = "created"
+	IngestUpdated   = "updated"
+	IngestUnchanged = "unchanged"
+	IngestFailed    = "failed"
+)
+
+// leaseCommandArguments returns the arguments of a lease command's output,
+// either the whole response ([{"result": 0, "arguments": {...}}]) or just
+// the arguments. nil means the response had none.
+func leaseCommandArguments(command string, r io.Reader) (json.RawMessage, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []KeaResponse
+	if json.Unmarshal(data, &responses) != nil {
+		return data, nil
+	}
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("empty %s response", command)
+	}
+	response := responses[0]
+	if response.Result != KeaResultSuccess && response.Result != KeaResultEmpty {
+		return nil, &KeaError{HMSError: keaSentinelForResult(response.Result).NewChild(""),
+			Command: command, Result: response.Result, Text: response.Text}
+	}
+	return response.Arguments, nil
+}
+
+// ParseKeaLeases4 reads the leases in lease4-get-all output, either the
+// whole response ([{"result": 0, "arguments": {"leases": [...]}}]) or just
+// its arguments ({"leases": [...]}).
+func ParseKeaLeases4(r io.Reader) ([]KeaLease4, error) {
+	data, err := leaseCommandArguments("lease4-get-all", r)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	var leases keaLeases4
+	if err = json.Unmarshal(data, &leases); err != nil {
+		return nil, fmt.Errorf("malformed lease4-get-all output: %w", err)
+	}
+	return leases.Leases, nil
+}
+
+// ParseKeaLeases6 is ParseKeaLeases4 for lease6-get-all output.
+func ParseKeaLeases6(r io.Reader) ([]KeaLease6, error) {
+	data, err := leaseCommandArguments("lease6-get-all", r)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	var leases keaLeases6
+	if err = json.Unmarshal(data, &leases); err != nil {
+		return nil, fmt.Errorf("malformed lease6-get-all output: %w", err)
+	}
+	return leases.Leases, nil
+}
+
+// memfileRow gives access to the fields of a memfile row by column name.
+// The first bad number sticks in err.
+type memfileRow struct {
+	line    int
+	columns map[string]int
+	record  []string
+	err     error
+}
+
+func (row *memfileRow) field(name string) string {
+	if ix, ok := row.columns[name]; ok && ix < len(row.record) {
+		return strings.TrimSpace(row.record[ix])
+	}
+	return ""
+}
+
+func (row *memfileRow) number(name string) int64 {
+	if row.err != nil || row.field(name) == "" {
+		return 0
+	}
+	val, err := strconv.ParseInt(row.field(name), 10, 64)
+	if err != nil {
+		row.err = fmt.Errorf("lease file line %d: bad %s '%s'", row.line, name, row.field(name))
+	}
+	return val
+}
+
+// hostname returns the hostname field, which Kea writes with commas
+// escaped.
+func (row *memfileRow) hostname() string {
+	return strings.ReplaceAll(row.field("hostname"), "&#x2c", ",")
+}
+
+// readMemfile reads a Kea memfile lease file, calling parse for every row.
+// The file is a log, so a later row for an address replaces earlier ones,
+// and a row with a zero valid lifetime deletes the lease. parse returns the
+// row's address and whether it is such a deletion.
+func readMemfile(r io.Reader, required []string, parse func(row *memfileRow) (string, bool)) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("lease file has no header: %w", err)
+	}
+	columns := make(map[string]int)
+	for ix, name := range header {
+		columns[strings.TrimSpace(name)] = ix
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("lease file has no '%s' column", name)
+		}
+	}
+
+	live := make(map[string]bool)
+	var order []string
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("lease file line %d: %w", line, err)
+		}
+		row := &memfileRow{line: line, columns: columns, record: record}
+		address, deleted := parse(row)
+		if row.err != nil {
+			return nil, row.err
+		}
+		if _, seen := live[address]; !seen {
+			order = append(order, address)
+		}
+		live[address] = !deleted
+	}
+
+	var addresses []string
+	for _, address := range order {
+		if live[address] {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// ReadKeaMemfile4 reads a Kea DHCPv4 memfile lease file, see readMemfile().
+// Leases are returned in IP address order.
+func ReadKeaMemfile4(r io.Reader) ([]KeaLease4, error) {
+	byAddress := make(map[string]KeaLease4)
+	addresses, err := readMemfile(r, []string{"address", "hwaddr", "valid_lifetime", "expire", "subnet_id"},
+		func(row *memfileRow) (string, bool) {
+			lease := KeaLease4{
+				IPAddress:     row.field("address"),
+				HWAddress:     row.field("hwaddr"),
+				ClientID:      row.field("client_id"),
+				ValidLifetime: row.number("valid_lifetime"),
+				SubnetID:      int(row.number("subnet_id")),
+				FQDNFwd:       row.field("fqdn_fwd") == "1",
+				FQDNRev:       row.field("fqdn_rev") == "1",
+				Hostname:      row.hostname(),
+				State:         int(row.number("state")),
+			}
+			lease.CLTT = row.number("expire") - lease.ValidLifetime
+			byAddress[lease.IPAddress] = lease
+			return lease.IPAddress, lease.ValidLifetime == 0
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]KeaLease4, 0, len(addresses))
+	for _, address := range addresses {
+		leases = append(leases, byAddress[address])
+	}
+	sortLeases(leases, func(lease KeaLease4) string { return lease.IPAddress })
+	return leases, nil
+}
+
+// Memfile lease_type values.
+var memfileLeaseTypes = map[int64]string{0: KeaLeaseIANA, 1: KeaLeaseIATA, 2: KeaLeaseIAPD}
+
+// ReadKeaMemfile6 is ReadKeaMemfile4 for a DHCPv6 memfile lease file.
+func ReadKeaMemfile6(r io.Reader) ([]KeaLease6, error) {
+	byAddress := make(map[string]KeaLease6)
+	addresses, err := readMemfile(r, []string{"address", "duid", "valid_lifetime", "expire", "subnet_id",
+		"lease_type"},
+		func(row *memfileRow) (string, bool) {
+			lease := KeaLease6{
+				IPAddress:         row.field("address"),
+				DUID:              row.field("duid"),
+				IAID:              uint32(row.number("iaid")),
+				HWAddress:         row.field("hwaddr"),
+				Type:              memfileLeaseTypes[row.number("lease_type")],
+				PrefixLen:         int(row.number("prefix_len")),
+				ValidLifetime:     row.number("valid_lifetime"),
+				PreferredLifetime: row.number("pref_lifetime"),
+				SubnetID:          int(row.number("subnet_id")),
+				FQDNFwd:           row.field("fqdn_fwd") == "1",
+				FQDNRev:           row.field("fqdn_rev") == "1",
+				Hostname:          row.hostname(),
+				State:             int(row.number("state")),
+			}
+			lease.CLTT = row.number("expire") - lease.ValidLifetime
+			byAddress[lease.IPAddress] = lease
+			return lease.IPAddress, lease.ValidLifetime == 0
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]KeaLease6, 0, len(addresses))
+	for _, address := range addresses {
+		leases = append(leases, byAddress[address])
+	}
+	sortLeases(leases, func(lease KeaLease6) string { return lease.IPAddress })
+	return leases, nil
+}
+
+// sortLeases sorts leases by IP address.
+func sortLeases[L any](leases []L, address func(L) string) {
+	sort.SliceStable(leases, func(i, j int) bool {
+		addrI, errI := netip.ParseAddr(address(leases[i]))
+		addrJ, errJ := netip.ParseAddr(address(leases[j]))
+		if errI != nil || errJ != nil {
+			return address(leases[i]) < address(leases[j])
+		}
+		return addrI.Less(addrJ)
+	})
+}
+
+// LeaseIngester maps leases to interfaces.
+type LeaseIngester struct {
+	// Optional. Gives leases the Network of their subnet, found by subnet
+	// ID or else by address.
+	Subnets *SubnetTable
+
+	// Clock used to skip expired leases, time.Now if nil.
+	Now func() time.Time
+}
+
+// IngestResult is the outcome of ingesting one interface.
+type IngestResult struct {
+	ID     string `json:"ID"`
+	Action string `json:"Action"`
+	Err    error  `json:"-"`
+}
+
+// network returns the Network of a lease's address.
+func (ingester *LeaseIngester) network(subnetID int, addr netip.Addr) string {
+	if ingester.Subnets == nil {
+		return ""
+	}
+	for _, subnet := range ingester.Subnets.Subnets() {
+		if subnet.ID == subnetID && subnet.prefix.Contains(addr) {
+			return subnet.Network
+		}
+	}
+	if subnet, ok := ingester.Subnets.Lookup(addr); ok {
+		return subnet.Network
+	}
+	return ""
+}
+
+// leaseCompID returns the xname a lease's hostname names, if any.
+func leaseCompID(hostname string) string {
+	label := strings.SplitN(hostname, ".", 2)[0]
+	if label == "" || !xnametypes.IsHMSCompIDValid(label) {
+		return ""
+	}
+	return xnametypes.NormalizeHMSCompID(label)
+}
+
+// lease is what ingestion needs of a DHCPv4 or DHCPv6 lease.
+type lease struct {
+	hwAddr        string
+	ipAddr        string
+	subnetID      int
+	hostname      string
+	state         int
+	validLifetime int64
+	expires       time.Time
+}
+
+// Interfaces maps active leases to interfaces, one per MAC address, sorted
+// by ID. Leases that expired, aren't active, or don't have a usable MAC or
+// IP address are returned in skipped.
+func (ingester *LeaseIngester) Interfaces(leases []KeaLease4) (ethInterfaces []sm.CompEthInterfaceV2,
+	skipped []Unplaced) {
+	var common []lease
+	for _, lease4 := range leases {
+		common = append(common, lease{
+			hwAddr:        lease4.HWAddress,
+			ipAddr:        lease4.IPAddress,
+			subnetID:      lease4.SubnetID,
+			hostname:      lease4.Hostname,
+			state:         lease4.State,
+			validLifetime: lease4.ValidLifetime,
+			expires:       lease4.Expires(),
+		})
+	}
+	return ingester.interfaces(common, nil)
+}
+
+// Interfaces6 is Interfaces for DHCPv6 leases. A lease's MAC address is
+// its HWAddress if the server learned it, or else taken from a DUID-LLT or
+// DUID-LL. Prefix delegations aren't addresses and are skipped.
+func (ingester *LeaseIngester) Interfaces6(leases []KeaLease6) (ethInterfaces []sm.CompEthInterfaceV2,
+	skipped []Unplaced) {
+	var common []lease
+	for _, lease6 := range leases {
+		if lease6.Type == KeaLeaseIAPD {
+			skipped = append(skipped, Unplaced{MACAddr: lease6.HWAddress, IPAddr: lease6.IPAddress,
+				Reason: UnplacedPrefixDelegation, Detail: fmt.Sprintf("%s/%d is a delegated prefix",
+					lease6.IPAddress, lease6.PrefixLen)})
+			continue
+		}
+		hwAddr := lease6.HWAddress
+		if hwAddr == "" {
+			duidMAC, err := mac.FromDUID(lease6.DUID)
+			if err != nil {
+				skipped = append(skipped, Unplaced{IPAddr: lease6.IPAddress, Reason: UnplacedInvalidMAC,
+					Detail: fmt.Sprintf("no MAC address in DUID '%s': %v", lease6.DUID, err)})
+				continue
+			}
+			hwAddr = duidMAC.String()
+		}
+		common = append(common, lease{
+			hwAddr:        hwAddr,
+			ipAddr:        lease6.IPAddress,
+			subnetID:      lease6.SubnetID,
+			hostname:      lease6.Hostname,
+			state:         lease6.State,
+			validLifetime: lease6.ValidLifetime,
+			expires:       lease6.Expires(),
+		})
+	}
+	return ingester.interfaces(common, skipped)
+}
+
+func (ingester *LeaseIngester) interfaces(leases []lease, skipped []Unplaced) (
+	ethInterfaces []sm.CompEthInterfaceV2, _ []Unplaced) {
+	now := time.Now
+	if ingester.Now != nil {
+		now = ingester.Now
+	}
+	sorted := append([]lease(nil), leases...)
+	sortLeases(sorted, func(cur lease) string { return cur.ipAddr })
+
+	byID := make(map[string]*sm.CompEthInterfaceV2)
+	for _, cur := range sorted {
+		skip := func(reason string, detail string) {
+			_, id, _ := mac.Normalize(cur.hwAddr)
+			skipped = append(skipped, Unplaced{ID: id, MACAddr: cur.hwAddr, IPAddr: cur.ipAddr,
+				Reason: reason, Detail: detail})
+		}
+
+		hwAddr, err := mac.ParseUnicast(cur.hwAddr)
+		if err != nil {
+			skip(UnplacedInvalidMAC, fmt.Sprintf("MAC address '%s': %v", cur.hwAddr, err))
+			continue
+		}
+		ipAddr, err := NormalizeIPAddr(cur.ipAddr)
+		if err != nil {
+			skip(UnplacedInvalidIP, err.Error())
+			continue
+		}
+		addr := netip.MustParseAddr(ipAddr)
+		switch {
+		case cur.state != KeaLeaseDefault:
+			skip(UnplacedInactiveLease, fmt.Sprintf("lease of %s is in state %d", addr, cur.state))
+			continue
+		case cur.validLifetime > 0 && cur.expires.Before(now()):
+			skip(UnplacedExpiredLease, fmt.Sprintf("lease of %s expired at %s", addr,
+				cur.expires.UTC().Format(time.RFC3339)))
+			continue
+		}
+
+		ethInterface, ok := byID[hwAddr.HSMID()]
+		if !ok {
+			ethInterface = &sm.CompEthInterfaceV2{ID: hwAddr.HSMID(), MACAddr: hwAddr.String()}
+			byID[hwAddr.HSMID()] = ethInterface
+		}
+		if compID := leaseCompID(cur.hostname); compID != "" {
+			ethInterface.CompID = compID
+		}
+		ethInterface.IPAddrs = append(ethInterface.IPAddrs, sm.IPAddressMapping{
+			IPAddr:  ipAddr,
+			Network: ingester.network(cur.subnetID, addr),
+		})
+	}
+
+	for _, ethInterface := range byID {
+		ethInterfaces = append(ethInterfaces, *ethInterface)
+	}
+	sort.Slice(ethInterfaces, func(i, j int) bool {
+		return ethInterfaces[i].ID < ethInterfaces[j].ID
+	})
+	return ethInterfaces, skipped
+}
+
+// mergeLeaseInterface applies the lease data in leased to cur, the
+// interface in HSM. Leased addresses replace those of cur that aren't
+// leased themselves but are of the same IP version on the same network, or
+// in the same subnet, as one that is; cur's other addresses and fields are
+// kept. Several leases on one network thus don't replace each other, and
+// merging the same leases again changes nothing. changed is false if the
+// result is the same as cur.
+func (ingester *LeaseIngester) mergeLeaseInterface(cur sm.CompEthInterfaceV2, leased sm.CompEthInterfaceV2) (
+	merged sm.CompEthInterfaceV2, changed bool) {
+	merged = cur
+	if leased.CompID != "" && !strings.EqualFold(leased.CompID, cur.CompID) {
+		merged.CompID = leased.CompID
+	}
+
+	leasedAddrs := make(map[netip.Addr]sm.IPAddressMapping, len(leased.IPAddrs))
+	for _, ipm := range leased.IPAddrs {
+		addr, _ := netip.ParseAddr(ipm.IPAddr)
+		leasedAddrs[addr] = ipm
+	}
+	replaced := func(curAddr netip.Addr, curIPM sm.IPAddressMapping) bool {
+		for addr, ipm := range leasedAddrs {
+			if (ipm.Network != "" && strings.EqualFold(ipm.Network, curIPM.Network) && curAddr.Is4() == addr.Is4()) ||
+				ingester.sameSubnet(addr, curAddr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	merged.IPAddrs = nil
+	present := make(map[netip.Addr]bool, len(cur.IPAddrs))
+	for _, curIPM := range cur.IPAddrs {
+		curAddr, err := netip.ParseAddr(curIPM.IPAddr)
+		if err == nil {
+			if ipm, ok := leasedAddrs[curAddr]; ok {
+				// The same address; only a newly known network changes it.
+				if ipm.Network != "" && !strings.EqualFold(ipm.Network, curIPM.Network) {
+					curIPM.Network = ipm.Network
+				}
+				present[curAddr] = true
+			} else if replaced(curAddr, curIPM) {
+				continue
+			}
+		}
+		merged.IPAddrs = append(merged.IPAddrs, curIPM)
+	}
+	for _, ipm := range leased.IPAddrs {
+		if addr, _ := netip.ParseAddr(ipm.IPAddr); !present[addr] {
+			merged.IPAddrs = append(merged.IPAddrs, ipm)
+		}
+	}
+
+	changed = merged.CompID != cur.CompID || !slices.Equal(merged.IPAddrs, cur.IPAddrs)
+	return
+}
+
+func (ingester *LeaseIngester) sameSubnet(a netip.Addr, b netip.Addr) bool {
+	if ingester.Subnets == nil {
+		return false
+	}
+	subnetA, okA := ingester.Subnets.Lookup(a)
+	subnetB, okB := ingester.Subnets.Lookup(b)
+	return okA && okB && subnetA == subnetB
+}
+
+func (helper *DNSDHCPHelper) IngestLeases(ingester *LeaseIngester, leases []KeaLease4) (results []IngestResult,
+	skipped []Unplaced, err error) {
+	return helper.IngestLeasesCtx(context.Background(), ingester, leases)
+}
+
+// IngestLeasesCtx writes the interfaces of leases to HSM, see
+// LeaseIngester.Interfaces(). An interface is created if HSM doesn't have
+// it and updated, keeping its other addresses and fields, if the leases
+// change it; both go through AddNewEthernetInterfaceCtx() with conflict
+// patching. Interfaces the leases don't change aren't written. One failed
+// interface doesn't stop the others; its result carries the error. err is
+// only set if HSM's interfaces couldn't be read.
+func (helper *DNSDHCPHelper) IngestLeasesCtx(ctx context.Context, ingester *LeaseIngester, leases []KeaLease4) (
+	results []IngestResult, skipped []Unplaced, err error) {
+	if ingester == nil {
+		ingester = &LeaseIngester{}
+	}
+	leased, skipped := ingester.Interfaces(leases)
+	results, err = helper.ingestInterfaces(ctx, ingester, leased)
+	return
+}
+
+func (helper *DNSDHCPHelper) IngestLeases6(ingester *LeaseIngester, leases []KeaLease6) (results []IngestResult,
+	skipped []Unplaced, err error) {
+	return helper.IngestLeases6Ctx(context.Background(), ingester, leases)
+}
+
+// IngestLeases6Ctx is IngestLeasesCtx for DHCPv6 leases, see
+// LeaseIngester.Interfaces6().
+func (helper *DNSDHCPHelper) IngestLeases6Ctx(ctx context.Context, ingester *LeaseIngester, leases []KeaLease6) (
+	results []IngestResult, skipped []Unplaced, err error) {
+	if ingester == nil {
+		ingester = &LeaseIngester{}
+	}
+	leased, skipped := ingester.Interfaces6(leases)
+	results, err = helper.ingestInterfaces(ctx, ingester, leased)
+	return
+}
+
+// ingestInterfaces upserts the interfaces made from leases.
+func (helper *DNSDHCPHelper) ingestInterfaces(ctx context.Context, ingester *LeaseIngester,
+	leased []sm.CompEthInterfaceV2) (results []IngestResult, err error) {
+	if len(leased) == 0 {
+		return
+	}
+
+	existing, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	byID := make(map[string]sm.CompEthInterfaceV2, len(existing))
+	for _, ethInterface := range existing {
+		byID[ethInterface.ID] = ethInterface
+	}
+
+	for _, ethInterface := range leased {
+		result := IngestResult{ID: ethInterface.ID, Action: IngestCreated}
+		if ethInterface.IPAddrs, result.Err = helper.attributeNetworks(ethInterface.IPAddrs); result.Err == nil {
+			if cur, ok := byID[ethInterface.ID]; ok {
+				var changed bool
+				ethInterface, changed = ingester.mergeLeaseInterface(cur, ethInterface)
+				result.Action = IngestUpdated
+				if !changed {
+					result.Action = IngestUnchanged
+				}
+			}
+			if result.Action != IngestUnchanged {
+				result.Err = helper.AddNewEthernetInterfaceCtx(ctx, ethInterface, true)
+			}
+		}
+		if result.Err != nil {
+			result.Action = IngestFailed
+		}
+		results = append(results, result)
+	}
+	return
+}
+
+func (helper *DNSDHCPHelper) IngestKeaLeases4(kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) (
+	results []IngestResult, skipped []Unplaced, err error) {
+	return helper.IngestKeaLeases4Ctx(context.Background(), kea, ingester, subnetIDs...)
+}
+
+// IngestKeaLeases4Ctx fetches the leases of the given subnets (all if none
+// are given) from Kea and ingests them, see IngestLeasesCtx().
+func (helper *DNSDHCPHelper) IngestKeaLeases4Ctx(ctx context.Context, kea *KeaClient, ingester *LeaseIngester,
+	subnetIDs ...int) (results []IngestResult, skipped []Unplaced, err error) {
+	leases, err := kea.Lease4GetAllCtx(ctx, subnetIDs...)
+	if err != nil {
+		return
+	}
+	return helper.IngestLeasesCtx(ctx, ingester, leases)
+}
+
+func (helper *DNSDHCPHelper) IngestKeaLeases6(kea *KeaClient, ingester *LeaseIngester, subnetIDs ...int) (
+	results []IngestResult, skipped []Unplaced, err error) {
+	return helper.IngestKeaLeases6Ctx(context.Background(), kea, ingester, subnetIDs...)
+}
+
+// IngestKeaLeases6Ctx is IngestKeaLeases4Ctx for DHCPv6 leases.
+func (helper *DNSDHCPHelper) IngestKeaLeases6Ctx(ctx context.Context, kea *KeaClient, ingester *LeaseIngester,
+	subnetIDs ...int) (results []IngestResult, skipped []Unplaced, err error) {
+	leases, err := kea.Lease6GetAllCtx(ctx, subnetIDs...)
+	if err != nil {
+		return
+	}
+	return helper.IngestLeases6Ctx(ctx, ingester, leases)
+}
@@ -0,0 +1,260 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestParseKeaLeases4(t *testing.T) {
+	tests := []struct {
+		input  string
+		leases int
+		isErr  bool
+	}{
+		{`[{"result":0,"text":"1 IPv4 lease(s) found.","arguments":{"leases":[{"ip-address":"10.252.1.5",
+			"hw-address":"a4:bf:01:2e:7f:b1","subnet-id":10,"valid-lft":3600,"cltt":1760000000}]}}]`, 1, false},
+		{`{"leases":[{"ip-address":"10.252.1.5"},{"ip-address":"10.252.1.6"}]}`, 2, false},
+		{`[{"result":3,"text":"0 IPv4 lease(s) found.","arguments":{"leases":[]}}]`, 0, false},
+		{`[{"result":3,"text":"0 IPv4 lease(s) found."}]`, 0, false},
+		{`[{"result":2,"text":"'lease4-get-all' command not supported."}]`, 0, true},
+		{`{"leases":`, 0, true},
+	}
+
+	for ix, test := range tests {
+		leases, err := ParseKeaLeases4(strings.NewReader(test.input))
+		if ((err != nil) != test.isErr || len(leases) != test.leases) {
+			t.Errorf("ERROR, test %d: got %v, error: %v", ix, leases, err)
+		}
+	}
+	if _, err := ParseKeaLeases4(strings.NewReader(tests[4].input)); (!errors.Is(err, ErrKeaUnsupported)) {
+		t.Errorf("ERROR, expected ErrKeaUnsupported, got %v", err)
+	}
+}
+
+func TestReadKeaMemfile4(t *testing.T) {
+	memfile := `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context,pool_id
+10.252.1.6,a4:bf:01:2e:7f:b2,,3600,1760003600,10,0,0,,0,,0
+10.252.1.5,a4:bf:01:2e:7f:b1,01:a4:bf:01:2e:7f:b1,3600,1760003600,10,1,1,x3000c0s1b0n0&#x2c,0,,0
+10.252.1.7,a4:bf:01:2e:7f:b3,,3600,1760003600,10,0,0,,0,,0
+10.252.1.5,a4:bf:01:2e:7f:b1,01:a4:bf:01:2e:7f:b1,3600,1760007200,10,1,1,x3000c0s1b0n0,0,,0
+10.252.1.7,a4:bf:01:2e:7f:b3,,0,1760003600,10,0,0,,0,,0
+`
+	leases, err := ReadKeaMemfile4(strings.NewReader(memfile))
+	if (err != nil || len(leases) != 2) {
+		t.Fatalf("ERROR, ReadKeaMemfile4() returned %v, error: %v", leases, err)
+	}
+	lease := leases[0]
+	if (lease.IPAddress != "10.252.1.5" || lease.CLTT != 1760003600 || lease.ValidLifetime != 3600 ||
+		lease.SubnetID != 10 || !lease.FQDNFwd || lease.Hostname != "x3000c0s1b0n0" ||
+		lease.ClientID != "01:a4:bf:01:2e:7f:b1") {
+		t.Errorf("ERROR, unexpected lease %v", lease)
+	}
+	if (leases[1].IPAddress != "10.252.1.6") {
+		t.Errorf("ERROR, unexpected lease %v", leases[1])
+	}
+
+	for _, bad := range []string{"", "address,hwaddr\n", memfile + "10.252.1.8,a4:bf:01:2e:7f:b4,,soon,0,10,0,0,,0,,0\n"} {
+		if _, err = ReadKeaMemfile4(strings.NewReader(bad)); (err == nil) {
+			t.Errorf("ERROR, expected an error for '%s'", bad)
+		}
+	}
+}
+
+func TestLeaseIngesterInterfaces(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{{ID: 10, CIDR: "10.252.0.0/17", Network: "NMN"},
+		{ID: 20, CIDR: "10.254.0.0/17", Network: "HMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	ingester := &LeaseIngester{Subnets: table,
+		Now: func() time.Time { return time.Unix(1760001000, 0) }}
+
+	leases := []KeaLease4{
+		{IPAddress: "10.254.1.5", HWAddress: "A4-BF-01-2E-7F-B1", SubnetID: 20, CLTT: 1760000000, ValidLifetime: 3600},
+		{IPAddress: "10.252.1.5", HWAddress: "a4:bf:01:2e:7f:b1", SubnetID: 10, CLTT: 1760000000, ValidLifetime: 3600,
+			Hostname: "x3000c0s01b0n0.nmn.example.com"},
+		{IPAddress: "10.252.1.6", HWAddress: "a4:bf:01:2e:7f:b2", SubnetID: 99, CLTT: 1760000000, ValidLifetime: 3600,
+			Hostname: "ncn-w001"},
+		{IPAddress: "10.252.1.7", HWAddress: "a4:bf:01:2e:7f:b3", SubnetID: 10, CLTT: 1750000000, ValidLifetime: 3600},
+		{IPAddress: "10.252.1.8", HWAddress: "a4:bf:01:2e:7f:b4", SubnetID: 10, State: KeaLeaseDeclined},
+		{IPAddress: "10.252.1.9", HWAddress: "ff:ff:ff:ff:ff:ff", SubnetID: 10},
+	}
+	ethInterfaces, skipped := ingester.Interfaces(leases)
+	if (len(ethInterfaces) != 2) {
+		t.Fatalf("ERROR, expected 2 interfaces, got %v", ethInterfaces)
+	}
+	first := ethInterfaces[0]
+	if (first.ID != "a4bf012e7fb1" || first.MACAddr != "a4:bf:01:2e:7f:b1" || first.CompID != "x3000c0s1b0n0" ||
+		len(first.IPAddrs) != 2 || first.IPAddrs[0] != (sm.IPAddressMapping{IPAddr: "10.252.1.5", Network: "NMN"}) ||
+		first.IPAddrs[1] != (sm.IPAddressMapping{IPAddr: "10.254.1.5", Network: "HMN"})) {
+		t.Errorf("ERROR, unexpected interface %v", first)
+	}
+	// An unknown subnet ID falls back to the address.
+	if (ethInterfaces[1].CompID != "" || ethInterfaces[1].IPAddrs[0].Network != "NMN") {
+		t.Errorf("ERROR, unexpected interface %v", ethInterfaces[1])
+	}
+
+	reasons := []string{UnplacedExpiredLease, UnplacedInactiveLease, UnplacedInvalidMAC}
+	if (len(skipped) != len(reasons)) {
+		t.Fatalf("ERROR, expected %d skipped, got %v", len(reasons), skipped)
+	}
+	for ix, reason := range reasons {
+		if (skipped[ix].Reason != reason) {
+			t.Errorf("ERROR, skipped %d: expected %s, got %v", ix, reason, skipped[ix])
+		}
+	}
+	if (skipped[0].ID != "a4bf012e7fb3") {
+		t.Errorf("ERROR, unexpected skipped ID %s", skipped[0].ID)
+	}
+}
+
+func TestMergeLeaseInterface(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{{ID: 10, CIDR: "10.252.0.0/17"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	ingester := &LeaseIngester{Subnets: table}
+	cur := sm.CompEthInterfaceV2{ID: "a4bf012e7fb1", Desc: "keep me", CompID: "x3000c0s1b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5"}, {IPAddr: "10.254.1.5", Network: "HMN"},
+			{IPAddr: "10.1.1.1"}}}
+
+	tests := []struct {
+		leased   []sm.IPAddressMapping
+		compID   string
+		changed  bool
+		expected []sm.IPAddressMapping
+	}{
+		// Already there.
+		{[]sm.IPAddressMapping{{IPAddr: "10.252.1.5"}}, "", false, cur.IPAddrs},
+		{[]sm.IPAddressMapping{{IPAddr: "10.254.1.5"}}, "x3000c0s1b0n0", false, cur.IPAddrs},
+		// A network becomes known.
+		{[]sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}}, "", true,
+			[]sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}, {IPAddr: "10.254.1.5", Network: "HMN"},
+				{IPAddr: "10.1.1.1"}}},
+		// A new address replaces the old one in the same subnet or network.
+		{[]sm.IPAddressMapping{{IPAddr: "10.252.1.9"}, {IPAddr: "10.254.1.9", Network: "HMN"}}, "", true,
+			[]sm.IPAddressMapping{{IPAddr: "10.1.1.1"}, {IPAddr: "10.252.1.9"}, {IPAddr: "10.254.1.9", Network: "HMN"}}},
+		// Leases of several addresses in one subnet all stay, and replace
+		// the address that isn't leased.
+		{[]sm.IPAddressMapping{{IPAddr: "10.252.1.9"}, {IPAddr: "10.252.1.10"}}, "", true,
+			[]sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "HMN"}, {IPAddr: "10.1.1.1"},
+				{IPAddr: "10.252.1.9"}, {IPAddr: "10.252.1.10"}}},
+		{[]sm.IPAddressMapping{{IPAddr: "10.252.1.5"}, {IPAddr: "10.252.1.10"}}, "", true,
+			[]sm.IPAddressMapping{{IPAddr: "10.252.1.5"}, {IPAddr: "10.254.1.5", Network: "HMN"},
+				{IPAddr: "10.1.1.1"}, {IPAddr: "10.252.1.10"}}},
+		// A new component.
+		{nil, "x3000c0s2b0n0", true, cur.IPAddrs},
+	}
+
+	for ix, test := range tests {
+		merged, changed := ingester.mergeLeaseInterface(cur, sm.CompEthInterfaceV2{CompID: test.compID,
+			IPAddrs: test.leased})
+		if (changed != test.changed || merged.Desc != "keep me" || len(merged.IPAddrs) != len(test.expected)) {
+			t.Errorf("ERROR, test %d: got %v, changed %t", ix, merged, changed)
+			continue
+		}
+		for jx := range test.expected {
+			if (merged.IPAddrs[jx] != test.expected[jx]) {
+				t.Errorf("ERROR, test %d: expected %v, got %v", ix, test.expected, merged.IPAddrs)
+				break
+			}
+		}
+	}
+	// Merging the result again changes nothing.
+	leased := sm.CompEthInterfaceV2{IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.9"}, {IPAddr: "10.252.1.10"}}}
+	merged, _ := ingester.mergeLeaseInterface(cur, leased)
+	if merged, changed := ingester.mergeLeaseInterface(merged, leased); (changed || len(merged.IPAddrs) != 4) {
+		t.Errorf("ERROR, merging the same leases again changed %v", merged)
+	}
+
+	if (len(cur.IPAddrs) != 3 || cur.IPAddrs[0].Network != "") {
+		t.Errorf("ERROR, merge changed its input: %v", cur)
+	}
+}
+
+func TestParseKeaLeases6(t *testing.T) {
+	leases, err := ParseKeaLeases6(strings.NewReader(`[{"result":0,"arguments":{"leases":[{"ip-address":"fd00::5",
+		"duid":"00:03:00:01:a4:bf:01:2e:7f:b1","iaid":1,"type":"IA_NA","prefix-len":128,"subnet-id":60,
+		"valid-lft":3600,"preferred-lft":1800,"cltt":1760000000}]}}]`))
+	if (err != nil || len(leases) != 1 || leases[0].DUID != "00:03:00:01:a4:bf:01:2e:7f:b1" ||
+		leases[0].Type != KeaLeaseIANA || leases[0].PreferredLifetime != 1800) {
+		t.Errorf("ERROR, ParseKeaLeases6() returned %v, error: %v", leases, err)
+	}
+	if _, err = ParseKeaLeases6(strings.NewReader(`[{"result":1,"text":"boom"}]`)); (!errors.Is(err, ErrKeaError)) {
+		t.Errorf("ERROR, expected ErrKeaError, got %v", err)
+	}
+}
+
+func TestReadKeaMemfile6(t *testing.T) {
+	memfile := `address,duid,valid_lifetime,expire,subnet_id,pref_lifetime,lease_type,iaid,prefix_len,fqdn_fwd,fqdn_rev,hostname,hwaddr,state,user_context,hwtype,hwaddr_source,pool_id
+fd00::5,00:03:00:01:a4:bf:01:2e:7f:b1,3600,1760003600,60,1800,0,1,128,0,0,x3000c0s1b0n0,,0,,,,0
+fd00:1::,00:03:00:01:a4:bf:01:2e:7f:b1,3600,1760003600,60,1800,2,2,56,0,0,,,0,,,,0
+fd00::6,00:01:00:01:2b:3c:4d:5e:a4:bf:01:2e:7f:b2,3600,1760003600,60,1800,0,1,128,0,0,,a4:bf:01:2e:7f:b2,0,,1,2,0
+fd00::6,00:01:00:01:2b:3c:4d:5e:a4:bf:01:2e:7f:b2,0,1760003600,60,1800,0,1,128,0,0,,a4:bf:01:2e:7f:b2,0,,1,2,0
+fd00::6,00:01:00:01:2b:3c:4d:5e:a4:bf:01:2e:7f:b2,3600,1760007200,60,1800,0,1,128,0,0,,a4:bf:01:2e:7f:b2,0,,1,2,0
+`
+	leases, err := ReadKeaMemfile6(strings.NewReader(memfile))
+	if (err != nil || len(leases) != 3) {
+		t.Fatalf("ERROR, ReadKeaMemfile6() returned %v, error: %v", leases, err)
+	}
+	if (leases[0].IPAddress != "fd00::5" || leases[0].Type != KeaLeaseIANA || leases[0].IAID != 1 ||
+		leases[0].CLTT != 1760000000 || leases[0].Hostname != "x3000c0s1b0n0") {
+		t.Errorf("ERROR, unexpected lease %v", leases[0])
+	}
+	if (leases[1].IPAddress != "fd00::6" || leases[1].HWAddress != "a4:bf:01:2e:7f:b2" || leases[1].CLTT != 1760003600) {
+		t.Errorf("ERROR, unexpected lease %v", leases[1])
+	}
+	if (leases[2].IPAddress != "fd00:1::" || leases[2].Type != KeaLeaseIAPD || leases[2].PrefixLen != 56) {
+		t.Errorf("ERROR, unexpected lease %v", leases[2])
+	}
+}
+
+func TestLeaseIngesterInterfaces6(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{{ID: 60, CIDR: "fd00::/64", Network: "NMN"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+	ingester := &LeaseIngester{Subnets: table}
+
+	leases := []KeaLease6{
+		{IPAddress: "FD00::5", DUID: "00:03:00:01:a4:bf:01:2e:7f:b1", Type: KeaLeaseIANA, SubnetID: 60,
+			Hostname: "x3000c0s1b0n0"},
+		{IPAddress: "fd00::6", DUID: "00:02:00:00:00:0b:01:02:03", HWAddress: "a4:bf:01:2e:7f:b2", SubnetID: 60},
+		{IPAddress: "fd00::7", DUID: "00:02:00:00:00:0b:01:02:04", SubnetID: 60},
+		{IPAddress: "fd00:1::", DUID: "00:03:00:01:a4:bf:01:2e:7f:b1", Type: KeaLeaseIAPD, PrefixLen: 56},
+	}
+	ethInterfaces, skipped := ingester.Interfaces6(leases)
+	if (len(ethInterfaces) != 2 || ethInterfaces[0].ID != "a4bf012e7fb1" || ethInterfaces[0].CompID != "x3000c0s1b0n0" ||
+		ethInterfaces[0].IPAddrs[0] != (sm.IPAddressMapping{IPAddr: "fd00::5", Network: "NMN"}) ||
+		ethInterfaces[1].ID != "a4bf012e7fb2") {
+		t.Errorf("ERROR, unexpected interfaces %v", ethInterfaces)
+	}
+	if (len(skipped) != 2 || skipped[0].Reason != UnplacedInvalidMAC || skipped[1].Reason != UnplacedPrefixDelegation) {
+		t.Errorf("ERROR, unexpected skipped %v", skipped)
+	}
+}
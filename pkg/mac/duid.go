@@ -0,0 +1,106 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mac
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// DHCPv6 identifies clients by DUID (RFC 8415) rather than MAC address.
+// Two of the DUID types embed the client's link-layer address, which for
+// Ethernet is its MAC.
+
+// HMSError classes of the DUID errors, one per error.
+const (
+	DUIDErrClassBadFormat   = "duid-bad-format"
+	DUIDErrClassNoMAC       = "duid-no-mac"
+	DUIDErrClassNotEthernet = "duid-not-ethernet"
+)
+
+var ErrDUIDBadFormat = base.NewHMSError(DUIDErrClassBadFormat, "Invalid DUID format")
+var ErrDUIDNoMAC = base.NewHMSError(DUIDErrClassNoMAC, "DUID type doesn't carry a link-layer address")
+var ErrDUIDNotEthernet = base.NewHMSError(DUIDErrClassNotEthernet, "DUID link-layer address isn't Ethernet")
+
+// DUID types.
+const (
+	DUIDTypeLLT  = 1 // link-layer address plus time
+	DUIDTypeEN   = 2 // assigned by vendor based on enterprise number
+	DUIDTypeLL   = 3 // link-layer address
+	DUIDTypeUUID = 4
+)
+
+// hwTypeEthernet is the IANA hardware type of Ethernet.
+const hwTypeEthernet = 1
+
+// ParseDUID decodes a DUID written as hex, with or without colon or dash
+// separators, e.g. "00:03:00:01:a4:bf:01:2e:7f:b1" as Kea writes it.
+func ParseDUID(s string) ([]byte, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(":", "", "-", "").Replace(s)
+	duid, err := hex.DecodeString(s)
+	// A DUID is a 2 byte type and at least one byte of identifier, and at
+	// most 128 bytes of identifier.
+	if err != nil || len(duid) < 3 || len(duid) > 130 {
+		return nil, ErrDUIDBadFormat
+	}
+	return duid, nil
+}
+
+// FromDUID returns the MAC address in a DUID-LLT or DUID-LL of an
+// Ethernet interface.
+func FromDUID(s string) (MAC, error) {
+	var m MAC
+	duid, err := ParseDUID(s)
+	if err != nil {
+		return m, err
+	}
+
+	var lladdr []byte
+	switch binary.BigEndian.Uint16(duid) {
+	case DUIDTypeLLT:
+		if len(duid) < 8 {
+			return m, ErrDUIDBadFormat
+		}
+		lladdr = duid[8:]
+	case DUIDTypeLL:
+		if len(duid) < 4 {
+			return m, ErrDUIDBadFormat
+		}
+		lladdr = duid[4:]
+	default:
+		return m, ErrDUIDNoMAC
+	}
+	if binary.BigEndian.Uint16(duid[2:]) != hwTypeEthernet || len(lladdr) != len(m) {
+		return m, ErrDUIDNotEthernet
+	}
+	copy(m[:], lladdr)
+	return m, nil
+}
+
+// DUIDLL returns the DUID-LL of m, in Kea's notation.
+func (m MAC) DUIDLL() string {
+	return "00:03:00:01:" + m.String()
+}
@@ -0,0 +1,73 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mac
+
+import (
+	"errors"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+func TestFromDUID(t *testing.T) {
+	tests := []struct {
+		duid     string
+		expected string
+		err      error
+	}{
+		{"00:03:00:01:a4:bf:01:2e:7f:b1", "a4:bf:01:2e:7f:b1", nil},
+		{"00030001A4BF012E7FB1", "a4:bf:01:2e:7f:b1", nil},
+		{"00:01:00:01:2b:3c:4d:5e:a4:bf:01:2e:7f:b1", "a4:bf:01:2e:7f:b1", nil},
+		{"00-01-00-01-2b-3c-4d-5e-a4-bf-01-2e-7f-b1", "a4:bf:01:2e:7f:b1", nil},
+		{"00:02:00:00:00:0b:01:02:03", "", ErrDUIDNoMAC},
+		{"00:04:12:34:56:78:12:34:56:78:12:34:56:78:12:34:56:78", "", ErrDUIDNoMAC},
+		{"00:03:00:20:a4:bf:01:2e:7f:b1", "", ErrDUIDNotEthernet},
+		{"00:03:00:01:a4:bf:01:2e:7f", "", ErrDUIDNotEthernet},
+		{"00:01:00:01:2b", "", ErrDUIDBadFormat},
+		{"00:03", "", ErrDUIDBadFormat},
+		{"zz:03:00:01:a4:bf:01:2e:7f:b1", "", ErrDUIDBadFormat},
+	}
+
+	for ix, test := range tests {
+		m, err := FromDUID(test.duid)
+		if (test.err != nil) {
+			if (!errors.Is(err, test.err)) {
+				t.Errorf("ERROR, test %d: expected %v, got %v", ix, test.err, err)
+			}
+			continue
+		}
+		if (err != nil || m.String() != test.expected) {
+			t.Errorf("ERROR, test %d: expected %s, got %s, error: %v", ix, test.expected, m, err)
+		}
+	}
+
+	m, _ := Parse("a4:bf:01:2e:7f:b1")
+	if back, err := FromDUID(m.DUIDLL()); (err != nil || back != m) {
+		t.Errorf("ERROR, DUID-LL round trip failed: %s, %v", back, err)
+	}
+
+	if (!base.IsHMSErrorClass(ErrDUIDNoMAC, DUIDErrClassNoMAC) ||
+		base.IsHMSErrorClass(ErrDUIDNoMAC, DUIDErrClassBadFormat) ||
+		base.IsHMSErrorClass(ErrDUIDNotEthernet, DUIDErrClassNoMAC)) {
+		t.Errorf("ERROR, DUID errors share a class")
+	}
+}
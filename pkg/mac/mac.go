@@ -0,0 +1,171 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+// Package mac parses and validates Ethernet MAC addresses in the notations
+// found in the wild (colon, dash, Cisco dotted, HPE and bare hex, any case)
+// and renders them in the canonical forms HSM uses. It also extracts them
+// from DHCPv6 DUIDs.
+package mac
+
+import (
+	"encoding/hex"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// HMSError classes of the MAC errors, one per error.
+const (
+	MACErrClassBadFormat = "mac-bad-format"
+	MACErrClassMulticast = "mac-multicast"
+	MACErrClassBroadcast = "mac-broadcast"
+	MACErrClassZero      = "mac-zero"
+)
+
+var ErrMACBadFormat = base.NewHMSError(MACErrClassBadFormat, "Invalid MAC address format")
+var ErrMACMulticast = base.NewHMSError(MACErrClassMulticast, "MAC address is a multicast address")
+var ErrMACBroadcast = base.NewHMSError(MACErrClassBroadcast, "MAC address is the broadcast address")
+var ErrMACZero = base.NewHMSError(MACErrClassZero, "MAC address is all zeros")
+
+// MAC is a 48-bit (EUI-48) Ethernet MAC address.
+type MAC [6]byte
+
+// Parse reads a MAC address in any of these notations, in either case:
+//
+//	a4:bf:01:2e:7f:b1    colon separated (leading zeros optional)
+//	a4-bf-01-2e-7f-b1    dash separated (leading zeros optional)
+//	a4bf.012e.7fb1       Cisco dotted
+//	a4bf01-2e7fb1        HPE
+//	a4bf012e7fb1         bare hex, as in HSM IDs
+//
+// Parse only checks the format; use Validate() or ParseUnicast() to also
+// reject addresses that can't belong to a NIC.
+func Parse(s string) (MAC, error) {
+	var m MAC
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	var groups []string
+	switch {
+	case strings.Count(s, ":") == 5:
+		groups = strings.Split(s, ":")
+	case strings.Count(s, "-") == 5:
+		groups = strings.Split(s, "-")
+	case strings.Count(s, ".") == 2:
+		groups = strings.Split(s, ".")
+	case strings.Count(s, "-") == 1:
+		groups = strings.Split(s, "-")
+	default:
+		groups = []string{s}
+	}
+
+	// Every group must be the same width; for the six-group notations
+	// a single digit is padded (e.g. "a:bf:1:2e:7f:b1").
+	width := 12 / len(groups)
+	var digits strings.Builder
+	for _, group := range groups {
+		if len(groups) == 6 && len(group) == 1 {
+			group = "0" + group
+		}
+		if len(group) != width {
+			return m, ErrMACBadFormat
+		}
+		digits.WriteString(group)
+	}
+
+	raw, err := hex.DecodeString(digits.String())
+	if err != nil || len(raw) != len(m) {
+		return m, ErrMACBadFormat
+	}
+	copy(m[:], raw)
+	return m, nil
+}
+
+// ParseUnicast parses s and then validates it with Validate().
+func ParseUnicast(s string) (MAC, error) {
+	m, err := Parse(s)
+	if err != nil {
+		return m, err
+	}
+	return m, m.Validate()
+}
+
+// Validate rejects the all-zero, broadcast and multicast addresses, none of
+// which identify a single NIC. Locally-administered addresses are allowed;
+// check IsLocallyAdministered() to flag them.
+func (m MAC) Validate() error {
+	switch {
+	case m.IsZero():
+		return ErrMACZero
+	case m.IsBroadcast():
+		return ErrMACBroadcast
+	case m.IsMulticast():
+		return ErrMACMulticast
+	}
+	return nil
+}
+
+// String returns the canonical lower-case, colon separated form, as used in
+// HSM's MACAddress field.
+func (m MAC) String() string {
+	var sb strings.Builder
+	for ix, octet := range m {
+		if ix > 0 {
+			sb.WriteByte(':')
+		}
+		sb.WriteString(hex.EncodeToString([]byte{octet}))
+	}
+	return sb.String()
+}
+
+// HSMID returns the HSM EthernetInterface ID for the address, the lower-case
+// hex digits without separators.
+func (m MAC) HSMID() string {
+	return hex.EncodeToString(m[:])
+}
+
+func (m MAC) IsZero() bool {
+	return m == MAC{}
+}
+
+func (m MAC) IsBroadcast() bool {
+	return m == MAC{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+}
+
+// IsMulticast is true for group addresses, which includes broadcast.
+func (m MAC) IsMulticast() bool {
+	return m[0]&0x01 != 0
+}
+
+// IsLocallyAdministered is true for addresses that weren't assigned by the
+// NIC vendor, e.g. those made up for VMs or bonds.
+func (m MAC) IsLocallyAdministered() bool {
+	return m[0]&0x02 != 0
+}
+
+// Normalize parses s and returns its canonical form and HSM ID.
+func Normalize(s string) (canonical string, hsmID string, err error) {
+	m, err := Parse(s)
+	if err != nil {
+		return
+	}
+	return m.String(), m.HSMID(), nil
+}
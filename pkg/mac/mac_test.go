@@ -0,0 +1,129 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package mac
+
+import (
+	"errors"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+func TestParse(t *testing.T) {
+	good := []string{
+		"a4:bf:01:2e:7f:b1",
+		"A4:BF:01:2E:7F:B1",
+		"a4-bf-01-2e-7f-b1",
+		"a4bf.012e.7fb1",
+		"A4BF01-2E7FB1",
+		"a4bf012e7fb1",
+		" a4:bf:1:2e:7f:b1 ",
+	}
+	for _, s := range good {
+		m, err := Parse(s)
+		if (err != nil) {
+			t.Errorf("ERROR, Parse(%q) error: %v", s, err)
+			continue
+		}
+		if (m.String() != "a4:bf:01:2e:7f:b1") {
+			t.Errorf("ERROR, Parse(%q) canonical form '%s'", s, m.String())
+		}
+		if (m.HSMID() != "a4bf012e7fb1") {
+			t.Errorf("ERROR, Parse(%q) HSM ID '%s'", s, m.HSMID())
+		}
+	}
+
+	bad := []string{
+		"",
+		"a4:bf:01:2e:7f",
+		"a4:bf:01:2e:7f:b1:00",
+		"a4:bf:01-2e:7f:b1",
+		"a4:bf:001:2e:7f:b1",
+		"a4bf.012e.7fb",
+		"a4bf0-12e7fb1",
+		"a4bf012e7fbg",
+		"a4bf012e7fb1ff",
+		"x3000c0s1b0n0",
+	}
+	for _, s := range bad {
+		if _, err := Parse(s); (!errors.Is(err, ErrMACBadFormat)) {
+			t.Errorf("ERROR, Parse(%q) expected ErrMACBadFormat, got: %v", s, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		mac     string
+		wantErr error
+	}{
+		{"a4:bf:01:2e:7f:b1", nil},
+		{"02:00:00:00:00:01", nil},
+		{"00:00:00:00:00:00", ErrMACZero},
+		{"ff:ff:ff:ff:ff:ff", ErrMACBroadcast},
+		{"01:00:5e:00:00:fb", ErrMACMulticast},
+		{"33:33:00:00:00:01", ErrMACMulticast},
+		{"bogus", ErrMACBadFormat},
+	}
+	for _, tt := range tests {
+		_, err := ParseUnicast(tt.mac)
+		if (err != tt.wantErr) {
+			t.Errorf("ERROR, ParseUnicast(%q) expected %v, got: %v", tt.mac, tt.wantErr, err)
+		}
+	}
+
+	// Each error has a class of its own.
+	classes := map[*base.HMSError]string{ErrMACBadFormat: MACErrClassBadFormat, ErrMACMulticast: MACErrClassMulticast,
+		ErrMACBroadcast: MACErrClassBroadcast, ErrMACZero: MACErrClassZero}
+	for sentinel, class := range classes {
+		for other, otherClass := range classes {
+			if (base.IsHMSErrorClass(sentinel, otherClass) != (sentinel == other)) {
+				t.Errorf("ERROR, %v has class %s, class %s matches it", sentinel, class, otherClass)
+			}
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	m, _ := Parse("02:42:ac:11:00:02")
+	if (!m.IsLocallyAdministered() || m.IsMulticast()) {
+		t.Errorf("ERROR, %s should be locally administered unicast.", m)
+	}
+	m, _ = Parse("a4:bf:01:2e:7f:b1")
+	if (m.IsLocallyAdministered() || m.IsMulticast() || m.IsZero() || m.IsBroadcast()) {
+		t.Errorf("ERROR, %s should be globally administered unicast.", m)
+	}
+	m, _ = Parse("ff:ff:ff:ff:ff:ff")
+	if (!m.IsBroadcast() || !m.IsMulticast()) {
+		t.Errorf("ERROR, %s should be broadcast and multicast.", m)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	canonical, hsmID, err := Normalize("A4BF.012E.7FB1")
+	if (err != nil || canonical != "a4:bf:01:2e:7f:b1" || hsmID != "a4bf012e7fb1") {
+		t.Errorf("ERROR, Normalize() returned '%s', '%s', %v", canonical, hsmID, err)
+	}
+	if _, _, err = Normalize("a4:bf"); (err != ErrMACBadFormat) {
+		t.Errorf("ERROR, Normalize() expected ErrMACBadFormat, got: %v", err)
+	}
+}
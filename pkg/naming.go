@@ -0,0 +1,412 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// The naming engine turns an EthernetInterface into the DNS names of its IP
+// addresses, so every consumer of the helper names hosts the same way. By
+// default a host is named after the xname of the component that owns the
+// interface, in the domain of the network the address is on:
+//
+//	x3000c0s1b0n0.nmn   Node on the NMN
+//	x3000c0s1b0.hmn     NodeBMC on the HMN
+//
+// Given a ComponentIndex (see Namer.WithComponents()), nodes also get an
+// alias from their NID, e.g. nid000123.nmn, and templates can use their Role
+// and SubRole. Templates (see NameTemplate) cover the exceptions.
+
+// HMSError classes of the naming errors, one per error.
+const (
+	NamingErrClassNoComponent     = "naming-no-component"
+	NamingErrClassBadXname        = "naming-bad-xname"
+	NamingErrClassUnsupportedType = "naming-unsupported-type"
+	NamingErrClassNoDomain        = "naming-no-domain"
+	NamingErrClassBadName         = "naming-bad-name"
+)
+
+var ErrNamingNoComponent = base.NewHMSError(NamingErrClassNoComponent, "interface has no component to name it after")
+var ErrNamingBadXname = base.NewHMSError(NamingErrClassBadXname, "component ID is not a valid xname")
+var ErrNamingUnsupportedType = base.NewHMSError(NamingErrClassUnsupportedType, "no naming rule for component type")
+var ErrNamingNoDomain = base.NewHMSError(NamingErrClassNoDomain, "no DNS domain configured for network")
+var ErrNamingBadName = base.NewHMSError(NamingErrClassBadName, "not a valid DNS name")
+
+// namedTypes are the HMS types named after their own xname without a
+// template.
+var namedTypes = map[xnametypes.HMSType]bool{
+	xnametypes.Node:                 true,
+	xnametypes.VirtualNode:          true,
+	xnametypes.NodeBMC:              true,
+	xnametypes.RouterBMC:            true,
+	xnametypes.ChassisBMC:           true,
+	xnametypes.CabinetBMC:           true,
+	xnametypes.CEC:                  true,
+	xnametypes.CabinetPDUController: true,
+	xnametypes.MgmtSwitch:           true,
+	xnametypes.MgmtHLSwitch:         true,
+	xnametypes.CDUMgmtSwitch:        true,
+}
+
+// ownerTypes are the HMS types whose NIC belongs to, and is named after,
+// their parent: a NIC and the controller it sits on, or a PDU and its
+// controller.
+var ownerTypes = map[xnametypes.HMSType]bool{
+	xnametypes.NodeNic:       true,
+	xnametypes.NodeHsnNic:    true,
+	xnametypes.NodeBMCNic:    true,
+	xnametypes.RouterBMCNic:  true,
+	xnametypes.ChassisBMCNic: true,
+	xnametypes.CabinetPDUNic: true,
+	xnametypes.CabinetPDU:    true,
+}
+
+// DefaultNIDFormat is the fmt format of NID aliases when
+// NamingConfig.NIDFormat is empty.
+const DefaultNIDFormat = "nid%06d"
+
+var dnsLabelRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NameTemplate overrides the default names of matching interfaces. Host and
+// Aliases are text/template templates executed with a NameData; each must
+// produce one or more dot separated DNS labels, to which the network's
+// domain is appended.
+type NameTemplate struct {
+	// What the template applies to. Empty fields match anything; at least
+	// one should be set. Type is an HMS type, e.g. "CabinetPDUController".
+	// Role and SubRole only match nodes found in the ComponentIndex.
+	Xname   string
+	Type    string
+	Network string
+	Role    string
+	SubRole string
+
+	// Host replaces the host name; empty keeps the default one.
+	Host    string
+	Aliases []string
+}
+
+// NameData is what NameTemplate templates are executed with.
+type NameData struct {
+	Xname   string // The component the interface is named after.
+	CompID  string // The interface's ComponentID, normalized.
+	Type    string // The HMS type of Xname.
+	Parent  string // The parent xname of Xname.
+	Network string
+	Domain  string
+	MACAddr string
+	ID      string // The interface ID.
+
+	// From the ComponentIndex, for nodes only. RoleIndex is the node's
+	// 1-based position among those with the same Role and SubRole, so
+	// "uan{{printf \"%02d\" .RoleIndex}}" gives uan01, uan02...
+	NID       int
+	Role      string
+	SubRole   string
+	RoleIndex int
+}
+
+// NamingConfig configures a Namer.
+type NamingConfig struct {
+	// Domains maps network names (matched ignoring case) to the DNS domain
+	// names on that network are created in, e.g. "NMN": "nmn" or
+	// "HMN": "hmn.example.com".
+	Domains map[string]string
+
+	// DefaultNetwork is used for IP addresses that carry no Network, e.g.
+	// those from an HSM v1 API. Empty skips such addresses.
+	DefaultNetwork string
+
+	// PrimaryNetwork is the network whose names also get the bare host name
+	// as an alias, e.g. "x3000c0s1b0n0" for "x3000c0s1b0n0.nmn".
+	PrimaryNetwork string
+
+	// NIDFormat formats the NID alias of nodes, see DefaultNIDFormat. "-"
+	// turns NID aliases off.
+	NIDFormat string
+
+	// Templates are tried in order; the first that matches an interface is
+	// used.
+	Templates []NameTemplate
+}
+
+// HostName holds the DNS names of one IP address of an interface.
+type HostName struct {
+	IPAddr  string
+	Network string
+	FQDN    string
+	Aliases []string
+}
+
+type compiledTemplate struct {
+	NameTemplate
+	host    *template.Template
+	aliases []*template.Template
+}
+
+// Namer derives DNS names from EthernetInterfaces. It is safe for
+// concurrent use.
+type Namer struct {
+	domains        map[string]string
+	defaultNetwork string
+	primaryNetwork string
+	nidFormat      string
+	templates      []compiledTemplate
+	components     *ComponentIndex
+}
+
+// NewNamer creates a Namer, checking the config and compiling its templates.
+func NewNamer(config NamingConfig) (*Namer, error) {
+	namer := &Namer{
+		domains:        make(map[string]string),
+		defaultNetwork: config.DefaultNetwork,
+		primaryNetwork: config.PrimaryNetwork,
+		nidFormat:      config.NIDFormat,
+	}
+	switch namer.nidFormat {
+	case "":
+		namer.nidFormat = DefaultNIDFormat
+	case "-":
+		namer.nidFormat = ""
+	}
+	for network, domain := range config.Domains {
+		domain = strings.ToLower(strings.Trim(domain, "."))
+		if err := checkDNSName(domain); err != nil {
+			return nil, fmt.Errorf("domain for network %s: %w", network, err)
+		}
+		namer.domains[strings.ToUpper(network)] = domain
+	}
+
+	for ix, nameTemplate := range config.Templates {
+		compiled := compiledTemplate{NameTemplate: nameTemplate}
+		if nameTemplate.Xname != "" {
+			compiled.Xname = xnametypes.NormalizeHMSCompID(nameTemplate.Xname)
+		}
+		var err error
+		if nameTemplate.Host != "" {
+			compiled.host, err = template.New("host").Option("missingkey=error").Parse(nameTemplate.Host)
+			if err != nil {
+				return nil, fmt.Errorf("name template %d host: %w", ix, err)
+			}
+		}
+		for _, alias := range nameTemplate.Aliases {
+			aliasTemplate, err := template.New("alias").Option("missingkey=error").Parse(alias)
+			if err != nil {
+				return nil, fmt.Errorf("name template %d alias: %w", ix, err)
+			}
+			compiled.aliases = append(compiled.aliases, aliasTemplate)
+		}
+		namer.templates = append(namer.templates, compiled)
+	}
+	return namer, nil
+}
+
+// WithComponents returns a copy of the Namer that takes node NIDs, Roles and
+// SubRoles from index. Call it with a fresh index every sync cycle.
+func (namer *Namer) WithComponents(index *ComponentIndex) *Namer {
+	bound := *namer
+	bound.components = index
+	return &bound
+}
+
+// Domain returns the DNS domain of network, if one is configured.
+func (namer *Namer) Domain(network string) (domain string, ok bool) {
+	domain, ok = namer.domains[strings.ToUpper(network)]
+	return
+}
+
+// Name returns the canonical FQDN and the aliases of ethInterface on
+// network.
+func (namer *Namer) Name(ethInterface sm.CompEthInterfaceV2, network string) (fqdn string, aliases []string,
+	err error) {
+	domain, ok := namer.Domain(network)
+	if !ok {
+		err = fmt.Errorf("network '%s': %w", network, ErrNamingNoDomain)
+		return
+	}
+
+	data := NameData{
+		CompID:  xnametypes.NormalizeHMSCompID(ethInterface.CompID),
+		Network: network,
+		Domain:  domain,
+		MACAddr: ethInterface.MACAddr,
+		ID:      ethInterface.ID,
+	}
+	if data.CompID == "" {
+		err = fmt.Errorf("interface %s: %w", ethInterface.ID, ErrNamingNoComponent)
+		return
+	}
+
+	// NICs and PDUs are named after the controller they belong to.
+	data.Xname = data.CompID
+	hmsType := xnametypes.GetHMSType(data.Xname)
+	if ownerTypes[hmsType] {
+		data.Xname = xnametypes.GetHMSCompParent(data.Xname)
+		hmsType = xnametypes.GetHMSType(data.Xname)
+	}
+	if hmsType != xnametypes.HMSTypeInvalid {
+		data.Type = hmsType.String()
+		data.Parent = xnametypes.GetHMSCompParent(data.Xname)
+	}
+	if info, found := namer.components.Lookup(data.Xname); found {
+		data.NID = info.NID
+		data.Role = info.Role
+		data.SubRole = info.SubRole
+		data.RoleIndex = info.RoleIndex
+	}
+
+	nameTemplate := namer.match(data)
+	host := data.Xname
+	switch {
+	case nameTemplate != nil && nameTemplate.host != nil:
+		if host, err = executeName(nameTemplate.host, data); err != nil {
+			return
+		}
+	case hmsType == xnametypes.HMSTypeInvalid:
+		err = fmt.Errorf("interface %s component '%s': %w", ethInterface.ID, ethInterface.CompID,
+			ErrNamingBadXname)
+		return
+	case !namedTypes[hmsType]:
+		err = fmt.Errorf("interface %s component %s (%s): %w", ethInterface.ID, data.CompID, hmsType,
+			ErrNamingUnsupportedType)
+		return
+	}
+	fqdn = host + "." + domain
+
+	seen := map[string]bool{fqdn: true}
+	addAlias := func(alias string) {
+		if !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	primary := namer.primaryNetwork != "" && strings.EqualFold(network, namer.primaryNetwork)
+	if primary {
+		addAlias(host)
+	}
+	if data.NID > 0 && namer.nidFormat != "" {
+		nidName := strings.ToLower(fmt.Sprintf(namer.nidFormat, data.NID))
+		if err = checkDNSName(nidName); err != nil {
+			return
+		}
+		addAlias(nidName + "." + domain)
+		if primary {
+			addAlias(nidName)
+		}
+	}
+	if nameTemplate != nil {
+		for _, aliasTemplate := range nameTemplate.aliases {
+			alias, aliasErr := executeName(aliasTemplate, data)
+			if aliasErr != nil {
+				err = aliasErr
+				return
+			}
+			addAlias(alias + "." + domain)
+		}
+	}
+	return
+}
+
+// Names returns the DNS names of every IP address of ethInterface. Addresses
+// with no Network use the default network; addresses whose network has no
+// domain are skipped.
+func (namer *Namer) Names(ethInterface sm.CompEthInterfaceV2) (hostNames []HostName, err error) {
+	for _, ipm := range ethInterface.IPAddrs {
+		network := ipm.Network
+		if network == "" {
+			network = namer.defaultNetwork
+		}
+		if _, ok := namer.Domain(network); !ok || ipm.IPAddr == "" {
+			continue
+		}
+
+		fqdn, aliases, nameErr := namer.Name(ethInterface, network)
+		if nameErr != nil {
+			return nil, nameErr
+		}
+		hostNames = append(hostNames, HostName{
+			IPAddr:  ipm.IPAddr,
+			Network: network,
+			FQDN:    fqdn,
+			Aliases: aliases,
+		})
+	}
+	return
+}
+
+// match returns the first template that applies to data, or nil.
+func (namer *Namer) match(data NameData) *compiledTemplate {
+	for ix := range namer.templates {
+		nameTemplate := &namer.templates[ix]
+		if nameTemplate.Xname != "" && nameTemplate.Xname != data.Xname && nameTemplate.Xname != data.CompID {
+			continue
+		}
+		if nameTemplate.Type != "" && !strings.EqualFold(nameTemplate.Type, data.Type) {
+			continue
+		}
+		if nameTemplate.Network != "" && !strings.EqualFold(nameTemplate.Network, data.Network) {
+			continue
+		}
+		if nameTemplate.Role != "" && !strings.EqualFold(nameTemplate.Role, data.Role) {
+			continue
+		}
+		if nameTemplate.SubRole != "" && !strings.EqualFold(nameTemplate.SubRole, data.SubRole) {
+			continue
+		}
+		return nameTemplate
+	}
+	return nil
+}
+
+func executeName(nameTemplate *template.Template, data NameData) (string, error) {
+	var buf bytes.Buffer
+	if err := nameTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("name template: %w", err)
+	}
+	name := strings.ToLower(strings.TrimSpace(buf.String()))
+	if err := checkDNSName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// checkDNSName checks name is one or more dot separated DNS labels.
+func checkDNSName(name string) error {
+	if name == "" || len(name) > 253 {
+		return fmt.Errorf("'%s': %w", name, ErrNamingBadName)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !dnsLabelRE.MatchString(label) {
+			return fmt.Errorf("'%s': %w", name, ErrNamingBadName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func testNamer(t *testing.T) *Namer {
+	namer, err := NewNamer(NamingConfig{
+		Domains:        map[string]string{"NMN": "nmn", "hmn": "hmn.example.com."},
+		DefaultNetwork: "NMN",
+		PrimaryNetwork: "NMN",
+		Templates: []NameTemplate{
+			{Xname: "x3000c0s01b0n0", Host: "ncn-m001", Aliases: []string{"{{.Xname}}"}},
+			{Type: "CabinetPDUController", Aliases: []string{"pdu-{{.Xname}}"}},
+			{Type: "MgmtSwitch", Network: "HMN", Host: "sw-{{.Xname}}"},
+		},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNamer() error: %v", err)
+	}
+	return namer
+}
+
+func TestNamerName(t *testing.T) {
+	namer := testNamer(t)
+
+	tests := []struct {
+		compID  string
+		network string
+		fqdn    string
+		aliases []string
+	}{
+		{"x3000c0s2b0n0", "NMN", "x3000c0s2b0n0.nmn", []string{"x3000c0s2b0n0"}},
+		{"X3000C0S02B0N0", "nmn", "x3000c0s2b0n0.nmn", []string{"x3000c0s2b0n0"}},
+		{"x3000c0s2b0", "HMN", "x3000c0s2b0.hmn.example.com", nil},
+		{"x3000c0s2b0i0", "HMN", "x3000c0s2b0.hmn.example.com", nil},
+		{"x3000c0s1b0n0", "NMN", "ncn-m001.nmn", []string{"ncn-m001", "x3000c0s1b0n0.nmn"}},
+		{"x3000c0s1b0n0", "HMN", "ncn-m001.hmn.example.com", []string{"x3000c0s1b0n0.hmn.example.com"}},
+		{"x1000c0b0", "HMN", "x1000c0b0.hmn.example.com", nil},
+		{"x1000c0r7b0", "HMN", "x1000c0r7b0.hmn.example.com", nil},
+		{"x3000m0p0", "HMN", "x3000m0.hmn.example.com", []string{"pdu-x3000m0.hmn.example.com"}},
+		{"x3000c0w22", "HMN", "sw-x3000c0w22.hmn.example.com", nil},
+		{"x3000c0w22", "NMN", "x3000c0w22.nmn", []string{"x3000c0w22"}},
+		{"d0w1", "HMN", "d0w1.hmn.example.com", nil},
+	}
+	for _, tt := range tests {
+		fqdn, aliases, err := namer.Name(sm.CompEthInterfaceV2{CompID: tt.compID}, tt.network)
+		if (err != nil) {
+			t.Errorf("ERROR, Name(%s, %s) error: %v", tt.compID, tt.network, err)
+			continue
+		}
+		if (fqdn != tt.fqdn || !reflect.DeepEqual(aliases, tt.aliases)) {
+			t.Errorf("ERROR, Name(%s, %s) returned %s %v, expected %s %v", tt.compID, tt.network, fqdn,
+				aliases, tt.fqdn, tt.aliases)
+		}
+	}
+
+	errTests := []struct {
+		compID  string
+		network string
+		err     error
+	}{
+		{"", "NMN", ErrNamingNoComponent},
+		{"bogus", "NMN", ErrNamingBadXname},
+		{"x3000c0s2b0n0p0", "NMN", ErrNamingUnsupportedType},
+		{"x3000c0s2b0n0", "CAN", ErrNamingNoDomain},
+	}
+	for _, tt := range errTests {
+		_, _, err := namer.Name(sm.CompEthInterfaceV2{CompID: tt.compID}, tt.network)
+		if (!errors.Is(err, tt.err)) {
+			t.Errorf("ERROR, Name(%s, %s) expected %v, got: %v", tt.compID, tt.network, tt.err, err)
+		}
+		for _, other := range errTests {
+			if (IsHSMErrorClass(err, other.err.(*base.HMSError).Class) != (other.err == tt.err)) {
+				t.Errorf("ERROR, Name(%s, %s) error %v has the class of %v", tt.compID, tt.network, err,
+					other.err)
+			}
+		}
+	}
+}
+
+func TestNamerNames(t *testing.T) {
+	namer := testNamer(t)
+
+	hostNames, err := namer.Names(sm.CompEthInterfaceV2{
+		ID:     "a4bf012e7fb1",
+		CompID: "x3000c0s2b0n0",
+		IPAddrs: []sm.IPAddressMapping{
+			{IPAddr: "10.252.1.5", Network: "NMN"},
+			{IPAddr: "10.252.1.6"},
+			{IPAddr: "10.103.1.5", Network: "CAN"},
+			{IPAddr: "10.254.1.5", Network: "HMN"},
+		},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, Names() error: %v", err)
+	}
+	exp := []HostName{
+		{IPAddr: "10.252.1.5", Network: "NMN", FQDN: "x3000c0s2b0n0.nmn", Aliases: []string{"x3000c0s2b0n0"}},
+		{IPAddr: "10.252.1.6", Network: "NMN", FQDN: "x3000c0s2b0n0.nmn", Aliases: []string{"x3000c0s2b0n0"}},
+		{IPAddr: "10.254.1.5", Network: "HMN", FQDN: "x3000c0s2b0n0.hmn.example.com"},
+	}
+	if (!reflect.DeepEqual(hostNames, exp)) {
+		t.Errorf("ERROR, Names() returned %v, expected %v", hostNames, exp)
+	}
+
+	hostNames, err = namer.Names(sm.CompEthInterfaceV2{ID: "a4bf012e7fb2"})
+	if (err != nil || len(hostNames) != 0) {
+		t.Errorf("ERROR, Names() of an interface without IPs returned %v, %v", hostNames, err)
+	}
+}
+
+func TestNewNamerErrors(t *testing.T) {
+	_, err := NewNamer(NamingConfig{Domains: map[string]string{"NMN": "bad_domain"}})
+	if (!errors.Is(err, ErrNamingBadName)) {
+		t.Errorf("ERROR, expected ErrNamingBadName for a bad domain, got: %v", err)
+	}
+	_, err = NewNamer(NamingConfig{Templates: []NameTemplate{{Host: "{{.Xname"}}})
+	if (err == nil) {
+		t.Errorf("ERROR, NewNamer() accepted a bad template.")
+	}
+
+	namer, err := NewNamer(NamingConfig{
+		Domains:   map[string]string{"NMN": "nmn"},
+		Templates: []NameTemplate{{Type: "Node", Host: "{{.Xname}}_node"}},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNamer() error: %v", err)
+	}
+	_, _, err = namer.Name(sm.CompEthInterfaceV2{CompID: "x3000c0s2b0n0"}, "NMN")
+	if (!errors.Is(err, ErrNamingBadName)) {
+		t.Errorf("ERROR, expected ErrNamingBadName for a bad template result, got: %v", err)
+	}
+}
+
+func TestNamerComponents(t *testing.T) {
+	index := NewComponentIndex([]base.Component{
+		{ID: "x3000c0s1b0n0", Type: "Node", NID: "100001", Role: "Management", SubRole: "Worker"},
+		{ID: "x3000c0s2b0n0", Type: "Node", NID: "100002", Role: "Management", SubRole: "Worker"},
+		{ID: "x3000c0s9b0n0", Type: "Node", NID: "59", Role: "Application", SubRole: "UAN"},
+		{ID: "x1000c0s0b0n0", Type: "Node", NID: "123", Role: "Compute"},
+	}, nil)
+	namer, err := NewNamer(NamingConfig{
+		Domains:        map[string]string{"NMN": "nmn", "HMN": "hmn"},
+		PrimaryNetwork: "NMN",
+		Templates: []NameTemplate{
+			{Role: "Management", SubRole: "Worker", Host: `ncn-w{{printf "%03d" .RoleIndex}}`,
+				Aliases: []string{"{{.Xname}}"}},
+			{Role: "Application", SubRole: "UAN", Aliases: []string{`{{.SubRole}}{{printf "%02d" .RoleIndex}}`}},
+		},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNamer() error: %v", err)
+	}
+
+	tests := []struct {
+		compID  string
+		network string
+		fqdn    string
+		aliases []string
+	}{
+		{"x1000c0s0b0n0", "NMN", "x1000c0s0b0n0.nmn", []string{"x1000c0s0b0n0", "nid000123.nmn", "nid000123"}},
+		{"x1000c0s0b0n0", "HMN", "x1000c0s0b0n0.hmn", []string{"nid000123.hmn"}},
+		{"x3000c0s2b0n0", "NMN", "ncn-w002.nmn",
+			[]string{"ncn-w002", "nid100002.nmn", "nid100002", "x3000c0s2b0n0.nmn"}},
+		{"x3000c0s9b0n0", "HMN", "x3000c0s9b0n0.hmn", []string{"nid000059.hmn", "uan01.hmn"}},
+		// BMCs aren't in the index and get no NID alias.
+		{"x1000c0s0b0", "HMN", "x1000c0s0b0.hmn", nil},
+	}
+
+	// Without an index nodes only get their xname.
+	fqdn, aliases, err := namer.Name(sm.CompEthInterfaceV2{CompID: "x1000c0s0b0n0"}, "HMN")
+	if (err != nil || fqdn != "x1000c0s0b0n0.hmn" || len(aliases) != 0) {
+		t.Errorf("ERROR, Name() without components returned %s %v, error: %v", fqdn, aliases, err)
+	}
+
+	namer = namer.WithComponents(index)
+	for _, tt := range tests {
+		fqdn, aliases, err := namer.Name(sm.CompEthInterfaceV2{CompID: tt.compID}, tt.network)
+		if (err != nil) {
+			t.Errorf("ERROR, Name(%s, %s) error: %v", tt.compID, tt.network, err)
+			continue
+		}
+		if (fqdn != tt.fqdn || !reflect.DeepEqual(aliases, tt.aliases)) {
+			t.Errorf("ERROR, Name(%s, %s) returned %s %v, expected %s %v", tt.compID, tt.network, fqdn,
+				aliases, tt.fqdn, tt.aliases)
+		}
+	}
+
+	noNIDs, _ := NewNamer(NamingConfig{Domains: map[string]string{"NMN": "nmn"}, NIDFormat: "-"})
+	_, aliases, _ = noNIDs.WithComponents(index).Name(sm.CompEthInterfaceV2{CompID: "x1000c0s0b0n0"}, "NMN")
+	if (len(aliases) != 0) {
+		t.Errorf("ERROR, NID aliases not turned off: %v", aliases)
+	}
+}
@@ -0,0 +1,196 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// HSM's IPAddressMapping.Network is optional and DHCP feeders rarely set
+// it, which leaves DNS unable to tell an NMN address from an HMN one. A
+// NetworkTable maps network names to their CIDRs so the helper can fill in
+// (or check) Network on every address it sends to HSM; see
+// WithNetworkTable().
+
+// HMSError classes of the network errors.
+const (
+	NetworkErrClassUnknown  = "network-unknown"
+	NetworkErrClassMismatch = "network-mismatch"
+)
+
+var ErrNetworkUnknown = base.NewHMSError(NetworkErrClassUnknown, "IP address is in no configured network")
+var ErrNetworkMismatch = base.NewHMSError(NetworkErrClassMismatch, "IP address is in a different network")
+
+type networkPrefix struct {
+	name   string
+	prefix netip.Prefix
+}
+
+// NetworkTable maps network names (e.g. "NMN", "HMN") to one or more IPv4
+// or IPv6 CIDRs. When CIDRs overlap, the most specific one wins. A table
+// may be shared between helpers, but must not be changed with Add() once
+// it is in use.
+type NetworkTable struct {
+	prefixes []networkPrefix
+}
+
+// NewNetworkTable creates a table from a map of network name to CIDRs,
+// e.g. {"NMN": {"10.252.0.0/17"}, "HMN": {"10.254.0.0/17", "fd00:254::/64"}}.
+func NewNetworkTable(networks map[string][]string) (*NetworkTable, error) {
+	table := &NetworkTable{}
+	for name, cidrs := range networks {
+		if err := table.Add(name, cidrs...); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// Add adds CIDRs to a network, creating it if needed. A CIDR already
+// assigned to a different network is an error.
+func (table *NetworkTable) Add(name string, cidrs ...string) error {
+	if name == "" {
+		return fmt.Errorf("network name must not be empty")
+	}
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return fmt.Errorf("network %s: invalid CIDR '%s': %w", name, cidr, err)
+		}
+		prefix = prefix.Masked()
+
+		dup := false
+		for _, cur := range table.prefixes {
+			if cur.prefix != prefix {
+				continue
+			}
+			if cur.name != name {
+				return fmt.Errorf("network %s: CIDR %s already belongs to network %s", name, prefix, cur.name)
+			}
+			dup = true
+		}
+		if !dup {
+			table.prefixes = append(table.prefixes, networkPrefix{name: name, prefix: prefix})
+		}
+	}
+
+	// Keep the most specific prefixes first so Lookup() can stop at the
+	// first match.
+	sort.SliceStable(table.prefixes, func(i, j int) bool {
+		return table.prefixes[i].prefix.Bits() > table.prefixes[j].prefix.Bits()
+	})
+	return nil
+}
+
+// Networks returns the names of the networks in the table, sorted.
+func (table *NetworkTable) Networks() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, cur := range table.prefixes {
+		if !seen[cur.name] {
+			seen[cur.name] = true
+			names = append(names, cur.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the network ipAddr belongs to. It returns false if the
+// address is in no configured network or isn't a valid IP address.
+func (table *NetworkTable) Lookup(ipAddr string) (network string, ok bool) {
+	addr, err := netip.ParseAddr(ipAddr)
+	if err != nil {
+		return "", false
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, cur := range table.prefixes {
+		if cur.prefix.Contains(addr) {
+			return cur.name, true
+		}
+	}
+	return "", false
+}
+
+// Attribute returns a copy of ipAddrs with Network filled in from the table
+// wherever it was empty. A Network that is already set must match the
+// table (case is ignored); if not, ErrNetworkMismatch is returned. In
+// strict mode an address in no configured network fails with
+// ErrNetworkUnknown; otherwise it is passed through unchanged.
+func (table *NetworkTable) Attribute(ipAddrs []sm.IPAddressMapping, strict bool) (
+	[]sm.IPAddressMapping, error) {
+	if ipAddrs == nil {
+		return nil, nil
+	}
+
+	attributed := make([]sm.IPAddressMapping, len(ipAddrs))
+	copy(attributed, ipAddrs)
+	for ix := range attributed {
+		ipm := &attributed[ix]
+		if ipm.IPAddr == "" {
+			continue
+		}
+		if _, err := netip.ParseAddr(ipm.IPAddr); err != nil {
+			return nil, fmt.Errorf("invalid IP address '%s': %w", ipm.IPAddr, sm.ErrCompEthInterfaceBadIPAddress)
+		}
+
+		network, ok := table.Lookup(ipm.IPAddr)
+		switch {
+		case !ok && strict:
+			return nil, fmt.Errorf("IP address %s: %w", ipm.IPAddr, ErrNetworkUnknown)
+		case !ok:
+		case ipm.Network == "":
+			ipm.Network = network
+		case !strings.EqualFold(ipm.Network, network):
+			return nil, fmt.Errorf("IP address %s is in network %s, not %s: %w", ipm.IPAddr, network,
+				ipm.Network, ErrNetworkMismatch)
+		}
+	}
+	return attributed, nil
+}
+
+// WithNetworkTable makes the helper fill in or check the Network of every
+// IP address it adds or patches in HSM using table, see
+// NetworkTable.Attribute(). In strict mode, addresses that fall in no
+// configured network are refused before anything is sent to HSM.
+func WithNetworkTable(table *NetworkTable, strict bool) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.networks = table
+		helper.strictNetworks = strict
+	}
+}
+
+// attributeNetworks normalizes ipAddrs, see NormalizeIPAddressMappings(),
+// and applies the helper's network table, if it has one.
+func (helper *DNSDHCPHelper) attributeNetworks(ipAddrs []sm.IPAddressMapping) ([]sm.IPAddressMapping, error) {
+	ipAddrs, err := NormalizeIPAddressMappings(ipAddrs)
+	if err != nil || helper.networks == nil {
+		return ipAddrs, err
+	}
+	return helper.networks.Attribute(ipAddrs, helper.strictNetworks)
+}
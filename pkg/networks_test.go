@@ -0,0 +1,185 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func testNetworkTable(t *testing.T) *NetworkTable {
+	table, err := NewNetworkTable(map[string][]string{
+		"NMN":   {"10.252.0.0/17", "fd00:252::/64"},
+		"HMN":   {"10.254.0.0/17"},
+		"MTL":   {"10.1.0.0/16"},
+		"NMNLB": {"10.92.100.0/24"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNetworkTable() error: %v", err)
+	}
+	return table
+}
+
+func TestNetworkTableLookup(t *testing.T) {
+	table := testNetworkTable(t)
+	if err := table.Add("Supern", "10.0.0.0/8"); (err != nil) {
+		t.Fatalf("ERROR, Add() error: %v", err)
+	}
+
+	tests := []struct {
+		ip      string
+		network string
+		ok      bool
+	}{
+		{"10.252.1.5", "NMN", true},
+		{"10.254.127.255", "HMN", true},
+		{"10.254.128.1", "Supern", true},
+		{"10.92.100.71", "NMNLB", true},
+		{"::ffff:10.1.0.9", "MTL", true},
+		{"fd00:252::1f", "NMN", true},
+		{"fe80::1%eth0", "", false},
+		{"192.168.1.1", "", false},
+		{"not-an-ip", "", false},
+	}
+	for _, tt := range tests {
+		network, ok := table.Lookup(tt.ip)
+		if (network != tt.network || ok != tt.ok) {
+			t.Errorf("ERROR, Lookup(%s) returned '%s', %v, expected '%s', %v", tt.ip, network, ok,
+				tt.network, tt.ok)
+		}
+	}
+
+	names := table.Networks()
+	if (len(names) != 5 || names[0] != "HMN" || names[4] != "Supern") {
+		t.Errorf("ERROR, Networks() returned %v", names)
+	}
+
+	if err := table.Add("HMN", "10.252.0.0/17"); (err == nil) {
+		t.Errorf("ERROR, Add() allowed a CIDR in two networks.")
+	}
+	if err := table.Add("NMN", "10.252.0.1/17"); (err != nil) {
+		t.Errorf("ERROR, Add() rejected the same CIDR for the same network: %v", err)
+	}
+	if _, err := NewNetworkTable(map[string][]string{"NMN": {"10.252.0.0/33"}}); (err == nil) {
+		t.Errorf("ERROR, NewNetworkTable() accepted a bad CIDR.")
+	}
+}
+
+func TestNetworkTableAttribute(t *testing.T) {
+	table := testNetworkTable(t)
+	table.Add("Supern", "10.0.0.0/8")
+
+	in := []sm.IPAddressMapping{
+		{IPAddr: "10.252.1.5"},
+		{IPAddr: "10.254.1.5", Network: "hmn"},
+		{IPAddr: "192.168.1.1"},
+	}
+	out, err := table.Attribute(in, false)
+	if (err != nil) {
+		t.Fatalf("ERROR, Attribute() error: %v", err)
+	}
+	if (out[0].Network != "NMN" || out[1].Network != "hmn" || out[2].Network != "") {
+		t.Errorf("ERROR, Attribute() returned %v", out)
+	}
+	if (in[0].Network != "") {
+		t.Errorf("ERROR, Attribute() changed its input.")
+	}
+
+	_, err = table.Attribute(in, true)
+	if (!errors.Is(err, ErrNetworkUnknown)) {
+		t.Errorf("ERROR, expected ErrNetworkUnknown in strict mode, got: %v", err)
+	}
+	_, err = table.Attribute([]sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "HMN"}}, false)
+	if (!errors.Is(err, ErrNetworkMismatch)) {
+		t.Errorf("ERROR, expected ErrNetworkMismatch, got: %v", err)
+	}
+	if (!IsHSMErrorClass(err, NetworkErrClassMismatch) || IsHSMErrorClass(err, NetworkErrClassUnknown) ||
+		base.IsHMSErrorClass(ErrNetworkUnknown, NetworkErrClassMismatch)) {
+		t.Errorf("ERROR, network errors share a class: %v", err)
+	}
+	_, err = table.Attribute([]sm.IPAddressMapping{{IPAddr: "10.252.1.500"}}, false)
+	if (!errors.Is(err, sm.ErrCompEthInterfaceBadIPAddress)) {
+		t.Errorf("ERROR, expected ErrCompEthInterfaceBadIPAddress, got: %v", err)
+	}
+}
+
+func TestWithNetworkTable(t *testing.T) {
+	var posted []sm.CompEthInterfaceV2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var eee sm.CompEthInterfaceV2
+		body, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(body, &eee)
+		posted = append(posted, eee)
+		if (req.Method == "POST") {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	table := testNetworkTable(t)
+
+	hlp := New(srv.URL, WithNetworkTable(table, false))
+	ethi := sm.CompEthInterfaceV2{
+		MACAddr: "a4:bf:01:2e:7f:b1",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5"}, {IPAddr: "172.16.0.1"}},
+	}
+	err := hlp.AddNewEthernetInterface(ethi, false)
+	if (err != nil) {
+		t.Errorf("ERROR, AddNewEthernetInterface() error: %v", err)
+	}
+	err = hlp.PatchEthernetInterface(ethi)
+	if (err != nil) {
+		t.Errorf("ERROR, PatchEthernetInterface() error: %v", err)
+	}
+	if (len(posted) != 2) {
+		t.Fatalf("ERROR, expected 2 requests, got %d", len(posted))
+	}
+	for _, eee := range posted {
+		if (eee.IPAddrs[0].Network != "NMN" || eee.IPAddrs[1].Network != "") {
+			t.Errorf("ERROR, interface sent without networks attributed: %v", eee.IPAddrs)
+		}
+	}
+
+	strict := New(srv.URL, WithNetworkTable(table, true))
+	posted = nil
+	err = strict.AddNewEthernetInterface(ethi, false)
+	if (!errors.Is(err, ErrNetworkUnknown)) {
+		t.Errorf("ERROR, expected ErrNetworkUnknown in strict mode, got: %v", err)
+	}
+	ipAddrs := []sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "NMN"}}
+	_, err = strict.PatchEthernetInterfaceFields(ethi.MACAddr, sm.CompEthInterfaceV2Patch{IPAddrs: &ipAddrs})
+	if (!errors.Is(err, ErrNetworkMismatch)) {
+		t.Errorf("ERROR, expected ErrNetworkMismatch, got: %v", err)
+	}
+	if (len(posted) != 0) {
+		t.Errorf("ERROR, refused interfaces were sent to HSM: %v", posted)
+	}
+}
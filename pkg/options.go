@@ -0,0 +1,101 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// Option configures a DNSDHCPHelper created with New().
+type Option func(*DNSDHCPHelper)
+
+// WithServiceName sets the User-Agent sent on every request. It defaults to
+// the host name.
+func WithServiceName(name string) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.serviceName = name
+	}
+}
+
+// WithHTTPClient sets the retryable client used to talk to HSM. A nil
+// client is ignored and a default one is created.
+func WithHTTPClient(client *retryablehttp.Client) Option {
+	return func(helper *DNSDHCPHelper) {
+		if client != nil {
+			helper.HTTPClient = client
+		}
+	}
+}
+
+// WithBasePath sets the path of the HSM API under the HSM URL, e.g.
+// "/hsm/v2" (the default).
+func WithBasePath(path string) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.basePath = "/" + strings.Trim(path, "/")
+	}
+}
+
+// WithAPIVersion selects the HSM API version, HSMAPIVersion1 or
+// HSMAPIVersion2. It is shorthand for WithBasePath("/hsm/" + version). Any
+// other version fails every request with ErrUnknownAPIVersion.
+func WithAPIVersion(version string) Option {
+	if version != HSMAPIVersion1 && version != HSMAPIVersion2 {
+		return func(helper *DNSDHCPHelper) {
+			helper.configErr = fmt.Errorf("HSM API version '%s': %w", version, ErrUnknownAPIVersion)
+		}
+	}
+	return WithBasePath("/hsm/" + version)
+}
+
+// WithHeader adds a header sent on every request. It may be given several
+// times, including for the same key.
+func WithHeader(key string, value string) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.headers.Add(key, value)
+	}
+}
+
+// WithHeaders adds all of headers to every request.
+func WithHeaders(headers http.Header) Option {
+	return func(helper *DNSDHCPHelper) {
+		for key, vals := range headers {
+			for _, val := range vals {
+				helper.headers.Add(key, val)
+			}
+		}
+	}
+}
+
+// WithRequestTimeout bounds every HSM round-trip, including retries and
+// reading the response, to timeout. It applies on top of any deadline on
+// the caller's context. Zero (the default) means no limit.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.requestTimeout = timeout
+	}
+}
@@ -0,0 +1,143 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// newOptsHandler returns an HSM stand-in and a func returning the requests
+// it got so far. The server calls the handler from its own goroutines.
+func newOptsHandler() (http.HandlerFunc, func() []*http.Request) {
+	var mu sync.Mutex
+	var reqs []*http.Request
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+		if (req.Header.Get("X-Slow") != "") {
+			time.Sleep(500 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[]"))
+	}
+	requests := func() []*http.Request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*http.Request(nil), reqs...)
+	}
+	return handler, requests
+}
+
+func TestNewOptions(t *testing.T) {
+	handler, requests := newOptsHandler()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	client := retryablehttp.NewClient()
+	hlpA := New(srv.URL+"/hsm/v2", WithServiceName("helperA"), WithHTTPClient(client),
+		WithHeader("X-Site", "test"), WithHeader("X-Site", "lab"))
+	hlpB := New(srv.URL, WithServiceName("helperB"), WithBasePath("apis/smd/hsm/v2/"))
+
+	if (hlpA.HTTPClient != client) {
+		t.Errorf("ERROR, WithHTTPClient() client not used.")
+	}
+	if (hlpB.HTTPClient == nil) {
+		t.Errorf("ERROR, New() didn't create HTTP client.")
+	}
+
+	ethi := sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b1"}
+	hlpA.GetAllEthernetInterfaces()
+	hlpA.AddNewEthernetInterface(ethi, false)
+	hlpB.GetAllEthernetInterfaces()
+	hlpB.PatchEthernetInterface(ethi)
+	optsReqs := requests()
+	if (len(optsReqs) != 4) {
+		t.Fatalf("ERROR, expected 4 requests, got %d", len(optsReqs))
+	}
+
+	for ix, req := range(optsReqs) {
+		expUA := "helperA"
+		expPath := "/hsm/v2/Inventory/EthernetInterfaces"
+		if (ix >= 2) {
+			expUA = "helperB"
+			expPath = "/apis/smd/hsm/v2/Inventory/EthernetInterfaces"
+		}
+		if (ix == 3) {
+			expPath += "/a4bf012e7fb1"
+		}
+		ua := req.Header.Values("User-Agent")
+		if (len(ua) != 1 || ua[0] != expUA) {
+			t.Errorf("ERROR, request %d has User-Agent %v, exp '%s'", ix, ua, expUA)
+		}
+		if (req.URL.Path != expPath) {
+			t.Errorf("ERROR, request %d went to '%s', exp '%s'", ix, req.URL.Path, expPath)
+		}
+		site := req.Header.Values("X-Site")
+		if (ix < 2 && (len(site) != 2 || site[0] != "test" || site[1] != "lab")) {
+			t.Errorf("ERROR, request %d missing default headers: %v", ix, site)
+		}
+		if (ix >= 2 && len(site) != 0) {
+			t.Errorf("ERROR, request %d got another helper's headers: %v", ix, site)
+		}
+	}
+
+	hlpV1 := New(srv.URL, WithAPIVersion("v1"))
+	if (hlpV1.hsmURL("/Inventory") != srv.URL+"/hsm/v1/Inventory") {
+		t.Errorf("ERROR, WithAPIVersion() gave '%s'", hlpV1.hsmURL("/Inventory"))
+	}
+
+	// A zero-valued helper still talks to /hsm/v2.
+	lit := DNSDHCPHelper{HSMURL: srv.URL, HTTPClient: client}
+	if (lit.hsmURL("/Inventory") != srv.URL+"/hsm/v2/Inventory") {
+		t.Errorf("ERROR, literal helper gave '%s'", lit.hsmURL("/Inventory"))
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	handler, _ := newOptsHandler()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	hlp := New(srv.URL, WithRequestTimeout(100*time.Millisecond), WithHeader("X-Slow", "1"))
+	hlp.HTTPClient.RetryMax = 0
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	hlp = New(srv.URL, WithRequestTimeout(5*time.Second))
+	_, err = hlp.GetAllEthernetInterfaces()
+	if (err != nil) {
+		t.Errorf("ERROR, GetAllEthernetInterfaces() error: %v", err)
+	}
+}
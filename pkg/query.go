@@ -0,0 +1,115 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+)
+
+// EthernetInterfaceFilter holds the query parameters accepted by HSM's
+// /hsm/v2/Inventory/EthernetInterfaces collection. Each slice field may
+// carry several values, which HSM ORs together; different fields are ANDed.
+// An empty string in ComponentID matches interfaces that have no component
+// assigned. Zero-valued times are not sent.
+type EthernetInterfaceFilter struct {
+	MACAddress  []string
+	IPAddress   []string
+	Network     []string
+	ComponentID []string
+	Type        []string
+	OlderThan   time.Time
+	NewerThan   time.Time
+}
+
+// Values returns the filter as URL query values.
+func (filter EthernetInterfaceFilter) Values() url.Values {
+	values := url.Values{}
+	add := func(key string, vals []string) {
+		for _, val := range vals {
+			values.Add(key, val)
+		}
+	}
+	add("MACAddress", filter.MACAddress)
+	add("IPAddress", filter.IPAddress)
+	add("Network", filter.Network)
+	add("ComponentID", filter.ComponentID)
+	add("Type", filter.Type)
+	if !filter.OlderThan.IsZero() {
+		values.Set("OlderThan", filter.OlderThan.UTC().Format(time.RFC3339))
+	}
+	if !filter.NewerThan.IsZero() {
+		values.Set("NewerThan", filter.NewerThan.UTC().Format(time.RFC3339))
+	}
+	return values
+}
+
+// Encode returns the escaped query string for the filter (without a
+// leading '?'). Keys are sorted, so equal filters encode identically.
+func (filter EthernetInterfaceFilter) Encode() string {
+	return filter.Values().Encode()
+}
+
+func (helper *DNSDHCPHelper) QueryEthernetInterfaces(filter EthernetInterfaceFilter) (
+	ethInterfaces []sm.CompEthInterfaceV2, err error) {
+	return helper.QueryEthernetInterfacesCtx(context.Background(), filter)
+}
+
+// QueryEthernetInterfacesCtx returns the EthernetInterfaces in HSM that
+// match filter. A zero-valued filter returns every interface.
+func (helper *DNSDHCPHelper) QueryEthernetInterfacesCtx(ctx context.Context, filter EthernetInterfaceFilter) (
+	ethInterfaces []sm.CompEthInterfaceV2, err error) {
+	version, err := helper.negotiateAPIVersion(ctx)
+	if err != nil {
+		return
+	}
+	if version == HSMAPIVersion1 && len(filter.Network) > 0 {
+		// v1 interfaces have no networks to match against.
+		err = unsupportedByAPIVersion("filtering on Network", version)
+		return
+	}
+
+	// HSM matches MACAddress literally against its stored, canonical form.
+	if len(filter.MACAddress) > 0 {
+		macAddrs := make([]string, len(filter.MACAddress))
+		for i, macAddr := range filter.MACAddress {
+			if macAddrs[i], _, err = mac.Normalize(macAddr); err != nil {
+				err = fmt.Errorf("invalid MACAddress filter '%s': %w", macAddr, err)
+				return
+			}
+		}
+		filter.MACAddress = macAddrs
+	}
+
+	url := helper.hsmURL("/Inventory/EthernetInterfaces")
+	if query := filter.Encode(); query != "" {
+		url += "?" + query
+	}
+	return helper.getEthernetInterfaces(ctx, version, url)
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEthernetInterfaceFilterEncode(t *testing.T) {
+	var filter EthernetInterfaceFilter
+	if (filter.Encode() != "") {
+		t.Errorf("ERROR, empty filter encoded as '%s'", filter.Encode())
+	}
+
+	filter = EthernetInterfaceFilter{
+		Network:     []string{"NMN", "HMN"},
+		ComponentID: []string{"x3000c0s1b0n0"},
+		IPAddress:   []string{"fd00::1"},
+		NewerThan:   time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("CDT", -5*60*60)),
+	}
+	exp := "ComponentID=x3000c0s1b0n0&IPAddress=fd00%3A%3A1&Network=NMN&Network=HMN" +
+		"&NewerThan=2026-10-16T17%3A00%3A00Z"
+	if (filter.Encode() != exp) {
+		t.Errorf("ERROR, wrong encoding, exp '%s', got '%s'", exp, filter.Encode())
+	}
+
+	filter = EthernetInterfaceFilter{ComponentID: []string{""}}
+	if (filter.Encode() != "ComponentID=") {
+		t.Errorf("ERROR, wrong encoding for unknown components: '%s'", filter.Encode())
+	}
+}
+
+func TestQueryEthernetInterfaces(t *testing.T) {
+	var gotQuery map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotQuery = req.URL.Query()
+		handleUnk(w, req)
+	}))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	older := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	compList, err := hlp.QueryEthernetInterfaces(EthernetInterfaceFilter{
+		MACAddress: []string{"a4:bf:01:2e:7f:b1", "A4BF.012E.7FB2"},
+		Type:       []string{"Node"},
+		OlderThan:  older,
+	})
+	if (err != nil) {
+		t.Errorf("ERROR, QueryEthernetInterfaces() error: %v", err)
+	}
+	if (len(compList) != 2) {
+		t.Errorf("ERROR expecting 2 components, got %d", len(compList))
+	}
+	if (len(gotQuery["MACAddress"]) != 2 || gotQuery["MACAddress"][1] != "a4:bf:01:2e:7f:b2") {
+		t.Errorf("ERROR, expected 2 canonical MACAddress params, got %v", gotQuery["MACAddress"])
+	}
+	if (len(gotQuery["Type"]) != 1 || gotQuery["Type"][0] != "Node") {
+		t.Errorf("ERROR, wrong Type params: %v", gotQuery["Type"])
+	}
+	if (len(gotQuery["OlderThan"]) != 1 || gotQuery["OlderThan"][0] != "2026-01-02T03:04:05Z") {
+		t.Errorf("ERROR, wrong OlderThan params: %v", gotQuery["OlderThan"])
+	}
+
+	gotQuery = nil
+	_, err = hlp.QueryEthernetInterfaces(EthernetInterfaceFilter{MACAddress: []string{"a4:bf"}})
+	if (err == nil || gotQuery != nil) {
+		t.Errorf("ERROR, QueryEthernetInterfaces() accepted a malformed MAC, err: %v", err)
+	}
+
+	_, err = hlp.GetUnknownComponents()
+	if (err != nil) {
+		t.Errorf("ERROR, GetUnknownComponents() error: %v", err)
+	}
+	if cids, ok := gotQuery["ComponentID"]; (!ok || len(cids) != 1 || cids[0] != "") {
+		t.Errorf("ERROR, GetUnknownComponents() didn't filter on ComponentID: %v", gotQuery)
+	}
+}
@@ -0,0 +1,462 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+// A Controller keeps a DHCP or DNS backend in step with HSM. Each cycle it
+// computes the desired state from HSM, reads the backend's actual state,
+// and applies the differences. Cycles run every Interval, right away when
+// triggered, and sooner after failures, with exponential backoff; only one
+// runs at a time. State is a set of StateItems, so the same controller
+// drives Kea reservations (KeaHostBackend), generated config files
+// (FileBackend) or any other ReconcileBackend.
+
+// Controller defaults.
+const (
+	DefaultReconcileInterval = 5 * time.Minute
+	DefaultMinBackoff        = 5 * time.Second
+)
+
+// Why a cycle ran.
+const (
+	CycleInitial = "initial"
+	CycleTimer   = "timer"
+	CycleRetry   = "retry"
+	CycleManual  = "manual"
+)
+
+var ErrControllerRunning = base.NewHMSError("reconcile", "controller is already running")
+
+// StateItem is one unit of reconciled state, e.g. a reservation or a file,
+// identified by Key. Values are compared with reflect.DeepEqual unless the
+// backend is a StateComparer.
+type StateItem struct {
+	Key   string
+	Value interface{}
+}
+
+// StateDiff is what it takes to turn actual state into desired state.
+// Items are sorted by key.
+type StateDiff struct {
+	Create []StateItem
+	Update []StateItem
+	// Replaced[i] is the actual item Update[i] replaces.
+	Replaced []StateItem
+	Delete   []StateItem
+}
+
+// Empty is true if there is nothing to do.
+func (diff StateDiff) Empty() bool {
+	return len(diff.Create) == 0 && len(diff.Update) == 0 && len(diff.Delete) == 0
+}
+
+// ReconcileBackend is the actual state a Controller converges, e.g. a Kea
+// server, a DNS server or a config file.
+type ReconcileBackend interface {
+	// Actual returns the backend's current items.
+	Actual(ctx context.Context) ([]StateItem, error)
+	// Apply makes the changes in diff.
+	Apply(ctx context.Context, diff StateDiff) error
+}
+
+// StateComparer is implemented by backends whose items need a looser
+// comparison than reflect.DeepEqual, e.g. because the backend fills in
+// defaults.
+type StateComparer interface {
+	Equal(desired StateItem, actual StateItem) bool
+}
+
+// DiffState compares desired and actual items by key. Actual items with no
+// desired counterpart are only deleted if prune is set. equal may be nil
+// for reflect.DeepEqual. Desired keys must be unique.
+func DiffState(desired []StateItem, actual []StateItem, prune bool,
+	equal func(desired StateItem, actual StateItem) bool) (diff StateDiff, err error) {
+	if equal == nil {
+		equal = func(desired StateItem, actual StateItem) bool {
+			return reflect.DeepEqual(desired.Value, actual.Value)
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, item := range desired {
+		if wanted[item.Key] {
+			return diff, fmt.Errorf("desired state has key '%s' twice", item.Key)
+		}
+		wanted[item.Key] = true
+	}
+	current := make(map[string]StateItem)
+	for _, item := range actual {
+		current[item.Key] = item
+	}
+
+	sorted := append([]StateItem(nil), desired...)
+	sortStateItems(sorted)
+	for _, item := range sorted {
+		cur, ok := current[item.Key]
+		switch {
+		case !ok:
+			diff.Create = append(diff.Create, item)
+		case !equal(item, cur):
+			diff.Update = append(diff.Update, item)
+			diff.Replaced = append(diff.Replaced, cur)
+		}
+	}
+	if prune {
+		for _, item := range actual {
+			if !wanted[item.Key] {
+				diff.Delete = append(diff.Delete, item)
+			}
+		}
+		sortStateItems(diff.Delete)
+	}
+	return diff, nil
+}
+
+func sortStateItems(items []StateItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+}
+
+// CycleStats describes one controller cycle.
+type CycleStats struct {
+	Cycle    int    // 1 for the first cycle of the controller.
+	Reason   string // CycleInitial, CycleTimer, CycleRetry or CycleManual.
+	Start    time.Time
+	Duration time.Duration
+
+	// Item counts, and the differences found. With DryRun, or if Err is
+	// set, the differences may not have been applied.
+	Desired int
+	Actual  int
+	Created int
+	Updated int
+	Deleted int
+	DryRun  bool
+
+	Err error
+	// Failed cycles in a row, including this one; 0 if it succeeded.
+	Failures int
+}
+
+// cycleFlight is a cycle in progress, shared by everyone who asked for one
+// while it runs. The cycle runs on its own goroutine and context, which is
+// cancelled once every caller waiting for it has given up. ran is closed
+// when the cycle is over, done once OnCycle has seen it too.
+type cycleFlight struct {
+	ran       chan struct{}
+	done      chan struct{}
+	stats     CycleStats
+	waiters   int
+	abandoned bool
+	cancel    context.CancelFunc
+}
+
+// Controller reconciles a backend with HSM, see above. Set its fields
+// before calling Run() or RunOnce(), and don't change them after.
+type Controller struct {
+	HSM Client
+	// Computes the desired state from HSM.
+	Desired func(ctx context.Context, hsm Client) ([]StateItem, error)
+	Backend ReconcileBackend
+
+	// Optional. Time between cycles, DefaultReconcileInterval if zero. A
+	// random delay of up to Jitter is added to every wait, so controllers
+	// started together don't stay in step.
+	Interval time.Duration
+	Jitter   time.Duration
+
+	// Optional. After a failed cycle, the next one runs after MinBackoff,
+	// doubling with each further failure up to MaxBackoff.
+	// DefaultMinBackoff and Interval if zero.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Optional. Delete backend items HSM doesn't want. Without it,
+	// reconciliation only adds and updates.
+	Prune bool
+
+	// Optional. Find the differences without applying them.
+	DryRun bool
+
+	// Optional. Each value received runs a cycle right away. Closing it
+	// only stops the triggering.
+	Trigger <-chan struct{}
+
+	// Optional. Called at the end of every cycle, on the goroutine that
+	// ran it, before the callers waiting for the cycle get its result.
+	OnCycle func(stats CycleStats)
+
+	mu       sync.Mutex
+	flight   *cycleFlight
+	cycles   int
+	failures int
+	looping  bool
+	stop     context.CancelFunc
+	done     chan struct{}
+
+	// Replaced in tests.
+	now   func() time.Time
+	after func(d time.Duration) <-chan time.Time
+}
+
+// RunOnce runs a cycle now and returns its stats and error. If a cycle is
+// already running, RunOnce waits for it and returns its result instead.
+// Cancelling ctx makes RunOnce return ctx's error. The cycle itself is only
+// cancelled when nobody else is waiting for it, and then RunOnce returns
+// once it has stopped.
+func (ctrl *Controller) RunOnce(ctx context.Context) (CycleStats, error) {
+	return ctrl.runOnce(ctx, CycleManual)
+}
+
+func (ctrl *Controller) runOnce(ctx context.Context, reason string) (CycleStats, error) {
+	ctrl.mu.Lock()
+	flight := ctrl.flight
+	for flight != nil && flight.abandoned {
+		// Being cancelled, so its result is no use; wait for it to
+		// finish rather than run two cycles at once.
+		ctrl.mu.Unlock()
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return CycleStats{Reason: reason, Err: ctx.Err()}, ctx.Err()
+		}
+		ctrl.mu.Lock()
+		flight = ctrl.flight
+	}
+	if flight == nil {
+		// Not bound to ctx, as callers joining later depend on it too.
+		cycleCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		flight = &cycleFlight{ran: make(chan struct{}), done: make(chan struct{}), cancel: cancel}
+		ctrl.flight = flight
+		ctrl.cycles++
+		go ctrl.runFlight(cycleCtx, flight, ctrl.cycles, reason)
+	}
+	flight.waiters++
+	ctrl.mu.Unlock()
+
+	select {
+	case <-flight.done:
+		return flight.stats, flight.stats.Err
+	case <-ctx.Done():
+		ctrl.mu.Lock()
+		flight.waiters--
+		abandoned := flight.waiters == 0
+		if abandoned {
+			flight.abandoned = true
+			flight.cancel()
+		}
+		ctrl.mu.Unlock()
+		if abandoned {
+			<-flight.ran
+		}
+		return CycleStats{Reason: reason, Err: ctx.Err()}, ctx.Err()
+	}
+}
+
+// runFlight runs the cycle of flight and hands its result to the callers
+// waiting for it. A cycle cancelled because nobody waits for it any more
+// doesn't count as a failure.
+func (ctrl *Controller) runFlight(ctx context.Context, flight *cycleFlight, cycle int, reason string) {
+	stats := ctrl.cycle(ctx, reason)
+	stats.Cycle = cycle
+
+	ctrl.mu.Lock()
+	if stats.Err == nil {
+		ctrl.failures = 0
+	} else if !flight.abandoned {
+		ctrl.failures++
+	}
+	stats.Failures = ctrl.failures
+	flight.stats = stats
+	ctrl.flight = nil
+	ctrl.mu.Unlock()
+	flight.cancel()
+	close(flight.ran)
+
+	if ctrl.OnCycle != nil {
+		ctrl.OnCycle(stats)
+	}
+	close(flight.done)
+}
+
+// cycle computes and applies the differences once.
+func (ctrl *Controller) cycle(ctx context.Context, reason string) (stats CycleStats) {
+	now := ctrl.now
+	if now == nil {
+		now = time.Now
+	}
+	stats = CycleStats{Reason: reason, Start: now(), DryRun: ctrl.DryRun}
+	defer func() {
+		stats.Duration = now().Sub(stats.Start)
+	}()
+
+	desired, err := ctrl.Desired(ctx, ctrl.HSM)
+	if err != nil {
+		stats.Err = fmt.Errorf("desired state: %w", err)
+		return
+	}
+	stats.Desired = len(desired)
+	actual, err := ctrl.Backend.Actual(ctx)
+	if err != nil {
+		stats.Err = fmt.Errorf("actual state: %w", err)
+		return
+	}
+	stats.Actual = len(actual)
+
+	var equal func(desired StateItem, actual StateItem) bool
+	if comparer, ok := ctrl.Backend.(StateComparer); ok {
+		equal = comparer.Equal
+	}
+	diff, err := DiffState(desired, actual, ctrl.Prune, equal)
+	if err != nil {
+		stats.Err = err
+		return
+	}
+	stats.Created = len(diff.Create)
+	stats.Updated = len(diff.Update)
+	stats.Deleted = len(diff.Delete)
+
+	if !ctrl.DryRun && !diff.Empty() {
+		if err = ctrl.Backend.Apply(ctx, diff); err != nil {
+			stats.Err = fmt.Errorf("apply: %w", err)
+		}
+	}
+	return
+}
+
+// Run runs cycles until ctx is done or until Stop(). The first cycle runs
+// right away. Either way the cycle in progress is cancelled, unless
+// RunOnce() callers are waiting for it too. Run returns ctx's error, or nil
+// after Stop().
+func (ctrl *Controller) Run(ctx context.Context) error {
+	ctrl.mu.Lock()
+	if ctrl.looping {
+		ctrl.mu.Unlock()
+		return ErrControllerRunning
+	}
+	ctrl.looping = true
+	loopCtx, stop := context.WithCancel(ctx)
+	ctrl.stop = stop
+	ctrl.done = make(chan struct{})
+	ctrl.mu.Unlock()
+
+	defer func() {
+		stop()
+		ctrl.mu.Lock()
+		ctrl.looping = false
+		close(ctrl.done)
+		ctrl.mu.Unlock()
+	}()
+
+	after := ctrl.after
+	if after == nil {
+		after = time.After
+	}
+	trigger := ctrl.Trigger
+	reason := CycleInitial
+	for {
+		stats, _ := ctrl.runOnce(loopCtx, reason)
+		if loopCtx.Err() != nil {
+			return ctx.Err()
+		}
+
+		reason = CycleTimer
+		if stats.Failures > 0 {
+			reason = CycleRetry
+		}
+		timer := after(ctrl.delay(stats.Failures))
+	wait:
+		for {
+			select {
+			case <-loopCtx.Done():
+				return ctx.Err()
+			case <-timer:
+				break wait
+			case _, ok := <-trigger:
+				if !ok {
+					// A nil channel never receives, so the timer takes over.
+					trigger = nil
+					continue
+				}
+				reason = CycleManual
+				break wait
+			}
+		}
+	}
+}
+
+// Stop makes Run() return, cancelling the cycle in progress as above, and
+// waits for it to. Cycles run on goroutines of their own, so OnCycle may
+// call Stop too.
+func (ctrl *Controller) Stop() {
+	ctrl.mu.Lock()
+	if !ctrl.looping {
+		ctrl.mu.Unlock()
+		return
+	}
+	ctrl.stop()
+	done := ctrl.done
+	ctrl.mu.Unlock()
+	<-done
+}
+
+// delay is the wait before the next cycle after failures failed ones.
+func (ctrl *Controller) delay(failures int) time.Duration {
+	interval := ctrl.Interval
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+
+	wait := interval
+	if failures > 0 {
+		wait = ctrl.MinBackoff
+		if wait <= 0 {
+			wait = DefaultMinBackoff
+		}
+		maxBackoff := ctrl.MaxBackoff
+		if maxBackoff <= 0 {
+			maxBackoff = interval
+		}
+		for ix := 1; ix < failures && wait < maxBackoff; ix++ {
+			wait *= 2
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+	}
+	if ctrl.Jitter > 0 {
+		wait += rand.N(ctrl.Jitter)
+	}
+	return wait
+}
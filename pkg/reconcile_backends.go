@@ -0,0 +1,259 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DesiredKeaHosts returns a Controller.Desired that generates Kea DHCPv4
+// reservations with gen, one StateItem per reservation, for a
+// KeaHostBackend. Addresses gen leaves out are not reported. With
+// subnetIDs, only the reservations of those subnets are; pass the
+// backend's SubnetIDs, if it has any, so the two agree on what is
+// reconciled.
+func DesiredKeaHosts(gen *KeaGenerator, subnetIDs ...int) func(ctx context.Context, hsm Client) ([]StateItem,
+	error) {
+	return func(ctx context.Context, hsm Client) ([]StateItem, error) {
+		config, _, err := hsm.GenerateKeaDhcp4Ctx(ctx, gen)
+		if err != nil {
+			return nil, err
+		}
+		var items []StateItem
+		for _, subnet := range config.Subnet4 {
+			if len(subnetIDs) > 0 && !slices.Contains(subnetIDs, subnet.ID) {
+				continue
+			}
+			for _, reservation := range subnet.Reservations {
+				items = append(items, keaHostItem(KeaHost{KeaReservation: reservation, SubnetID: subnet.ID}))
+			}
+		}
+		return items, nil
+	}
+}
+
+// keaHostItem keys host on its subnet and MAC, which Kea allows one
+// reservation for.
+func keaHostItem(host KeaHost) StateItem {
+	return StateItem{
+		Key:   fmt.Sprintf("%d/%s", host.SubnetID, strings.ToLower(host.HWAddress)),
+		Value: host,
+	}
+}
+
+// KeaHostBackend reconciles the DHCPv4 host reservations of a Kea server,
+// through the host_cmds hook. Item values are KeaHosts.
+type KeaHostBackend struct {
+	Kea *KeaClient
+
+	// The subnets reconciled. Reservations in other subnets, and those
+	// not keyed on a MAC, are left alone.
+	SubnetIDs []int
+	// Used if SubnetIDs is empty: every IPv4 subnet of the table is
+	// reconciled. Usually the KeaGenerator's.
+	Subnets *SubnetTable
+}
+
+// subnetIDs returns the IDs of the subnets reconciled.
+func (backend *KeaHostBackend) subnetIDs() (ids []int) {
+	if len(backend.SubnetIDs) > 0 || backend.Subnets == nil {
+		return backend.SubnetIDs
+	}
+	for _, subnet := range backend.Subnets.Subnets() {
+		if subnet.Prefix().Addr().Is4() {
+			ids = append(ids, subnet.ID)
+		}
+	}
+	return
+}
+
+// Actual returns the reservations of the backend's subnets.
+func (backend *KeaHostBackend) Actual(ctx context.Context) (items []StateItem, err error) {
+	subnetIDs := backend.subnetIDs()
+	if len(subnetIDs) == 0 {
+		return nil, fmt.Errorf("Kea host backend has no subnets to reconcile")
+	}
+	for _, subnetID := range subnetIDs {
+		hosts, err := backend.Kea.ReservationGetAllCtx(ctx, subnetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			if host.HWAddress == "" {
+				continue
+			}
+			host.SubnetID = subnetID
+			items = append(items, keaHostItem(host))
+		}
+	}
+	return items, nil
+}
+
+// Apply deletes the reservations diff deletes or replaces, then adds the
+// new ones, so a changed reservation is briefly missing. Reservations are
+// deleted by MAC, their key, so one that moves to another address of its
+// subnet is deleted at the address it has. Apply carries on past failed
+// commands and returns all their errors.
+func (backend *KeaHostBackend) Apply(ctx context.Context, diff StateDiff) error {
+	var errs []error
+	for _, item := range append(append([]StateItem(nil), diff.Delete...), diff.Replaced...) {
+		host := item.Value.(KeaHost)
+		err := backend.Kea.ReservationDelByHWAddressCtx(ctx, host.SubnetID, host.HWAddress)
+		if err != nil && !errors.Is(err, ErrKeaEmpty) {
+			errs = append(errs, err)
+		}
+	}
+	for _, item := range append(append([]StateItem(nil), diff.Update...), diff.Create...) {
+		host := item.Value.(KeaHost)
+		if err := backend.Kea.ReservationAddCtx(ctx, host.SubnetID, host.KeaReservation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Equal compares reservations, ignoring the option codes and spaces Kea
+// fills in for options given by name, and the reverse. Kea reports a
+// next-server of 0.0.0.0 for a reservation without one.
+func (backend *KeaHostBackend) Equal(desired StateItem, actual StateItem) bool {
+	want, have := desired.Value.(KeaHost), actual.Value.(KeaHost)
+	nextServer := func(host KeaHost) string {
+		if host.NextServer == "0.0.0.0" {
+			return ""
+		}
+		return host.NextServer
+	}
+	if !strings.EqualFold(want.HWAddress, have.HWAddress) || want.IPAddress != have.IPAddress ||
+		want.Hostname != have.Hostname || nextServer(want) != nextServer(have) ||
+		want.ServerHostname != have.ServerHostname || want.BootFileName != have.BootFileName ||
+		len(want.OptionData) != len(have.OptionData) {
+		return false
+	}
+	for ix, option := range want.OptionData {
+		got := have.OptionData[ix]
+		if option.Data != got.Data ||
+			(option.Name != "" && got.Name != "" && !strings.EqualFold(option.Name, got.Name)) ||
+			(option.Code != 0 && got.Code != 0 && option.Code != got.Code) ||
+			(option.Space != "" && got.Space != "" && option.Space != got.Space) {
+			return false
+		}
+	}
+	return true
+}
+
+// DesiredFile returns a Controller.Desired that renders a file with
+// render, for a FileBackend with the same path, e.g. with
+// WriteDhcpdHostsCtx() or WriteDnsmasqHostsCtx().
+func DesiredFile(path string, render func(ctx context.Context, hsm Client, w io.Writer) error) func(
+	ctx context.Context, hsm Client) ([]StateItem, error) {
+	return func(ctx context.Context, hsm Client) ([]StateItem, error) {
+		var buf bytes.Buffer
+		if err := render(ctx, hsm, &buf); err != nil {
+			return nil, err
+		}
+		return []StateItem{{Key: path, Value: buf.String()}}, nil
+	}
+}
+
+// FileBackend reconciles a generated file, a single item keyed on Path
+// whose value is the file's contents as a string. The file is replaced
+// atomically, by renaming a new file over it.
+type FileBackend struct {
+	Path string
+	// Optional. 0644 if zero.
+	Perm fs.FileMode
+
+	// Optional. Called after the file changed, e.g. to make the server
+	// reload it; an error fails the cycle.
+	OnChange func(ctx context.Context) error
+}
+
+// Actual returns the file, or nothing if it doesn't exist.
+func (backend *FileBackend) Actual(ctx context.Context) ([]StateItem, error) {
+	contents, err := os.ReadFile(backend.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []StateItem{{Key: backend.Path, Value: string(contents)}}, nil
+}
+
+// Apply writes or removes the file.
+func (backend *FileBackend) Apply(ctx context.Context, diff StateDiff) (err error) {
+	for _, item := range append(append([]StateItem(nil), diff.Create...), diff.Update...) {
+		if item.Key != backend.Path {
+			return fmt.Errorf("file backend for %s can't write %s", backend.Path, item.Key)
+		}
+		contents, ok := item.Value.(string)
+		if !ok {
+			return fmt.Errorf("%s: contents must be a string, not %T", item.Key, item.Value)
+		}
+		if err = backend.write(contents); err != nil {
+			return
+		}
+	}
+	for _, item := range diff.Delete {
+		if err = os.Remove(item.Key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+	if backend.OnChange != nil {
+		err = backend.OnChange(ctx)
+	}
+	return
+}
+
+// write replaces the file with contents.
+func (backend *FileBackend) write(contents string) error {
+	perm := backend.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(backend.Path), "."+filepath.Base(backend.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(contents)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), backend.Path)
+	}
+	return err
+}
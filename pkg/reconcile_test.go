@@ -0,0 +1,417 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memBackend is a ReconcileBackend holding items in memory.
+type memBackend struct {
+	mu      sync.Mutex
+	items   map[string]interface{}
+	applied int
+	err     error
+}
+
+func (backend *memBackend) Actual(ctx context.Context) (items []StateItem, err error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	for key, value := range backend.items {
+		items = append(items, StateItem{Key: key, Value: value})
+	}
+	return items, backend.err
+}
+
+func (backend *memBackend) Apply(ctx context.Context, diff StateDiff) error {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	backend.applied++
+	for _, item := range append(diff.Create, diff.Update...) {
+		backend.items[item.Key] = item.Value
+	}
+	for _, item := range diff.Delete {
+		delete(backend.items, item.Key)
+	}
+	return nil
+}
+
+func TestDiffState(t *testing.T) {
+	desired := []StateItem{{"c", 3}, {"a", 1}, {"b", 2}}
+	actual := []StateItem{{"d", 5}, {"c", 4}, {"b", 2}, {"e", 6}}
+
+	diff, err := DiffState(desired, actual, false, nil)
+	expected := StateDiff{Create: []StateItem{{"a", 1}}, Update: []StateItem{{"c", 3}},
+		Replaced: []StateItem{{"c", 4}}}
+	if (err != nil || !reflect.DeepEqual(diff, expected)) {
+		t.Errorf("ERROR, DiffState() returned %v, error: %v", diff, err)
+	}
+
+	diff, err = DiffState(desired, actual, true, nil)
+	if (err != nil || !reflect.DeepEqual(diff.Delete, []StateItem{{"d", 5}, {"e", 6}})) {
+		t.Errorf("ERROR, DiffState() with prune returned %v, error: %v", diff, err)
+	}
+
+	diff, err = DiffState(desired, actual, false, func(desired StateItem, actual StateItem) bool { return true })
+	if (err != nil || len(diff.Update) != 0 || len(diff.Create) != 1) {
+		t.Errorf("ERROR, DiffState() with equal returned %v, error: %v", diff, err)
+	}
+	if (!(StateDiff{}).Empty() || diff.Empty()) {
+		t.Errorf("ERROR, StateDiff.Empty() is wrong")
+	}
+
+	if _, err = DiffState([]StateItem{{"a", 1}, {"a", 2}}, nil, false, nil); (err == nil) {
+		t.Errorf("ERROR, expected an error for a duplicate key")
+	}
+}
+
+func TestControllerRunOnce(t *testing.T) {
+	backend := &memBackend{items: map[string]interface{}{"b": 2, "c": 4, "d": 5}}
+	desired := []StateItem{{"a", 1}, {"b", 2}, {"c", 3}}
+	var desiredErr error
+	var seen []CycleStats
+	ctrl := &Controller{
+		Desired: func(ctx context.Context, hsm Client) ([]StateItem, error) {
+			return desired, desiredErr
+		},
+		Backend: backend,
+		DryRun:  true,
+		OnCycle: func(stats CycleStats) { seen = append(seen, stats) },
+	}
+
+	stats, err := ctrl.RunOnce(context.Background())
+	if (err != nil || stats.Cycle != 1 || stats.Reason != CycleManual || !stats.DryRun || stats.Desired != 3 ||
+		stats.Actual != 3 || stats.Created != 1 || stats.Updated != 1 || stats.Deleted != 0 || backend.applied != 0) {
+		t.Errorf("ERROR, dry run returned %+v, error: %v", stats, err)
+	}
+
+	ctrl.DryRun = false
+	ctrl.Prune = true
+	stats, err = ctrl.RunOnce(context.Background())
+	if (err != nil || stats.Cycle != 2 || stats.Created != 1 || stats.Updated != 1 || stats.Deleted != 1 ||
+		!reflect.DeepEqual(backend.items, map[string]interface{}{"a": 1, "b": 2, "c": 3})) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, backend %v", stats, err, backend.items)
+	}
+
+	// Nothing to do; Apply isn't called.
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || backend.applied != 1 || stats.Created != 0) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, %d applies", stats, err, backend.applied)
+	}
+
+	desiredErr = errors.New("HSM is down")
+	ctrl.RunOnce(context.Background())
+	stats, err = ctrl.RunOnce(context.Background())
+	if (!errors.Is(err, desiredErr) || stats.Failures != 2 || !errors.Is(stats.Err, desiredErr)) {
+		t.Errorf("ERROR, failed RunOnce() returned %+v, error: %v", stats, err)
+	}
+	desiredErr = nil
+	backend.err = errors.New("backend is down")
+	if stats, err = ctrl.RunOnce(context.Background()); (!errors.Is(err, backend.err) || stats.Failures != 3) {
+		t.Errorf("ERROR, failed RunOnce() returned %+v, error: %v", stats, err)
+	}
+	backend.err = nil
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || stats.Failures != 0) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v", stats, err)
+	}
+	if (len(seen) != 7 || seen[6].Cycle != 7) {
+		t.Errorf("ERROR, OnCycle saw %d cycles", len(seen))
+	}
+}
+
+func TestControllerSingleFlight(t *testing.T) {
+	entered := make(chan bool)
+	release := make(chan bool)
+	calls := 0
+	ctrl := &Controller{
+		Desired: func(ctx context.Context, hsm Client) ([]StateItem, error) {
+			calls++
+			entered <- true
+			<-release
+			return nil, nil
+		},
+		Backend: &memBackend{items: map[string]interface{}{}},
+	}
+
+	results := make(chan CycleStats, 2)
+	go func() {
+		stats, _ := ctrl.RunOnce(context.Background())
+		results <- stats
+	}()
+	<-entered
+	go func() {
+		stats, _ := ctrl.RunOnce(context.Background())
+		results <- stats
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	first, second := <-results, <-results
+	if (calls != 1 || first.Cycle != 1 || second.Cycle != 1) {
+		t.Errorf("ERROR, expected one shared cycle, got %d calls, cycles %d and %d", calls, first.Cycle,
+			second.Cycle)
+	}
+
+	// A waiter whose context ends stops waiting.
+	release = make(chan bool)
+	go ctrl.RunOnce(context.Background())
+	<-entered
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := ctrl.RunOnce(ctx); (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, expected context.DeadlineExceeded, got %v", err)
+	}
+	close(release)
+}
+
+func TestControllerCancel(t *testing.T) {
+	entered := make(chan bool, 1)
+	ctrl := &Controller{
+		Desired: func(ctx context.Context, hsm Client) ([]StateItem, error) {
+			entered <- true
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		Backend: &memBackend{items: map[string]interface{}{}},
+	}
+
+	// The caller that started the cycle giving up doesn't fail those who
+	// joined it.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := ctrl.RunOnce(ctx)
+		first <- err
+	}()
+	<-entered
+	joinCtx, joinCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer joinCancel()
+	joined := make(chan error)
+	go func() {
+		_, err := ctrl.RunOnce(joinCtx)
+		joined <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-first; (!errors.Is(err, context.Canceled)) {
+		t.Errorf("ERROR, expected context.Canceled, got %v", err)
+	}
+	if err := <-joined; (!errors.Is(err, context.DeadlineExceeded)) {
+		t.Errorf("ERROR, joined RunOnce() expected its own context.DeadlineExceeded, got %v", err)
+	}
+	// Nobody waits for it any more, so it was cancelled, and that isn't a
+	// failure.
+	ctrl.mu.Lock()
+	if (ctrl.flight != nil || ctrl.failures != 0) {
+		t.Errorf("ERROR, abandoned cycle still running or counted as failure (%d)", ctrl.failures)
+	}
+	ctrl.mu.Unlock()
+
+	// Stop cancels the cycle in progress.
+	ran := make(chan error)
+	go func() { ran <- ctrl.Run(context.Background()) }()
+	<-entered
+	ctrl.Stop()
+	if err := <-ran; (err != nil) {
+		t.Errorf("ERROR, Run() returned %v after Stop()", err)
+	}
+
+	// OnCycle can stop the controller.
+	ctrl = &Controller{
+		Desired: func(ctx context.Context, hsm Client) ([]StateItem, error) { return nil, nil },
+		Backend: &memBackend{items: map[string]interface{}{}},
+	}
+	ctrl.OnCycle = func(stats CycleStats) { ctrl.Stop() }
+	go func() { ran <- ctrl.Run(context.Background()) }()
+	select {
+	case err := <-ran:
+		if (err != nil) {
+			t.Errorf("ERROR, Run() returned %v after Stop() from OnCycle", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ERROR, Stop() from OnCycle deadlocked")
+	}
+}
+
+func TestControllerDelay(t *testing.T) {
+	ctrl := &Controller{Interval: time.Minute, MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := map[int]time.Duration{0: time.Minute, 1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second,
+		4: 5 * time.Second, 50: 5 * time.Second}
+	for failures, delay := range expected {
+		if (ctrl.delay(failures) != delay) {
+			t.Errorf("ERROR, delay(%d) is %s, expected %s", failures, ctrl.delay(failures), delay)
+		}
+	}
+
+	ctrl = &Controller{}
+	if (ctrl.delay(0) != DefaultReconcileInterval || ctrl.delay(1) != DefaultMinBackoff ||
+		ctrl.delay(100) != DefaultReconcileInterval) {
+		t.Errorf("ERROR, unexpected default delays %s, %s, %s", ctrl.delay(0), ctrl.delay(1), ctrl.delay(100))
+	}
+
+	ctrl = &Controller{Interval: time.Minute, Jitter: 10 * time.Second}
+	for ix := 0; ix < 100; ix++ {
+		if delay := ctrl.delay(0); (delay < time.Minute || delay >= time.Minute+10*time.Second) {
+			t.Fatalf("ERROR, delay with jitter is %s", delay)
+		}
+	}
+}
+
+func TestControllerRun(t *testing.T) {
+	var mu sync.Mutex
+	var desiredErr error
+	cycles := make(chan CycleStats, 10)
+	delays := make(chan time.Duration, 10)
+	tick := make(chan time.Time)
+	trigger := make(chan struct{})
+
+	ctrl := &Controller{
+		Desired: func(ctx context.Context, hsm Client) ([]StateItem, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return nil, desiredErr
+		},
+		Backend:    &memBackend{items: map[string]interface{}{}},
+		Interval:   time.Minute,
+		MinBackoff: time.Second,
+		Trigger:    trigger,
+		OnCycle:    func(stats CycleStats) { cycles <- stats },
+		after: func(d time.Duration) <-chan time.Time {
+			delays <- d
+			return tick
+		},
+	}
+
+	ran := make(chan error)
+	go func() { ran <- ctrl.Run(context.Background()) }()
+
+	expect := func(reason string, delay time.Duration) {
+		t.Helper()
+		if stats := <-cycles; (stats.Reason != reason) {
+			t.Errorf("ERROR, cycle %d ran for %s, expected %s", stats.Cycle, stats.Reason, reason)
+		}
+		if d := <-delays; (d != delay) {
+			t.Errorf("ERROR, next cycle in %s, expected %s", d, delay)
+		}
+	}
+	expect(CycleInitial, time.Minute)
+
+	if err := ctrl.Run(context.Background()); (!errors.Is(err, ErrControllerRunning)) {
+		t.Errorf("ERROR, expected ErrControllerRunning, got %v", err)
+	}
+
+	trigger <- struct{}{}
+	expect(CycleManual, time.Minute)
+	mu.Lock()
+	desiredErr = errors.New("HSM is down")
+	mu.Unlock()
+	tick <- time.Now()
+	expect(CycleTimer, time.Second)
+	tick <- time.Now()
+	expect(CycleRetry, 2*time.Second)
+	mu.Lock()
+	desiredErr = nil
+	mu.Unlock()
+	tick <- time.Now()
+	expect(CycleRetry, time.Minute)
+
+	// A closed trigger doesn't run cycles, the timer still does.
+	close(trigger)
+	tick <- time.Now()
+	expect(CycleTimer, time.Minute)
+
+	ctrl.Stop()
+	if err := <-ran; (err != nil) {
+		t.Errorf("ERROR, Run() returned %v after Stop()", err)
+	}
+	ctrl.Stop()
+
+	// It can run again, until its context ends.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { ran <- ctrl.Run(ctx) }()
+	expect(CycleInitial, time.Minute)
+	cancel()
+	if err := <-ran; (!errors.Is(err, context.Canceled)) {
+		t.Errorf("ERROR, Run() returned %v after cancel, expected context.Canceled", err)
+	}
+}
+
+func TestFileBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.conf")
+	contents := "dhcp-host=a4:bf:01:00:00:01,10.252.1.11\n"
+	changes := 0
+	backend := &FileBackend{Path: path, OnChange: func(ctx context.Context) error {
+		changes++
+		return nil
+	}}
+	ctrl := &Controller{
+		Desired: DesiredFile(path, func(ctx context.Context, hsm Client, w io.Writer) error {
+			_, err := io.WriteString(w, contents)
+			return err
+		}),
+		Backend: backend,
+		Prune:   true,
+	}
+
+	stats, err := ctrl.RunOnce(context.Background())
+	written, _ := os.ReadFile(path)
+	if (err != nil || stats.Created != 1 || string(written) != contents || changes != 1) {
+		t.Fatalf("ERROR, RunOnce() returned %+v, error: %v, file '%s'", stats, err, written)
+	}
+	if info, _ := os.Stat(path); (info.Mode().Perm() != 0644) {
+		t.Errorf("ERROR, file mode is %s", info.Mode())
+	}
+
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || stats.Updated != 0 || changes != 1) {
+		t.Errorf("ERROR, unchanged RunOnce() returned %+v, error: %v", stats, err)
+	}
+
+	contents = "dhcp-host=a4:bf:01:00:00:01,10.252.1.12\n"
+	stats, err = ctrl.RunOnce(context.Background())
+	written, _ = os.ReadFile(path)
+	if (err != nil || stats.Updated != 1 || string(written) != contents || changes != 2) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v, file '%s'", stats, err, written)
+	}
+
+	ctrl.Desired = func(ctx context.Context, hsm Client) ([]StateItem, error) { return nil, nil }
+	if stats, err = ctrl.RunOnce(context.Background()); (err != nil || stats.Deleted != 1) {
+		t.Errorf("ERROR, RunOnce() returned %+v, error: %v", stats, err)
+	}
+	if _, err = os.Stat(path); (!os.IsNotExist(err)) {
+		t.Errorf("ERROR, expected the file to be removed, got %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if (len(entries) != 0) {
+		t.Errorf("ERROR, temporary files left behind: %v", entries)
+	}
+
+	if err = backend.Apply(context.Background(), StateDiff{Create: []StateItem{{path, 1}}}); (err == nil) {
+		t.Errorf("ERROR, expected an error for a non-string value")
+	}
+}
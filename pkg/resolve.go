@@ -0,0 +1,300 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+)
+
+// Interfaces that DHCP learns about before discovery has run have no
+// ComponentID. HSM usually knows who they belong to already: BMC MACs and
+// IPs are in RedfishEndpoints, and the NICs Redfish reports for systems and
+// managers are in ComponentEndpoints. The resolver matches unknown
+// interfaces against that data and proposes a ComponentID for each.
+
+// MatchConfidence says how much a proposed ComponentID can be trusted.
+type MatchConfidence int
+
+const (
+	// No proposal, either nothing matched or the matches disagree.
+	MatchNone MatchConfidence = iota
+	// Matched on IP address, but the endpoint's MAC is a different one.
+	MatchLow
+	// Matched on IP address, or on a NIC's permanent MAC address.
+	MatchMedium
+	// Matched on the MAC address Redfish reports for the component.
+	MatchHigh
+)
+
+func (confidence MatchConfidence) String() string {
+	switch confidence {
+	case MatchLow:
+		return "low"
+	case MatchMedium:
+		return "medium"
+	case MatchHigh:
+		return "high"
+	}
+	return "none"
+}
+
+// ComponentMatch is the resolver's proposal for one interface.
+type ComponentMatch struct {
+	Interface sm.CompEthInterfaceV2
+
+	// Proposed ComponentID and its HMS type, empty for MatchNone.
+	CompID string
+	Type   string
+
+	Confidence MatchConfidence
+	Reason     string
+}
+
+func (helper *DNSDHCPHelper) GetRedfishEndpoints() (endpoints []sm.RedfishEndpoint, err error) {
+	return helper.GetRedfishEndpointsCtx(context.Background())
+}
+
+// GetRedfishEndpointsCtx returns every RedfishEndpoint in HSM.
+func (helper *DNSDHCPHelper) GetRedfishEndpointsCtx(ctx context.Context) (endpoints []sm.RedfishEndpoint,
+	err error) {
+	var endpointArray sm.RedfishEndpointArray
+	if err = helper.getJSON(ctx, "/Inventory/RedfishEndpoints", &endpointArray); err != nil {
+		return
+	}
+	for _, endpoint := range endpointArray.RedfishEndpoints {
+		if endpoint != nil {
+			endpoints = append(endpoints, *endpoint)
+		}
+	}
+	return
+}
+
+func (helper *DNSDHCPHelper) GetComponentEndpoints() (endpoints []sm.ComponentEndpoint, err error) {
+	return helper.GetComponentEndpointsCtx(context.Background())
+}
+
+// GetComponentEndpointsCtx returns every ComponentEndpoint in HSM.
+func (helper *DNSDHCPHelper) GetComponentEndpointsCtx(ctx context.Context) (endpoints []sm.ComponentEndpoint,
+	err error) {
+	var endpointArray sm.ComponentEndpointArray
+	if err = helper.getJSON(ctx, "/Inventory/ComponentEndpoints", &endpointArray); err != nil {
+		return
+	}
+	for _, endpoint := range endpointArray.ComponentEndpoints {
+		if endpoint != nil {
+			endpoints = append(endpoints, *endpoint)
+		}
+	}
+	return
+}
+
+// matchCandidate is one piece of evidence for a ComponentID.
+type matchCandidate struct {
+	compID     string
+	compType   string
+	confidence MatchConfidence
+	reason     string
+
+	// For IP address matches, the HSM ID of the endpoint's own MAC if it
+	// has one.
+	endpointMAC string
+}
+
+// hsmID returns the HSM ID of a MAC address, or "" if it isn't one.
+func hsmID(macAddr string) string {
+	m, err := mac.Parse(macAddr)
+	if err != nil {
+		return ""
+	}
+	return m.HSMID()
+}
+
+// ResolveComponents matches each of ethInterfaces against the Redfish
+// endpoint data and returns a ComponentMatch per interface, in the same
+// order. When the best matches point at different components the interface
+// is left unresolved (MatchNone) and Reason lists them.
+func ResolveComponents(ethInterfaces []sm.CompEthInterfaceV2, redfishEndpoints []sm.RedfishEndpoint,
+	componentEndpoints []sm.ComponentEndpoint) []ComponentMatch {
+	byMAC := make(map[string][]matchCandidate)
+	byIP := make(map[string][]matchCandidate)
+	addMAC := func(macAddr string, candidate matchCandidate) {
+		if id := hsmID(macAddr); id != "" {
+			byMAC[id] = append(byMAC[id], candidate)
+		}
+	}
+
+	for _, endpoint := range redfishEndpoints {
+		addMAC(endpoint.MACAddr, matchCandidate{endpoint.ID, endpoint.Type, MatchHigh,
+			fmt.Sprintf("MAC matches RedfishEndpoint %s (%s)", endpoint.ID, endpoint.FQDN), ""})
+		if endpoint.IPAddr != "" {
+			byIP[endpoint.IPAddr] = append(byIP[endpoint.IPAddr], matchCandidate{endpoint.ID, endpoint.Type,
+				MatchMedium, fmt.Sprintf("IP address matches RedfishEndpoint %s (%s)", endpoint.ID,
+					endpoint.FQDN), hsmID(endpoint.MACAddr)})
+		}
+	}
+	for _, endpoint := range componentEndpoints {
+		addMAC(endpoint.MACAddr, matchCandidate{endpoint.ID, endpoint.Type, MatchHigh,
+			fmt.Sprintf("MAC matches ComponentEndpoint %s", endpoint.ID), ""})
+
+		var nics []*rf.EthernetNICInfo
+		if endpoint.RedfishSystemInfo != nil {
+			nics = append(nics, endpoint.RedfishSystemInfo.EthNICInfo...)
+		}
+		if endpoint.RedfishManagerInfo != nil {
+			nics = append(nics, endpoint.RedfishManagerInfo.EthNICInfo...)
+		}
+		for _, nic := range nics {
+			if nic == nil {
+				continue
+			}
+			addMAC(nic.MACAddress, matchCandidate{endpoint.ID, endpoint.Type, MatchHigh,
+				fmt.Sprintf("MAC matches NIC %s of ComponentEndpoint %s", nic.RedfishId, endpoint.ID), ""})
+			if hsmID(nic.PermanentMACAddress) != hsmID(nic.MACAddress) {
+				addMAC(nic.PermanentMACAddress, matchCandidate{endpoint.ID, endpoint.Type, MatchMedium,
+					fmt.Sprintf("MAC matches the permanent MAC of NIC %s of ComponentEndpoint %s",
+						nic.RedfishId, endpoint.ID), ""})
+			}
+		}
+	}
+
+	matches := make([]ComponentMatch, 0, len(ethInterfaces))
+	for _, ethInterface := range ethInterfaces {
+		id := hsmID(ethInterface.MACAddr)
+		if id == "" {
+			id = hsmID(ethInterface.ID)
+		}
+		candidates := append([]matchCandidate(nil), byMAC[id]...)
+		for _, ipm := range ethInterface.IPAddrs {
+			for _, candidate := range byIP[ipm.IPAddr] {
+				if candidate.endpointMAC != "" && candidate.endpointMAC != id {
+					// The address has moved to a different NIC.
+					candidate.confidence = MatchLow
+					candidate.reason += ", but the endpoint's MAC differs"
+				}
+				candidates = append(candidates, candidate)
+			}
+		}
+		matches = append(matches, bestMatch(ethInterface, candidates))
+	}
+	return matches
+}
+
+// bestMatch picks the most confident candidate, as long as no other
+// component is as well supported.
+func bestMatch(ethInterface sm.CompEthInterfaceV2, candidates []matchCandidate) ComponentMatch {
+	match := ComponentMatch{Interface: ethInterface, Reason: "no Redfish endpoint data matches"}
+	if len(candidates) == 0 {
+		return match
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].confidence > candidates[j].confidence
+	})
+	best := candidates[0]
+	var rivals []string
+	for _, candidate := range candidates[1:] {
+		if candidate.confidence == best.confidence &&
+			xnametypes.NormalizeHMSCompID(candidate.compID) != xnametypes.NormalizeHMSCompID(best.compID) {
+			rivals = append(rivals, candidate.compID)
+		}
+	}
+	if len(rivals) > 0 {
+		match.Reason = fmt.Sprintf("ambiguous, %s matches %s and %s", best.confidence, best.compID,
+			strings.Join(rivals, ", "))
+		return match
+	}
+
+	match.CompID = xnametypes.NormalizeHMSCompID(best.compID)
+	match.Type = best.compType
+	if match.Type == "" {
+		match.Type = xnametypes.GetHMSTypeString(match.CompID)
+	}
+	match.Confidence = best.confidence
+	match.Reason = best.reason
+	return match
+}
+
+func (helper *DNSDHCPHelper) ResolveUnknownComponents() (matches []ComponentMatch, err error) {
+	return helper.ResolveUnknownComponentsCtx(context.Background())
+}
+
+// ResolveUnknownComponentsCtx proposes a ComponentID for every interface
+// GetUnknownComponents() returns, see ResolveComponents(). Nothing is
+// changed in HSM; use ApplyComponentMatches() for that.
+func (helper *DNSDHCPHelper) ResolveUnknownComponentsCtx(ctx context.Context) (matches []ComponentMatch,
+	err error) {
+	unknown, err := helper.GetUnknownComponentsCtx(ctx)
+	if err != nil || len(unknown) == 0 {
+		return
+	}
+	redfishEndpoints, err := helper.GetRedfishEndpointsCtx(ctx)
+	if err != nil {
+		return
+	}
+	componentEndpoints, err := helper.GetComponentEndpointsCtx(ctx)
+	if err != nil {
+		return
+	}
+	return ResolveComponents(unknown, redfishEndpoints, componentEndpoints), nil
+}
+
+func (helper *DNSDHCPHelper) ApplyComponentMatches(matches []ComponentMatch, minConfidence MatchConfidence) (
+	applied []ComponentMatch, err error) {
+	return helper.ApplyComponentMatchesCtx(context.Background(), matches, minConfidence)
+}
+
+// ApplyComponentMatchesCtx sets the proposed ComponentID of every match at
+// least as confident as minConfidence (and never MatchNone), patching only
+// that field, and returns the matches applied. HSM derives the interface
+// Type from the ComponentID. If a patch fails, the matches applied so far
+// are returned along with the error.
+func (helper *DNSDHCPHelper) ApplyComponentMatchesCtx(ctx context.Context, matches []ComponentMatch,
+	minConfidence MatchConfidence) (applied []ComponentMatch, err error) {
+	if minConfidence < MatchLow {
+		minConfidence = MatchLow
+	}
+	for _, match := range matches {
+		if match.Confidence < minConfidence || match.CompID == "" {
+			continue
+		}
+		compID := match.CompID
+		updated, patchErr := helper.PatchEthernetInterfaceFieldsCtx(ctx, match.Interface.ID,
+			sm.CompEthInterfaceV2Patch{CompID: &compID})
+		if patchErr != nil {
+			err = fmt.Errorf("failed to set component of interface %s to %s: %w", match.Interface.ID, compID,
+				patchErr)
+			return
+		}
+		match.Interface = updated
+		applied = append(applied, match)
+	}
+	return
+}
@@ -0,0 +1,147 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func testRedfishEndpoints() []sm.RedfishEndpoint {
+	var endpoints []sm.RedfishEndpoint
+	for _, epd := range []rf.RedfishEPDescription{
+		{ID: "x3000c0s1b0", Type: "NodeBMC", FQDN: "x3000c0s1b0.hmn", MACAddr: "a4:bf:01:00:00:01",
+			IPAddr: "10.254.1.1"},
+		{ID: "x3000c0s2b0", Type: "NodeBMC", FQDN: "x3000c0s2b0.hmn", IPAddr: "10.254.1.2"},
+		{ID: "x3000c0s3b0", Type: "NodeBMC", FQDN: "x3000c0s3b0.hmn", MACAddr: "a4:bf:01:00:00:03",
+			IPAddr: "10.254.1.3"},
+		{ID: "x3000c0s4b0", Type: "NodeBMC", MACAddr: "a4:bf:01:00:00:44"},
+		{ID: "x3000c0s5b0", Type: "NodeBMC", MACAddr: "A4BF01000044"},
+	} {
+		endpoints = append(endpoints, sm.RedfishEndpoint{RedfishEPDescription: epd})
+	}
+	return endpoints
+}
+
+func testComponentEndpoints() []sm.ComponentEndpoint {
+	node := sm.ComponentEndpoint{
+		ComponentDescription: rf.ComponentDescription{ID: "x3000c0s1b0n0", Type: "Node"},
+		RedfishSystemInfo: &rf.ComponentSystemInfo{EthNICInfo: []*rf.EthernetNICInfo{
+			{RedfishId: "1", MACAddress: "b4:2e:99:00:00:01"},
+			{RedfishId: "2", MACAddress: "b4:2e:99:00:00:02", PermanentMACAddress: "b4:2e:99:00:00:f2"},
+		}},
+	}
+	bmc := sm.ComponentEndpoint{
+		ComponentDescription: rf.ComponentDescription{ID: "x3000c0s6b0", Type: "NodeBMC",
+			MACAddr: "a4:bf:01:00:00:06"},
+	}
+	return []sm.ComponentEndpoint{node, bmc}
+}
+
+func TestResolveComponents(t *testing.T) {
+	ethInterfaces := []sm.CompEthInterfaceV2{
+		{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01"},
+		{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02", IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.2"}}},
+		{ID: "a4bf010000ff", MACAddr: "a4:bf:01:00:00:ff", IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.3"}}},
+		{ID: "b42e99000002", MACAddr: "b4:2e:99:00:00:02"},
+		{ID: "b42e990000f2", MACAddr: "b4:2e:99:00:00:f2"},
+		{ID: "a4bf01000006", MACAddr: "a4:bf:01:00:00:06"},
+		{ID: "a4bf01000044", MACAddr: "a4:bf:01:00:00:44"},
+		{ID: "a4bf01000099", MACAddr: "a4:bf:01:00:00:99"},
+	}
+	exp := []struct {
+		compID     string
+		compType   string
+		confidence MatchConfidence
+		reason     string
+	}{
+		{"x3000c0s1b0", "NodeBMC", MatchHigh, "RedfishEndpoint x3000c0s1b0"},
+		{"x3000c0s2b0", "NodeBMC", MatchMedium, "IP address"},
+		{"x3000c0s3b0", "NodeBMC", MatchLow, "MAC differs"},
+		{"x3000c0s1b0n0", "Node", MatchHigh, "NIC 2"},
+		{"x3000c0s1b0n0", "Node", MatchMedium, "permanent MAC"},
+		{"x3000c0s6b0", "NodeBMC", MatchHigh, "ComponentEndpoint x3000c0s6b0"},
+		{"", "", MatchNone, "ambiguous"},
+		{"", "", MatchNone, "no Redfish"},
+	}
+
+	matches := ResolveComponents(ethInterfaces, testRedfishEndpoints(), testComponentEndpoints())
+	if (len(matches) != len(exp)) {
+		t.Fatalf("ERROR, expected %d matches, got %d", len(exp), len(matches))
+	}
+	for ix, match := range matches {
+		if (match.Interface.ID != ethInterfaces[ix].ID) {
+			t.Errorf("ERROR, match %d is for interface %s", ix, match.Interface.ID)
+		}
+		if (match.CompID != exp[ix].compID || match.Type != exp[ix].compType ||
+			match.Confidence != exp[ix].confidence || !strings.Contains(match.Reason, exp[ix].reason)) {
+			t.Errorf("ERROR, interface %s matched %s (%s) with %s confidence, '%s'", match.Interface.ID,
+				match.CompID, match.Type, match.Confidence, match.Reason)
+		}
+	}
+}
+
+func TestGetEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload interface{}
+		switch req.URL.Path {
+		case "/hsm/v2/Inventory/RedfishEndpoints":
+			var endpoints sm.RedfishEndpointArray
+			for _, endpoint := range testRedfishEndpoints() {
+				endpoint := endpoint
+				endpoints.RedfishEndpoints = append(endpoints.RedfishEndpoints, &endpoint)
+			}
+			payload = endpoints
+		case "/hsm/v2/Inventory/ComponentEndpoints":
+			var endpoints sm.ComponentEndpointArray
+			for _, endpoint := range testComponentEndpoints() {
+				endpoint := endpoint
+				endpoints.ComponentEndpoints = append(endpoints.ComponentEndpoints, &endpoint)
+			}
+			payload = endpoints
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		ba, _ := json.Marshal(payload)
+		w.WriteHeader(http.StatusOK)
+		w.Write(ba)
+	}))
+	defer srv.Close()
+
+	hlp := NewDHCPDNSHelperInstance(srv.URL, nil, expSvcName)
+	rfEndpoints, err := hlp.GetRedfishEndpoints()
+	if (err != nil || len(rfEndpoints) != 5 || rfEndpoints[0].MACAddr != "a4:bf:01:00:00:01") {
+		t.Errorf("ERROR, GetRedfishEndpoints() returned %d endpoints, error: %v", len(rfEndpoints), err)
+	}
+	compEndpoints, err := hlp.GetComponentEndpoints()
+	if (err != nil || len(compEndpoints) != 2 || compEndpoints[0].RedfishSystemInfo == nil ||
+		len(compEndpoints[0].RedfishSystemInfo.EthNICInfo) != 2) {
+		t.Errorf("ERROR, GetComponentEndpoints() returned %v, error: %v", compEndpoints, err)
+	}
+}
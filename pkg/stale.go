@@ -0,0 +1,324 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Interfaces that haven't been updated in months and have no component, or
+// whose component is gone from HSM, are usually NICs that were swapped out.
+// AnalyzeInterfaces() classifies interfaces so they can be reviewed, and
+// PruneStaleInterfaces() deletes the ones selected.
+
+// InterfaceCategory classifies an interface by its ComponentID.
+type InterfaceCategory string
+
+const (
+	// The interface has no ComponentID.
+	CategoryNoComponent InterfaceCategory = "no-component"
+	// The interface's ComponentID isn't a component in HSM.
+	CategoryMissingComponent InterfaceCategory = "missing-component"
+	// The interface's component exists.
+	CategoryComponentPresent InterfaceCategory = "component-present"
+)
+
+// Age buckets of the report, by time since LastUpdate.
+const (
+	AgeUnknown = "unknown"
+	AgeWeek    = "<7d"
+	AgeMonth   = "7d-30d"
+	AgeQuarter = "30d-90d"
+	AgeOlder   = ">90d"
+)
+
+// InterfaceAge is one interface in a StaleReport.
+type InterfaceAge struct {
+	ID         string            `json:"ID"`
+	MACAddr    string            `json:"MACAddress"`
+	CompID     string            `json:"ComponentID,omitempty"`
+	IPAddrs    []string          `json:"IPAddresses,omitempty"`
+	LastUpdate string            `json:"LastUpdate"`
+	AgeDays    int               `json:"AgeDays"`
+	AgeBucket  string            `json:"AgeBucket"`
+	Category   InterfaceCategory `json:"Category"`
+
+	// Stale is true if the interface has no live component and is at least
+	// as old as the report's threshold.
+	Stale bool `json:"Stale"`
+
+	age time.Duration
+}
+
+// StaleReport is the result of AnalyzeInterfaces().
+type StaleReport struct {
+	GeneratedAt time.Time      `json:"GeneratedAt"`
+	MinAgeDays  int            `json:"MinAgeDays"`
+	Total       int            `json:"Total"`
+	StaleCount  int            `json:"StaleCount"`
+	ByCategory  map[string]int `json:"ByCategory"`
+	ByAge       map[string]int `json:"ByAge"`
+	Interfaces  []InterfaceAge `json:"Interfaces"`
+}
+
+func ageBucket(age time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case age < 7*day:
+		return AgeWeek
+	case age < 30*day:
+		return AgeMonth
+	case age < 90*day:
+		return AgeQuarter
+	}
+	return AgeOlder
+}
+
+// AnalyzeInterfaces classifies ethInterfaces by age at now and by whether
+// their component is one of componentIDs. Interfaces without a live
+// component that are at least minAge old are marked Stale; an interface
+// whose LastUpdate can't be parsed is never stale. The report lists the
+// oldest interfaces first.
+func AnalyzeInterfaces(ethInterfaces []sm.CompEthInterfaceV2, componentIDs []string, now time.Time,
+	minAge time.Duration) *StaleReport {
+	components := make(map[string]bool, len(componentIDs))
+	for _, id := range componentIDs {
+		components[xnametypes.NormalizeHMSCompID(id)] = true
+	}
+
+	report := &StaleReport{
+		GeneratedAt: now.UTC(),
+		MinAgeDays:  int(minAge / (24 * time.Hour)),
+		Total:       len(ethInterfaces),
+		ByCategory:  make(map[string]int),
+		ByAge:       make(map[string]int),
+		Interfaces:  make([]InterfaceAge, 0, len(ethInterfaces)),
+	}
+	for _, ethInterface := range ethInterfaces {
+		entry := InterfaceAge{
+			ID:         ethInterface.ID,
+			MACAddr:    ethInterface.MACAddr,
+			CompID:     ethInterface.CompID,
+			LastUpdate: ethInterface.LastUpdate,
+			AgeBucket:  AgeUnknown,
+		}
+		for _, ipm := range ethInterface.IPAddrs {
+			entry.IPAddrs = append(entry.IPAddrs, ipm.IPAddr)
+		}
+
+		switch {
+		case ethInterface.CompID == "":
+			entry.Category = CategoryNoComponent
+		case !components[xnametypes.NormalizeHMSCompID(ethInterface.CompID)]:
+			entry.Category = CategoryMissingComponent
+		default:
+			entry.Category = CategoryComponentPresent
+		}
+
+		if lastUpdate, err := time.Parse(time.RFC3339, ethInterface.LastUpdate); err == nil {
+			entry.age = now.Sub(lastUpdate)
+			entry.AgeDays = int(entry.age / (24 * time.Hour))
+			entry.AgeBucket = ageBucket(entry.age)
+			entry.Stale = entry.Category != CategoryComponentPresent && entry.age >= minAge
+		} else {
+			entry.age = -1
+		}
+
+		report.ByCategory[string(entry.Category)]++
+		report.ByAge[entry.AgeBucket]++
+		if entry.Stale {
+			report.StaleCount++
+		}
+		report.Interfaces = append(report.Interfaces, entry)
+	}
+
+	sort.SliceStable(report.Interfaces, func(i, j int) bool {
+		if report.Interfaces[i].age != report.Interfaces[j].age {
+			return report.Interfaces[i].age > report.Interfaces[j].age
+		}
+		return report.Interfaces[i].ID < report.Interfaces[j].ID
+	})
+	return report
+}
+
+// WriteJSON writes the report as indented JSON.
+func (report *StaleReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteTable writes the report as a human readable table followed by a
+// summary. With staleOnly set only stale interfaces are listed.
+func (report *StaleReport) WriteTable(w io.Writer, staleOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tMAC\tCOMPONENT\tCATEGORY\tLAST UPDATE\tAGE\tSTALE\tIP ADDRESSES")
+	for _, entry := range report.Interfaces {
+		if staleOnly && !entry.Stale {
+			continue
+		}
+		age := AgeUnknown
+		if entry.AgeBucket != AgeUnknown {
+			age = fmt.Sprintf("%dd", entry.AgeDays)
+		}
+		stale := ""
+		if entry.Stale {
+			stale = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.MACAddr, dashIfEmpty(entry.CompID),
+			entry.Category, dashIfEmpty(entry.LastUpdate), age, stale, strings.Join(entry.IPAddrs, ","))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d interfaces, %d stale (no live component, at least %d days old)\n",
+		report.Total, report.StaleCount, report.MinAgeDays)
+	if err != nil {
+		return err
+	}
+	for _, category := range []InterfaceCategory{CategoryNoComponent, CategoryMissingComponent,
+		CategoryComponentPresent} {
+		fmt.Fprintf(w, "  %-18s %d\n", category+":", report.ByCategory[string(category)])
+	}
+	for _, bucket := range []string{AgeWeek, AgeMonth, AgeQuarter, AgeOlder, AgeUnknown} {
+		fmt.Fprintf(w, "  %-18s %d\n", bucket+":", report.ByAge[bucket])
+	}
+	return nil
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (helper *DNSDHCPHelper) AnalyzeStaleInterfaces(minAge time.Duration) (report *StaleReport, err error) {
+	return helper.AnalyzeStaleInterfacesCtx(context.Background(), minAge)
+}
+
+// AnalyzeStaleInterfacesCtx runs AnalyzeInterfaces() over every interface
+// and component in HSM.
+func (helper *DNSDHCPHelper) AnalyzeStaleInterfacesCtx(ctx context.Context, minAge time.Duration) (
+	report *StaleReport, err error) {
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+	components, err := helper.GetComponentsCtx(ctx)
+	if err != nil {
+		return
+	}
+	componentIDs := make([]string, 0, len(components))
+	for _, component := range components {
+		componentIDs = append(componentIDs, component.ID)
+	}
+	return AnalyzeInterfaces(ethInterfaces, componentIDs, time.Now(), minAge), nil
+}
+
+// PruneOptions selects the interfaces PruneStaleInterfaces() deletes.
+type PruneOptions struct {
+	// Only interfaces at least this old are deleted. It must be set.
+	MinAge time.Duration
+
+	// Categories to delete; empty means CategoryNoComponent and
+	// CategoryMissingComponent. Interfaces with a live component are never
+	// deleted.
+	Categories []InterfaceCategory
+
+	// Nothing is deleted unless Execute is set.
+	Execute bool
+}
+
+func (helper *DNSDHCPHelper) PruneStaleInterfaces(report *StaleReport, opts PruneOptions) (
+	pruned []InterfaceAge, err error) {
+	return helper.PruneStaleInterfacesCtx(context.Background(), report, opts)
+}
+
+// PruneStaleInterfacesCtx deletes the interfaces in report selected by
+// opts and returns them. By default it is a dry run: nothing is deleted and
+// the interfaces that would have been are returned.
+//
+// Each interface is fetched again before it is deleted, and skipped if it
+// is already gone from HSM or its LastUpdate or ComponentID changed since
+// the report, e.g. because its lease was renewed. If a delete fails, the
+// interfaces deleted so far are returned along with the error.
+func (helper *DNSDHCPHelper) PruneStaleInterfacesCtx(ctx context.Context, report *StaleReport,
+	opts PruneOptions) (pruned []InterfaceAge, err error) {
+	if report == nil {
+		err = fmt.Errorf("no stale interface report to prune from")
+		return
+	}
+	if opts.MinAge <= 0 {
+		err = fmt.Errorf("refusing to prune interfaces without a minimum age")
+		return
+	}
+	categories := map[InterfaceCategory]bool{CategoryNoComponent: true, CategoryMissingComponent: true}
+	if len(opts.Categories) > 0 {
+		categories = make(map[InterfaceCategory]bool)
+		for _, category := range opts.Categories {
+			categories[category] = category != CategoryComponentPresent
+		}
+	}
+
+	for _, entry := range report.Interfaces {
+		// Ages are taken at report time, so a report read back from JSON
+		// selects the same interfaces.
+		lastUpdate, parseErr := time.Parse(time.RFC3339, entry.LastUpdate)
+		if !categories[entry.Category] || parseErr != nil || report.GeneratedAt.Sub(lastUpdate) < opts.MinAge {
+			continue
+		}
+		if opts.Execute {
+			cur, getErr := helper.GetEthernetInterfaceCtx(ctx, entry.ID)
+			if IsHSMErrorClass(getErr, HSMErrClassNotFound) {
+				continue
+			}
+			if getErr != nil {
+				err = getErr
+				return
+			}
+			if cur.LastUpdate != entry.LastUpdate || !strings.EqualFold(cur.CompID, entry.CompID) {
+				continue
+			}
+			if delErr := helper.DeleteEthernetInterfaceCtx(ctx, entry.ID); delErr != nil {
+				if IsHSMErrorClass(delErr, HSMErrClassNotFound) {
+					continue
+				}
+				err = delErr
+				return
+			}
+		}
+		pruned = append(pruned, entry)
+	}
+	return
+}
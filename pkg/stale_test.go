@@ -0,0 +1,154 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var staleNow = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+func staleInterfaces() []sm.CompEthInterfaceV2 {
+	return []sm.CompEthInterfaceV2{
+		{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01", LastUpdate: "2026-10-15T00:00:00Z",
+			CompID: "x3000c0s1b0n0", IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.1"}}},
+		{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02", LastUpdate: "2026-10-01T00:00:00Z"},
+		{ID: "a4bf01000003", MACAddr: "a4:bf:01:00:00:03", LastUpdate: "2026-06-01T00:00:00Z",
+			CompID: "x3000c0s3b0n0"},
+		{ID: "a4bf01000004", MACAddr: "a4:bf:01:00:00:04", LastUpdate: "2026-01-01T00:00:00Z"},
+		{ID: "a4bf01000005", MACAddr: "a4:bf:01:00:00:05", LastUpdate: "2025-01-01T00:00:00Z",
+			CompID: "x3000c0s05b0n0"},
+		{ID: "a4bf01000006", MACAddr: "a4:bf:01:00:00:06"},
+	}
+}
+
+func TestAnalyzeInterfaces(t *testing.T) {
+	report := AnalyzeInterfaces(staleInterfaces(), []string{"x3000c0s1b0n0", "x3000c0s5b0n0"}, staleNow,
+		90*24*time.Hour)
+
+	expOrder := []string{"a4bf01000005", "a4bf01000004", "a4bf01000003", "a4bf01000002", "a4bf01000001",
+		"a4bf01000006"}
+	expStale := map[string]bool{"a4bf01000004": true, "a4bf01000003": true}
+	for ix, entry := range report.Interfaces {
+		if (entry.ID != expOrder[ix]) {
+			t.Errorf("ERROR, report entry %d is %s, expected %s", ix, entry.ID, expOrder[ix])
+		}
+		if (entry.Stale != expStale[entry.ID]) {
+			t.Errorf("ERROR, interface %s stale: %v", entry.ID, entry.Stale)
+		}
+	}
+	if (report.Total != 6 || report.StaleCount != 2 || report.MinAgeDays != 90) {
+		t.Errorf("ERROR, report totals %d/%d/%d", report.Total, report.StaleCount, report.MinAgeDays)
+	}
+
+	expCategories := map[string]int{"no-component": 3, "missing-component": 1, "component-present": 2}
+	for category, count := range expCategories {
+		if (report.ByCategory[category] != count) {
+			t.Errorf("ERROR, %d interfaces in category %s, expected %d", report.ByCategory[category],
+				category, count)
+		}
+	}
+	expAges := map[string]int{AgeWeek: 1, AgeMonth: 1, AgeQuarter: 0, AgeOlder: 3, AgeUnknown: 1}
+	for bucket, count := range expAges {
+		if (report.ByAge[bucket] != count) {
+			t.Errorf("ERROR, %d interfaces aged %s, expected %d", report.ByAge[bucket], bucket, count)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); (err != nil) {
+		t.Errorf("ERROR, WriteJSON() error: %v", err)
+	}
+	var decoded StaleReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); (err != nil || decoded.StaleCount != 2 ||
+		len(decoded.Interfaces) != 6 || decoded.Interfaces[3].AgeDays != 15) {
+		t.Errorf("ERROR, JSON report didn't round trip: %v\n%s", err, buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteTable(&buf, true); (err != nil) {
+		t.Errorf("ERROR, WriteTable() error: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if (!strings.HasPrefix(lines[0], "ID ") || !strings.Contains(lines[1], "a4bf01000004") ||
+		!strings.Contains(lines[2], "x3000c0s3b0n0") || lines[3] != "" ||
+		!strings.Contains(buf.String(), "6 interfaces, 2 stale")) {
+		t.Errorf("ERROR, unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestPruneStaleInterfaces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleDelete))
+	defer srv.Close()
+
+	hlp := New(srv.URL)
+	delComps = staleInterfaces()
+	report := AnalyzeInterfaces(delComps, []string{"x3000c0s1b0n0"}, staleNow, 30*24*time.Hour)
+
+	_, err := hlp.PruneStaleInterfaces(report, PruneOptions{})
+	if (err == nil) {
+		t.Errorf("ERROR, PruneStaleInterfaces() ran without a minimum age.")
+	}
+	if _, err = hlp.PruneStaleInterfaces(nil, PruneOptions{MinAge: time.Hour}); (err == nil) {
+		t.Errorf("ERROR, PruneStaleInterfaces() ran without a report.")
+	}
+
+	pruned, err := hlp.PruneStaleInterfaces(report, PruneOptions{MinAge: 100 * 24 * time.Hour})
+	if (err != nil || len(pruned) != 3 || len(delComps) != 6) {
+		t.Errorf("ERROR, dry run pruned %d interfaces, %d left, error: %v", len(pruned), len(delComps), err)
+	}
+
+	// Via JSON, as a report reviewed before pruning would be.
+	var buf bytes.Buffer
+	report.WriteJSON(&buf)
+	var reviewed StaleReport
+	json.Unmarshal(buf.Bytes(), &reviewed)
+
+	pruned, err = hlp.PruneStaleInterfaces(&reviewed, PruneOptions{MinAge: 100 * 24 * time.Hour,
+		Categories: []InterfaceCategory{CategoryNoComponent, CategoryComponentPresent}, Execute: true})
+	if (err != nil || len(pruned) != 1 || pruned[0].ID != "a4bf01000004") {
+		t.Errorf("ERROR, PruneStaleInterfaces() pruned %v, error: %v", pruned, err)
+	}
+	if (len(delComps) != 5) {
+		t.Errorf("ERROR, %d interfaces left, expected 5", len(delComps))
+	}
+
+	// Interfaces updated since the report, and those already deleted, are
+	// skipped.
+	for ix := range(delComps) {
+		if (delComps[ix].ID == "a4bf01000003") {
+			delComps[ix].LastUpdate = "2026-10-16T00:00:00Z"
+		}
+	}
+	pruned, err = hlp.PruneStaleInterfaces(report, PruneOptions{MinAge: 100 * 24 * time.Hour, Execute: true})
+	if (err != nil || len(pruned) != 1 || pruned[0].ID != "a4bf01000005" || len(delComps) != 4) {
+		t.Errorf("ERROR, PruneStaleInterfaces() pruned %v, %d left, error: %v", pruned, len(delComps), err)
+	}
+}
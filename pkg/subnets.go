@@ -0,0 +1,373 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-dns-dhcp/pkg/mac"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// DHCP server configs are generated from HSM's interfaces and a list of
+// subnets. A SubnetTable places each interface's addresses in their subnets
+// and validates them the same way for every config format, so an interface
+// left out of one config is left out of all of them, for the same reason.
+
+// Reasons an interface address was left out of generated config.
+const (
+	UnplacedInvalidMAC      = "invalid-mac"
+	UnplacedInvalidIP       = "invalid-ip"
+	UnplacedNoSubnet        = "no-subnet"
+	UnplacedNetworkMismatch = "network-mismatch"
+	UnplacedDuplicateIP     = "duplicate-ip"
+	UnplacedDuplicateMAC    = "duplicate-mac"
+)
+
+// DHCPOption is one DHCP option. The JSON form is Kea's option-data.
+type DHCPOption struct {
+	Name       string `json:"name,omitempty"`
+	Code       int    `json:"code,omitempty"`
+	Space      string `json:"space,omitempty"`
+	Data       string `json:"data"`
+	AlwaysSend bool   `json:"always-send,omitempty"`
+}
+
+// Subnet is a DHCP subnet.
+type Subnet struct {
+	// Server subnet ID, unique and non-zero. Kea ties leases and
+	// reservations to it, so it must not change between runs.
+	ID   int    `json:"ID"`
+	CIDR string `json:"CIDR"`
+	// Optional. Addresses placed in the subnet get this network; those
+	// that already name a different one are refused.
+	Network string `json:"Network,omitempty"`
+	// Subnet-wide options.
+	Options []DHCPOption `json:"Options,omitempty"`
+
+	prefix netip.Prefix
+}
+
+// Prefix returns the subnet's parsed CIDR.
+func (subnet *Subnet) Prefix() netip.Prefix {
+	return subnet.prefix
+}
+
+// Placement is one interface address placed in a subnet.
+type Placement struct {
+	Interface sm.CompEthInterfaceV2
+	Subnet    *Subnet
+	MAC       mac.MAC
+	IPAddr    netip.Addr
+	// The subnet's Network, else the address's own.
+	Network string
+}
+
+// Unplaced is an interface address that was left out, and why.
+type Unplaced struct {
+	ID      string `json:"ID"`
+	MACAddr string `json:"MACAddress"`
+	CompID  string `json:"ComponentID,omitempty"`
+	IPAddr  string `json:"IPAddress,omitempty"`
+	Reason  string `json:"Reason"`
+	Detail  string `json:"Detail"`
+}
+
+// SubnetTable finds the subnet of an IP address, the most specific one if
+// subnets overlap.
+type SubnetTable struct {
+	subnets []*Subnet
+}
+
+// NewSubnetTable validates subnets and builds a table from them. IDs must
+// be unique and non-zero, and no CIDR may appear twice.
+func NewSubnetTable(subnets []Subnet) (*SubnetTable, error) {
+	table := &SubnetTable{}
+	ids := make(map[int]bool)
+	prefixes := make(map[netip.Prefix]bool)
+	for _, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(subnet.CIDR))
+		if err != nil {
+			return nil, fmt.Errorf("subnet %d: invalid CIDR '%s': %w", subnet.ID, subnet.CIDR, err)
+		}
+		prefix = prefix.Masked()
+		switch {
+		case subnet.ID <= 0:
+			return nil, fmt.Errorf("subnet %s: ID must be positive", prefix)
+		case ids[subnet.ID]:
+			return nil, fmt.Errorf("subnet %s: ID %d is used twice", prefix, subnet.ID)
+		case prefixes[prefix]:
+			return nil, fmt.Errorf("subnet %d: CIDR %s is used twice", subnet.ID, prefix)
+		}
+		ids[subnet.ID] = true
+		prefixes[prefix] = true
+
+		subnet := subnet
+		subnet.CIDR = prefix.String()
+		subnet.prefix = prefix
+		table.subnets = append(table.subnets, &subnet)
+	}
+	sort.SliceStable(table.subnets, func(i, j int) bool {
+		return table.subnets[i].ID < table.subnets[j].ID
+	})
+	return table, nil
+}
+
+// Subnets returns the table's subnets, in ID order.
+func (table *SubnetTable) Subnets() []*Subnet {
+	return table.subnets
+}
+
+// Lookup returns the most specific subnet containing addr.
+func (table *SubnetTable) Lookup(addr netip.Addr) (*Subnet, bool) {
+	var found *Subnet
+	addr = addr.Unmap().WithZone("")
+	for _, subnet := range table.subnets {
+		if subnet.prefix.Contains(addr) && (found == nil || subnet.prefix.Bits() > found.prefix.Bits()) {
+			found = subnet
+		}
+	}
+	return found, found != nil
+}
+
+// hasFamily is true if the table has a subnet of addr's IP version.
+func (table *SubnetTable) hasFamily(addr netip.Addr) bool {
+	for _, subnet := range table.subnets {
+		if subnet.prefix.Addr().Is4() == addr.Is4() {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedAddrKind returns "network" or "broadcast" if addr is that
+// address of prefix, which no host can have, and "" otherwise. IPv4 /31
+// and /32 subnets have neither (RFC 3021); IPv6 ones have no broadcast
+// address, and the network address is the subnet-router anycast address.
+func reservedAddrKind(prefix netip.Prefix, addr netip.Addr) string {
+	if addr.Is4() && prefix.Bits() > 30 {
+		return ""
+	}
+	if addr == prefix.Addr() {
+		return "network"
+	}
+	if addr.Is4() {
+		broadcast := prefix.Addr().As4()
+		for bit := prefix.Bits(); bit < 32; bit++ {
+			broadcast[bit/8] |= 0x80 >> (bit % 8)
+		}
+		if addr == netip.AddrFrom4(broadcast) {
+			return "broadcast"
+		}
+	}
+	return ""
+}
+
+// Place puts every address of every interface in its subnet. Each subnet
+// may hold a MAC and an IP address only once; later claims, in interface
+// ID order, are left out as duplicates, and a subnet's network and
+// broadcast addresses as invalid. Interfaces without addresses, and
+// addresses of an IP version the table has no subnets for, are skipped
+// without being reported.
+//
+// Placements are sorted by subnet ID and IP address, and unplaced entries
+// by interface ID, so the output is the same for the same input.
+func (table *SubnetTable) Place(ethInterfaces []sm.CompEthInterfaceV2) (placements []Placement,
+	unplaced []Unplaced) {
+	sorted := make([]sm.CompEthInterfaceV2, len(ethInterfaces))
+	copy(sorted, ethInterfaces)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	type subnetKey struct {
+		subnet *Subnet
+		key    string
+	}
+	claimed := make(map[subnetKey]string)
+
+	for _, ethInterface := range sorted {
+		if len(ethInterface.IPAddrs) == 0 {
+			continue
+		}
+		left := func(ipAddr string, reason string, detail string) {
+			unplaced = append(unplaced, Unplaced{
+				ID:      ethInterface.ID,
+				MACAddr: ethInterface.MACAddr,
+				CompID:  ethInterface.CompID,
+				IPAddr:  ipAddr,
+				Reason:  reason,
+				Detail:  detail,
+			})
+		}
+
+		hwAddr, err := mac.ParseUnicast(ethInterface.MACAddr)
+		if err != nil {
+			left("", UnplacedInvalidMAC, fmt.Sprintf("MAC address '%s': %v", ethInterface.MACAddr, err))
+			continue
+		}
+
+		for _, ipm := range ethInterface.IPAddrs {
+			if ipm.IPAddr == "" {
+				continue
+			}
+			addr, err := netip.ParseAddr(ipm.IPAddr)
+			if err != nil {
+				left(ipm.IPAddr, UnplacedInvalidIP, fmt.Sprintf("invalid IP address '%s'", ipm.IPAddr))
+				continue
+			}
+			addr = addr.Unmap().WithZone("")
+			if !table.hasFamily(addr) {
+				continue
+			}
+
+			subnet, ok := table.Lookup(addr)
+			if !ok {
+				left(ipm.IPAddr, UnplacedNoSubnet, fmt.Sprintf("%s is in no configured subnet", addr))
+				continue
+			}
+			if reserved := reservedAddrKind(subnet.prefix, addr); reserved != "" {
+				left(ipm.IPAddr, UnplacedInvalidIP, fmt.Sprintf("%s is the %s address of %s", addr, reserved,
+					subnet.prefix))
+				continue
+			}
+			network := ipm.Network
+			if subnet.Network != "" {
+				if network != "" && !strings.EqualFold(network, subnet.Network) {
+					left(ipm.IPAddr, UnplacedNetworkMismatch, fmt.Sprintf("%s is in subnet %s of network %s, not %s",
+						addr, subnet.prefix, subnet.Network, network))
+					continue
+				}
+				network = subnet.Network
+			}
+
+			ipKey := subnetKey{subnet, addr.String()}
+			macKey := subnetKey{subnet, hwAddr.String()}
+			if owner, ok := claimed[ipKey]; ok {
+				left(ipm.IPAddr, UnplacedDuplicateIP, fmt.Sprintf("%s is already reserved for %s", addr, owner))
+				continue
+			}
+			if owner, ok := claimed[macKey]; ok {
+				left(ipm.IPAddr, UnplacedDuplicateMAC, fmt.Sprintf("%s already has %s in subnet %s", hwAddr,
+					owner, subnet.prefix))
+				continue
+			}
+			claimed[ipKey] = ethInterface.ID
+			claimed[macKey] = addr.String()
+
+			placements = append(placements, Placement{
+				Interface: ethInterface,
+				Subnet:    subnet,
+				MAC:       hwAddr,
+				IPAddr:    addr,
+				Network:   network,
+			})
+		}
+	}
+
+	sort.SliceStable(placements, func(i, j int) bool {
+		if placements[i].Subnet.ID != placements[j].Subnet.ID {
+			return placements[i].Subnet.ID < placements[j].Subnet.ID
+		}
+		return placements[i].IPAddr.Less(placements[j].IPAddr)
+	})
+	return placements, unplaced
+}
+
+// IP versions a generated config covers.
+type ipFamily int
+
+const (
+	anyFamily ipFamily = iota
+	ipv4Family
+	ipv6Family
+)
+
+func (family ipFamily) has(addr netip.Addr) bool {
+	switch family {
+	case ipv4Family:
+		return addr.Unmap().Is4()
+	case ipv6Family:
+		return !addr.Unmap().Is4()
+	}
+	return true
+}
+
+// hostEntry is a placement and the DNS names of its host.
+type hostEntry struct {
+	Placement
+	// Empty if the host can't be named.
+	FQDN    string
+	Aliases []string
+}
+
+// placeHosts places ethInterfaces in table and names each placement, for
+// every config format. Only the subnets, hosts and unplaced addresses of
+// family are returned. Without a namer, a host is named after its
+// ComponentID, provided that is a valid DNS name.
+func placeHosts(table *SubnetTable, namer *Namer, ethInterfaces []sm.CompEthInterfaceV2, family ipFamily) (
+	subnets []*Subnet, hosts []hostEntry, unplaced []Unplaced, err error) {
+	if table == nil {
+		return nil, nil, nil, fmt.Errorf("no subnets configured")
+	}
+
+	for _, subnet := range table.Subnets() {
+		if family.has(subnet.prefix.Addr()) {
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	placements, allUnplaced := table.Place(ethInterfaces)
+	for _, left := range allUnplaced {
+		// Addresses of the other IP version belong to the other config.
+		if addr, err := netip.ParseAddr(left.IPAddr); err == nil && !family.has(addr) {
+			continue
+		}
+		unplaced = append(unplaced, left)
+	}
+	for _, placement := range placements {
+		if !family.has(placement.IPAddr) {
+			continue
+		}
+		host := hostEntry{Placement: placement}
+		if namer != nil {
+			fqdn, aliases, nameErr := namer.Name(placement.Interface, placement.Network)
+			if nameErr == nil {
+				host.FQDN = fqdn
+				host.Aliases = aliases
+			}
+		} else if name := strings.ToLower(placement.Interface.CompID); checkDNSName(name) == nil {
+			host.FQDN = name
+		}
+		hosts = append(hosts, host)
+	}
+	return subnets, hosts, unplaced, nil
+}
+
+// shortName is the first label of the host's FQDN.
+func (host hostEntry) shortName() string {
+	name, _, _ := strings.Cut(host.FQDN, ".")
+	return name
+}
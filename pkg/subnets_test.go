@@ -0,0 +1,153 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestNewSubnetTable(t *testing.T) {
+	tests := []struct {
+		subnets []Subnet
+		isErr   bool
+	}{
+		{[]Subnet{{ID: 2, CIDR: "10.252.0.0/17"}, {ID: 1, CIDR: "10.252.1.7/24"}}, false},
+		{[]Subnet{{ID: 1, CIDR: "10.252.0.0/17"}, {ID: 1, CIDR: "10.254.0.0/17"}}, true},
+		{[]Subnet{{ID: 1, CIDR: "10.252.0.0/17"}, {ID: 2, CIDR: "10.252.0.1/17"}}, true},
+		{[]Subnet{{ID: 0, CIDR: "10.252.0.0/17"}}, true},
+		{[]Subnet{{ID: 1, CIDR: "10.252.0.0"}}, true},
+	}
+
+	for ix, test := range tests {
+		table, err := NewSubnetTable(test.subnets)
+		if ((err != nil) != test.isErr) {
+			t.Errorf("ERROR, test %d: unexpected error result: %v", ix, err)
+			continue
+		}
+		if (err == nil && (table.Subnets()[0].ID != 1 || table.Subnets()[0].CIDR != "10.252.1.0/24")) {
+			t.Errorf("ERROR, test %d: subnets not sorted and masked: %v", ix, table.Subnets()[0])
+		}
+	}
+}
+
+func TestSubnetTablePlace(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 1, CIDR: "10.252.0.0/17", Network: "NMN"},
+		{ID: 2, CIDR: "10.252.2.0/24"},
+		{ID: 3, CIDR: "10.254.0.0/17", Network: "HMN"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	ethInterfaces := []sm.CompEthInterfaceV2{
+		{ID: "a4bf01000003", MACAddr: "a4:bf:01:00:00:03",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5"}, {IPAddr: "10.252.1.6"},
+				{IPAddr: "10.252.1.7"}}},
+		{ID: "a4bf01000001", MACAddr: "A4-BF-01-00-00-01", CompID: "x3000c0s1b0n0",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.5", Network: "NMN"}, {IPAddr: "10.252.2.5"},
+				{IPAddr: "fd00::5"}}},
+		{ID: "a4bf01000002", MACAddr: "a4:bf:01:00:00:02",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.254.1.5", Network: "NMN"}, {IPAddr: "10.100.0.1"},
+				{IPAddr: "10.254.0.0"}, {IPAddr: "bogus"}}},
+		{ID: "ffffffffffff", MACAddr: "ff:ff:ff:ff:ff:ff", IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.9"}}},
+		{ID: "a4bf01000004", MACAddr: "a4:bf:01:00:00:04"},
+	}
+
+	placements, unplaced := table.Place(ethInterfaces)
+	if (len(placements) != 3) {
+		t.Fatalf("ERROR, expected 3 placements, got %v", placements)
+	}
+	if (placements[0].Subnet.ID != 1 || placements[0].IPAddr.String() != "10.252.1.5" ||
+		placements[0].MAC.String() != "a4:bf:01:00:00:01" || placements[0].Network != "NMN") {
+		t.Errorf("ERROR, unexpected placement %v", placements[0])
+	}
+	if (placements[1].IPAddr.String() != "10.252.1.6" || placements[1].Interface.ID != "a4bf01000003") {
+		t.Errorf("ERROR, unexpected placement %v", placements[1])
+	}
+	if (placements[2].Subnet.ID != 2 || placements[2].IPAddr.String() != "10.252.2.5" || placements[2].Network != "") {
+		t.Errorf("ERROR, unexpected placement %v", placements[2])
+	}
+
+	expected := []struct {
+		id     string
+		reason string
+	}{
+		{"a4bf01000002", UnplacedNetworkMismatch},
+		{"a4bf01000002", UnplacedNoSubnet},
+		{"a4bf01000002", UnplacedInvalidIP},
+		{"a4bf01000002", UnplacedInvalidIP},
+		{"a4bf01000003", UnplacedDuplicateIP},
+		{"a4bf01000003", UnplacedDuplicateMAC},
+		{"ffffffffffff", UnplacedInvalidMAC},
+	}
+	if (len(unplaced) != len(expected)) {
+		t.Fatalf("ERROR, expected %d unplaced, got %v", len(expected), unplaced)
+	}
+	for ix, exp := range expected {
+		if (unplaced[ix].ID != exp.id || unplaced[ix].Reason != exp.reason) {
+			t.Errorf("ERROR, unplaced %d: expected %v, got %v", ix, exp, unplaced[ix])
+		}
+	}
+}
+
+func TestSubnetTablePlaceReserved(t *testing.T) {
+	table, err := NewSubnetTable([]Subnet{
+		{ID: 1, CIDR: "10.252.2.0/24"},
+		{ID: 2, CIDR: "10.252.3.0/31"},
+		{ID: 3, CIDR: "10.252.4.1/32"},
+		{ID: 4, CIDR: "fd00::/64"},
+	})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewSubnetTable() failed: %v", err)
+	}
+
+	tests := []struct {
+		ipAddr string
+		detail string
+	}{
+		{"10.252.2.0", "10.252.2.0 is the network address of 10.252.2.0/24"},
+		{"10.252.2.255", "10.252.2.255 is the broadcast address of 10.252.2.0/24"},
+		{"10.252.2.254", ""},
+		{"10.252.3.0", ""},
+		{"10.252.3.1", ""},
+		{"10.252.4.1", ""},
+		{"fd00::", "fd00:: is the network address of fd00::/64"},
+		{"fd00::ffff:ffff:ffff:ffff", ""},
+	}
+	for ix, test := range tests {
+		placements, unplaced := table.Place([]sm.CompEthInterfaceV2{{ID: "a4bf01000001", MACAddr: "a4:bf:01:00:00:01",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: test.ipAddr}}}})
+		if (test.detail == "") {
+			if (len(placements) != 1 || len(unplaced) != 0) {
+				t.Errorf("ERROR, test %d: %s not placed: %v", ix, test.ipAddr, unplaced)
+			}
+			continue
+		}
+		if (len(placements) != 0 || len(unplaced) != 1 || unplaced[0].Reason != UnplacedInvalidIP ||
+			unplaced[0].Detail != test.detail) {
+			t.Errorf("ERROR, test %d: %s placed %v, unplaced %v", ix, test.ipAddr, placements, unplaced)
+		}
+	}
+}
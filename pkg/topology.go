@@ -0,0 +1,294 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// The topology checker groups interfaces by component and checks each
+// group against rules per HMS type, e.g. that a Node has exactly one NMN
+// IP address, so problems show up in alerting instead of when a node fails
+// to boot. Every interface is also checked for a Type that doesn't match
+// the type of its ComponentID.
+
+// What a TopologyRule counts.
+const (
+	// IP addresses on the rule's network.
+	CountIPs = "IPs"
+	// Interfaces (MACs) with at least one IP address on the rule's network.
+	CountInterfaces = "Interfaces"
+)
+
+// Severities of violations.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Names of the checks built into TopologyChecker.
+const (
+	RuleTypeMismatch     = "type-mismatch"
+	RuleInvalidComponent = "invalid-component-id"
+)
+
+// TopologyRule requires the components of one HMS type to have between Min
+// and Max of something (see Count) on a network. Rules can be loaded from
+// JSON.
+type TopologyRule struct {
+	Name     string `json:"Name"`
+	Type     string `json:"Type"` // HMS type, e.g. "NodeBMC"
+	Network  string `json:"Network"`
+	Count    string `json:"Count"` // CountIPs (the default) or CountInterfaces
+	Min      int    `json:"Min"`
+	Max      int    `json:"Max"`                // Negative, or left out of JSON, means no limit.
+	Severity string `json:"Severity,omitempty"` // Defaults to SeverityError.
+}
+
+// UnmarshalJSON reads a rule, taking a missing Max as no limit. A Max below
+// Min is an error, as no count could pass.
+func (rule *TopologyRule) UnmarshalJSON(data []byte) error {
+	type plainRule TopologyRule
+	plain := plainRule{Max: -1}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	if plain.Max >= 0 && plain.Min > plain.Max {
+		return fmt.Errorf("topology rule '%s': Min %d is above Max %d", plain.Name, plain.Min, plain.Max)
+	}
+	*rule = TopologyRule(plain)
+	return nil
+}
+
+// DefaultTopologyRules are the checks CSM systems are expected to pass.
+var DefaultTopologyRules = []TopologyRule{
+	{Name: "node-one-nmn-ip", Type: "Node", Network: "NMN", Count: CountIPs, Min: 1, Max: 1},
+	{Name: "node-one-nmn-mac", Type: "Node", Network: "NMN", Count: CountInterfaces, Min: 1, Max: 1},
+	{Name: "nodebmc-hmn-ip", Type: "NodeBMC", Network: "HMN", Count: CountIPs, Min: 1, Max: -1},
+	{Name: "routerbmc-hmn-ip", Type: "RouterBMC", Network: "HMN", Count: CountIPs, Min: 1, Max: -1},
+	{Name: "chassisbmc-hmn-ip", Type: "ChassisBMC", Network: "HMN", Count: CountIPs, Min: 1, Max: -1},
+}
+
+// TopologyViolation is one failed check, for one component.
+type TopologyViolation struct {
+	Rule       string   `json:"Rule"`
+	Severity   string   `json:"Severity"`
+	CompID     string   `json:"ComponentID"`
+	Type       string   `json:"Type"`
+	Network    string   `json:"Network,omitempty"`
+	Count      int      `json:"Count"`
+	Interfaces []string `json:"Interfaces,omitempty"`
+	Detail     string   `json:"Detail"`
+}
+
+// TopologyChecker applies Rules to interfaces grouped by component.
+type TopologyChecker struct {
+	Rules []TopologyRule
+
+	// Optional; places IP addresses that have no Network. Those that still
+	// have none count towards no network.
+	Networks *NetworkTable
+}
+
+// NewTopologyChecker returns a checker using DefaultTopologyRules.
+func NewTopologyChecker() *TopologyChecker {
+	return &TopologyChecker{Rules: append([]TopologyRule(nil), DefaultTopologyRules...)}
+}
+
+func (checker *TopologyChecker) network(ipm sm.IPAddressMapping) string {
+	if ipm.Network == "" && checker.Networks != nil {
+		network, _ := checker.Networks.Lookup(ipm.IPAddr)
+		return network
+	}
+	return ipm.Network
+}
+
+// Check groups ethInterfaces by ComponentID and applies the rules to each
+// group. componentIDs lists further components known to HSM, so those with
+// no interfaces at all are checked too; it may be nil. Interfaces with no
+// ComponentID are ignored. Violations are sorted by component and rule.
+func (checker *TopologyChecker) Check(ethInterfaces []sm.CompEthInterfaceV2,
+	componentIDs []string) []TopologyViolation {
+	var violations []TopologyViolation
+	groups := make(map[string][]sm.CompEthInterfaceV2)
+	for _, id := range componentIDs {
+		id = xnametypes.NormalizeHMSCompID(id)
+		groups[id] = groups[id]
+	}
+
+	for _, ethInterface := range ethInterfaces {
+		if ethInterface.CompID == "" {
+			continue
+		}
+		compID := xnametypes.NormalizeHMSCompID(ethInterface.CompID)
+		compType := xnametypes.GetHMSType(compID)
+		switch {
+		case compType == xnametypes.HMSTypeInvalid:
+			violations = append(violations, TopologyViolation{
+				Rule:       RuleInvalidComponent,
+				Severity:   SeverityError,
+				CompID:     ethInterface.CompID,
+				Interfaces: []string{ethInterface.ID},
+				Detail:     fmt.Sprintf("ComponentID '%s' is not a valid xname", ethInterface.CompID),
+			})
+			continue
+		case ethInterface.Type != "" && !strings.EqualFold(ethInterface.Type, compType.String()):
+			violations = append(violations, TopologyViolation{
+				Rule:       RuleTypeMismatch,
+				Severity:   SeverityError,
+				CompID:     compID,
+				Type:       compType.String(),
+				Interfaces: []string{ethInterface.ID},
+				Detail: fmt.Sprintf("interface Type is %s but %s is a %s", ethInterface.Type, compID,
+					compType),
+			})
+		}
+		groups[compID] = append(groups[compID], ethInterface)
+	}
+
+	for compID, group := range groups {
+		compType := xnametypes.GetHMSType(compID)
+		for _, rule := range checker.Rules {
+			if !strings.EqualFold(rule.Type, compType.String()) {
+				continue
+			}
+			if violation, ok := checker.apply(rule, compID, compType.String(), group); !ok {
+				violations = append(violations, violation)
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].CompID != violations[j].CompID {
+			return violations[i].CompID < violations[j].CompID
+		}
+		return violations[i].Rule < violations[j].Rule
+	})
+	return violations
+}
+
+// apply checks one rule against a component's interfaces.
+func (checker *TopologyChecker) apply(rule TopologyRule, compID string, compType string,
+	group []sm.CompEthInterfaceV2) (violation TopologyViolation, ok bool) {
+	count := 0
+	var ids []string
+	for _, ethInterface := range group {
+		onNetwork := 0
+		for _, ipm := range ethInterface.IPAddrs {
+			if ipm.IPAddr != "" && strings.EqualFold(checker.network(ipm), rule.Network) {
+				onNetwork++
+			}
+		}
+		if onNetwork == 0 {
+			continue
+		}
+		ids = append(ids, ethInterface.ID)
+		if rule.Count == CountInterfaces {
+			count++
+		} else {
+			count += onNetwork
+		}
+	}
+	if count >= rule.Min && (rule.Max < 0 || count <= rule.Max) {
+		return violation, true
+	}
+
+	what := "IP addresses"
+	if rule.Count == CountInterfaces {
+		what = "interfaces"
+	}
+	want := fmt.Sprintf("%d", rule.Min)
+	switch {
+	case rule.Max < 0:
+		want = "at least " + want
+	case rule.Max != rule.Min:
+		want = fmt.Sprintf("%d to %d", rule.Min, rule.Max)
+	}
+	severity := rule.Severity
+	if severity == "" {
+		severity = SeverityError
+	}
+	sort.Strings(ids)
+	return TopologyViolation{
+		Rule:       rule.Name,
+		Severity:   severity,
+		CompID:     compID,
+		Type:       compType,
+		Network:    rule.Network,
+		Count:      count,
+		Interfaces: ids,
+		Detail:     fmt.Sprintf("%s has %d %s on %s, expected %s", compID, count, what, rule.Network, want),
+	}, false
+}
+
+func (helper *DNSDHCPHelper) CheckTopology(checker *TopologyChecker) (violations []TopologyViolation, err error) {
+	return helper.CheckTopologyCtx(context.Background(), checker)
+}
+
+// CheckTopologyCtx runs checker over every interface in HSM and every
+// component of a type the rules apply to. A nil checker uses the default
+// rules; a checker without a network table uses the helper's, see
+// WithNetworkTable().
+func (helper *DNSDHCPHelper) CheckTopologyCtx(ctx context.Context, checker *TopologyChecker) (
+	violations []TopologyViolation, err error) {
+	if checker == nil {
+		checker = NewTopologyChecker()
+	}
+	if checker.Networks == nil && helper.networks != nil {
+		bound := *checker
+		bound.Networks = helper.networks
+		checker = &bound
+	}
+
+	ethInterfaces, err := helper.GetAllEthernetInterfacesCtx(ctx)
+	if err != nil {
+		return
+	}
+
+	var types []string
+	seen := make(map[string]bool)
+	for _, rule := range checker.Rules {
+		if hmsType := xnametypes.VerifyNormalizeType(rule.Type); hmsType != "" && !seen[hmsType] {
+			seen[hmsType] = true
+			types = append(types, hmsType)
+		}
+	}
+	var componentIDs []string
+	if len(types) > 0 {
+		components, compErr := helper.GetComponentsCtx(ctx, types...)
+		if compErr != nil {
+			err = compErr
+			return
+		}
+		for _, component := range components {
+			componentIDs = append(componentIDs, component.ID)
+		}
+	}
+	return checker.Check(ethInterfaces, componentIDs), nil
+}
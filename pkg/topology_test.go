@@ -0,0 +1,129 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestCheckTopology(t *testing.T) {
+	ethInterfaces := []sm.CompEthInterfaceV2{
+		// A healthy node.
+		{ID: "a4bf01000001", CompID: "x3000c0s1b0n0", Type: "Node",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.1", Network: "NMN"}}},
+		// A node with two MACs on the NMN, once the second's network is known.
+		{ID: "a4bf01000002", CompID: "x3000c0s2b0n0", Type: "Node",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.2", Network: "NMN"}}},
+		{ID: "a4bf01000003", CompID: "x3000c0s2b0n0", Type: "Node",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.3"}}},
+		// A BMC with no HMN address, and the wrong Type.
+		{ID: "a4bf01000004", CompID: "x3000c0s1b0", Type: "Node",
+			IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.4", Network: "NMN"}}},
+		{ID: "a4bf01000005", CompID: "not-an-xname"},
+		// No component; ignored.
+		{ID: "a4bf01000006"},
+	}
+
+	var err error
+	checker := NewTopologyChecker()
+	violations := checker.Check(ethInterfaces, []string{"x3000c0s3b0", "x3000c0s1b0n0"})
+
+	expected := []struct {
+		rule   string
+		compID string
+		count  int
+	}{
+		{RuleInvalidComponent, "not-an-xname", 0},
+		{"nodebmc-hmn-ip", "x3000c0s1b0", 0},
+		{RuleTypeMismatch, "x3000c0s1b0", 0},
+		{"nodebmc-hmn-ip", "x3000c0s3b0", 0},
+	}
+	if (len(violations) != len(expected)) {
+		t.Fatalf("ERROR, expected %d violations, got %v", len(expected), violations)
+	}
+	for ix, exp := range expected {
+		vv := violations[ix]
+		if (vv.Rule != exp.rule || vv.CompID != exp.compID || vv.Count != exp.count || vv.Severity != SeverityError) {
+			t.Errorf("ERROR, violation %d: expected %v, got %v", ix, exp, vv)
+		}
+	}
+
+	// The second NMN address is only counted once its network is known.
+	checker.Networks, err = NewNetworkTable(map[string][]string{"NMN": {"10.252.0.0/17"}})
+	if (err != nil) {
+		t.Fatalf("ERROR, NewNetworkTable() failed: %v", err)
+	}
+	violations = checker.Check(ethInterfaces, nil)
+	if (len(violations) != 5 || violations[3].Rule != "node-one-nmn-ip" || violations[3].Count != 2 ||
+		violations[4].Rule != "node-one-nmn-mac" || violations[4].Count != 2 ||
+		len(violations[4].Interfaces) != 2) {
+		t.Errorf("ERROR, unexpected violations with a network table: %v", violations)
+	}
+	if (violations[3].Detail != "x3000c0s2b0n0 has 2 IP addresses on NMN, expected 1") {
+		t.Errorf("ERROR, unexpected detail '%s'", violations[3].Detail)
+	}
+}
+
+func TestTopologyRulesJSON(t *testing.T) {
+	var rules []TopologyRule
+	err := json.Unmarshal([]byte(`[{"Name":"cn-hsn","Type":"node","Network":"HSN","Count":"Interfaces",
+		"Min":2,"Max":-1,"Severity":"warning"}]`), &rules)
+	if (err != nil) {
+		t.Fatalf("ERROR, unmarshal failed: %v", err)
+	}
+
+	checker := &TopologyChecker{Rules: rules}
+	violations := checker.Check([]sm.CompEthInterfaceV2{{ID: "a4bf01000001", CompID: "x1000c0s0b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.253.0.1", Network: "HSN"}}}}, nil)
+	if (len(violations) != 1 || violations[0].Severity != SeverityWarning ||
+		violations[0].Detail != "x1000c0s0b0n0 has 1 interfaces on HSN, expected at least 2") {
+		t.Fatalf("ERROR, unexpected violations %v", violations)
+	}
+
+	// Without Max there is no limit; a Max below Min is refused.
+	err = json.Unmarshal([]byte(`[{"Name":"cn-nmn","Type":"Node","Network":"NMN","Min":1}]`), &rules)
+	if (err != nil || len(rules) != 1 || rules[0].Max != -1) {
+		t.Fatalf("ERROR, unmarshal returned %v, error: %v", rules, err)
+	}
+	checker.Rules = rules
+	if violations := checker.Check([]sm.CompEthInterfaceV2{{ID: "a4bf01000001", CompID: "x1000c0s0b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.0.1", Network: "NMN"}}}}, nil); (len(violations) != 0) {
+		t.Errorf("ERROR, rule without Max has violations %v", violations)
+	}
+	err = json.Unmarshal([]byte(`[{"Name":"cn-nmn","Type":"Node","Network":"NMN","Min":2,"Max":1}]`), &rules)
+	if (err == nil) {
+		t.Errorf("ERROR, unmarshal accepted Min above Max")
+	}
+
+	out, err := json.Marshal(violations[0])
+	if (err != nil) {
+		t.Fatalf("ERROR, marshal failed: %v", err)
+	}
+	expected := `{"Rule":"cn-hsn","Severity":"warning","ComponentID":"x1000c0s0b0n0","Type":"Node","Network":"HSN",` +
+		`"Count":1,"Interfaces":["a4bf01000001"],"Detail":"x1000c0s0b0n0 has 1 interfaces on HSN, expected at least 2"}`
+	if (string(out) != expected) {
+		t.Errorf("ERROR, unexpected JSON %s", out)
+	}
+}
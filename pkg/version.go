@@ -0,0 +1,239 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"sync"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// HSM API versions the helper can talk. Callers always see V2 types; when
+// talking v1, payloads are converted with sm.CompEthInterfaceV2.ToV1() on the
+// way out and back to V2 (with at most one IP address) on the way in.
+const (
+	HSMAPIVersion1 = "v1"
+	HSMAPIVersion2 = "v2"
+)
+
+var ErrUnsupportedByAPIVersion = base.NewHMSError("hsm-unsupported-api-version",
+	"not supported by the HSM API version in use")
+var ErrUnknownAPIVersion = base.NewHMSError("hsm-unknown-api-version", "unknown HSM API version")
+
+var apiVersionRE = regexp.MustCompile(`^v[0-9]+$`)
+
+// apiVersionState holds the outcome of API version detection. It is kept
+// behind a pointer so helpers can still be copied by value.
+type apiVersionState struct {
+	mu       sync.Mutex
+	resolved bool
+	basePath string
+}
+
+// WithAPIVersionDetection makes the helper probe HSM before its first
+// request and use v2 if HSM serves it, v1 otherwise. The version in the
+// base path (see WithBasePath()) is replaced by the detected one. A failed
+// probe is retried on the next request.
+func WithAPIVersionDetection() Option {
+	return func(helper *DNSDHCPHelper) {
+		helper.versionState = &apiVersionState{}
+	}
+}
+
+func (helper *DNSDHCPHelper) configuredBasePath() string {
+	if helper.basePath == "" {
+		return defaultHSMBasePath
+	}
+	return helper.basePath
+}
+
+// currentBasePath returns the HSM API path in use, after detection if that
+// was requested and has completed.
+func (helper *DNSDHCPHelper) currentBasePath() string {
+	if state := helper.versionState; state != nil {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		if state.resolved {
+			return state.basePath
+		}
+	}
+	return helper.configuredBasePath()
+}
+
+// APIVersion returns the HSM API version the helper is talking, as
+// configured or detected, e.g. HSMAPIVersion1. It is "" while detection is
+// pending, and if the base path names no version.
+func (helper *DNSDHCPHelper) APIVersion() string {
+	if state := helper.versionState; state != nil {
+		state.mu.Lock()
+		resolved := state.resolved
+		state.mu.Unlock()
+		if !resolved {
+			return ""
+		}
+	}
+	version := path.Base(helper.currentBasePath())
+	if !apiVersionRE.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// negotiateAPIVersion returns the API version to use for a request, running
+// detection first if it is enabled and hasn't succeeded yet.
+func (helper *DNSDHCPHelper) negotiateAPIVersion(ctx context.Context) (string, error) {
+	state := helper.versionState
+	if state == nil {
+		return helper.APIVersion(), nil
+	}
+
+	state.mu.Lock()
+	resolved := state.resolved
+	state.mu.Unlock()
+	if resolved {
+		return helper.APIVersion(), nil
+	}
+
+	// Hold the lock while probing so concurrent first requests share one
+	// probe.
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.resolved {
+		return path.Base(state.basePath), nil
+	}
+
+	prefix := helper.configuredBasePath()
+	if apiVersionRE.MatchString(path.Base(prefix)) {
+		prefix = path.Dir(prefix)
+	}
+	for _, version := range []string{HSMAPIVersion2, HSMAPIVersion1} {
+		response, err := rtGet(ctx, helper, helper.HSMURL+prefix+"/"+version+"/service/ready")
+		if err != nil {
+			return "", err
+		}
+		body, _ := readBody(ctx, response)
+		if response.StatusCode == http.StatusNotFound {
+			continue
+		}
+		// Anything but success, e.g. an HSM that isn't up yet or refuses
+		// our credentials, says nothing about the version; probe again
+		// next time.
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return "", newHSMError(response, body)
+		}
+		state.resolved = true
+		state.basePath = prefix + "/" + version
+		return version, nil
+	}
+	return "", fmt.Errorf("HSM at %s%s serves neither the %s nor the %s API", helper.HSMURL, prefix,
+		HSMAPIVersion2, HSMAPIVersion1)
+}
+
+func unsupportedByAPIVersion(operation string, version string) error {
+	return fmt.Errorf("%s with HSM %s API: %w", operation, version, ErrUnsupportedByAPIVersion)
+}
+
+// ethInterfaceFromV1 converts a v1 interface to V2, the reverse of
+// sm.CompEthInterfaceV2.ToV1().
+func ethInterfaceFromV1(ceiV1 sm.CompEthInterface) sm.CompEthInterfaceV2 {
+	cei := sm.CompEthInterfaceV2{
+		ID:         ceiV1.ID,
+		Desc:       ceiV1.Desc,
+		MACAddr:    ceiV1.MACAddr,
+		LastUpdate: ceiV1.LastUpdate,
+		CompID:     ceiV1.CompID,
+		Type:       ceiV1.Type,
+		IPAddrs:    []sm.IPAddressMapping{},
+	}
+	if ceiV1.IPAddr != "" {
+		cei.IPAddrs = append(cei.IPAddrs, sm.IPAddressMapping{IPAddr: ceiV1.IPAddr})
+	}
+	return cei
+}
+
+func marshalEthInterface(version string, ethInterface sm.CompEthInterfaceV2) ([]byte, error) {
+	if version == HSMAPIVersion1 {
+		return json.Marshal(ethInterface.ToV1())
+	}
+	return json.Marshal(ethInterface)
+}
+
+func unmarshalEthInterface(version string, jsonBytes []byte, ethInterface *sm.CompEthInterfaceV2) error {
+	if version == HSMAPIVersion1 {
+		var ceiV1 sm.CompEthInterface
+		if err := json.Unmarshal(jsonBytes, &ceiV1); err != nil {
+			return err
+		}
+		*ethInterface = ethInterfaceFromV1(ceiV1)
+		return nil
+	}
+	return json.Unmarshal(jsonBytes, ethInterface)
+}
+
+func unmarshalEthInterfaces(version string, jsonBytes []byte, ethInterfaces *[]sm.CompEthInterfaceV2) error {
+	if version == HSMAPIVersion1 {
+		var ceiV1s []sm.CompEthInterface
+		if err := json.Unmarshal(jsonBytes, &ceiV1s); err != nil {
+			return err
+		}
+		*ethInterfaces = make([]sm.CompEthInterfaceV2, 0, len(ceiV1s))
+		for _, ceiV1 := range ceiV1s {
+			*ethInterfaces = append(*ethInterfaces, ethInterfaceFromV1(ceiV1))
+		}
+		return nil
+	}
+	return json.Unmarshal(jsonBytes, ethInterfaces)
+}
+
+// ethInterfacePatchV1 is the v1 wire form of a field-mask patch.
+type ethInterfacePatchV1 struct {
+	Desc   *string `json:"Description,omitempty"`
+	IPAddr *string `json:"IPAddress,omitempty"`
+	CompID *string `json:"ComponentID,omitempty"`
+}
+
+func marshalEthInterfacePatch(version string, patch sm.CompEthInterfaceV2Patch) ([]byte, error) {
+	if version == HSMAPIVersion1 {
+		patchV1 := ethInterfacePatchV1{Desc: patch.Desc, CompID: patch.CompID}
+		if patch.IPAddrs != nil {
+			ipAddr := ""
+			if len(*patch.IPAddrs) > 0 {
+				ipAddr = (*patch.IPAddrs)[0].IPAddr
+			}
+			patchV1.IPAddr = &ipAddr
+		}
+		return json.Marshal(patchV1)
+	}
+	return json.Marshal(ethInterfacePatch{
+		Desc:    patch.Desc,
+		CompID:  patch.CompID,
+		IPAddrs: patch.IPAddrs,
+	})
+}
@@ -0,0 +1,238 @@
+// MIT License
+//
+// (C) Copyright 2026 Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package dns_dhcp
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var v1Comps = []sm.CompEthInterface{
+	{ID: "a4bf012e7fb1", MACAddr: "a4:bf:01:2e:7f:b1", IPAddr: "10.252.1.5", CompID: "x3000c0s1b0n0"},
+	{ID: "a4bf012e7fb2", MACAddr: "a4:bf:01:2e:7f:b2"},
+}
+var v1Body map[string]interface{}
+var v1Probes int
+
+// handleV1 is an HSM that only serves the v1 API.
+func handleV1(w http.ResponseWriter, req *http.Request) {
+	if (strings.HasSuffix(req.URL.Path, "/service/ready")) {
+		v1Probes++
+	}
+	if (!strings.HasPrefix(req.URL.Path, "/hsm/v1/")) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/hsm/v1")
+	switch {
+	case (path == "/service/ready"):
+		w.WriteHeader(http.StatusOK)
+	case (req.Method == "GET" && path == "/Inventory/EthernetInterfaces"):
+		ba, _ := json.Marshal(v1Comps)
+		w.Write(ba)
+	case (req.Method == "GET"):
+		ba, _ := json.Marshal(v1Comps[0])
+		w.Write(ba)
+	default:
+		v1Body = nil
+		body, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(body, &v1Body)
+		if (req.Method == "POST") {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}
+}
+
+func TestAPIVersionDetection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleV1))
+	defer srv.Close()
+
+	v1Probes = 0
+	hlp := New(srv.URL, WithAPIVersionDetection())
+	if (hlp.APIVersion() != "") {
+		t.Errorf("ERROR, expected no version before detection, got %s", hlp.APIVersion())
+	}
+	list, err := hlp.GetAllEthernetInterfaces()
+	if (err != nil) {
+		t.Fatalf("ERROR, GetAllEthernetInterfaces() error: %v", err)
+	}
+	if (hlp.APIVersion() != HSMAPIVersion1) {
+		t.Errorf("ERROR, v1 not detected, got %s", hlp.APIVersion())
+	}
+	if (len(list) != 2 || len(list[0].IPAddrs) != 1 || list[0].IPAddrs[0].IPAddr != "10.252.1.5" ||
+		list[1].IPAddrs == nil || len(list[1].IPAddrs) != 0) {
+		t.Errorf("ERROR, v1 interfaces not converted: %v", list)
+	}
+
+	hlp.GetUnknownComponents()
+	if (v1Probes != 2) {
+		t.Errorf("ERROR, expected 2 probes (v2, v1), got %d", v1Probes)
+	}
+
+	srv2 := httptest.NewServer(http.HandlerFunc(handleUnk))
+	defer srv2.Close()
+	hlp = New(srv2.URL, WithAPIVersionDetection())
+	hlp.GetAllEthernetInterfaces()
+	if (hlp.APIVersion() != HSMAPIVersion2) {
+		t.Errorf("ERROR, expected v2, got %s", hlp.APIVersion())
+	}
+}
+
+func TestAPIVersion1(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleV1))
+	defer srv.Close()
+
+	hlp := New(srv.URL, WithAPIVersion("v1"))
+	ethi := sm.CompEthInterfaceV2{MACAddr: "a4:bf:01:2e:7f:b3", CompID: "x3000c0s3b0n0",
+		IPAddrs: []sm.IPAddressMapping{{IPAddr: "10.252.1.7", Network: "NMN"}, {IPAddr: "10.254.1.7"}}}
+	err := hlp.AddNewEthernetInterface(ethi, false)
+	if (err != nil) {
+		t.Errorf("ERROR, AddNewEthernetInterface() error: %v", err)
+	}
+	if _, ok := v1Body["IPAddresses"]; (ok || v1Body["IPAddress"] != "10.252.1.7") {
+		t.Errorf("ERROR, POST body not converted to v1: %v", v1Body)
+	}
+
+	empty := []sm.IPAddressMapping{}
+	_, err = hlp.PatchEthernetInterfaceFields(ethi.MACAddr, sm.CompEthInterfaceV2Patch{IPAddrs: &empty})
+	if (err != nil) {
+		t.Errorf("ERROR, PatchEthernetInterfaceFields() error: %v", err)
+	}
+	if (len(v1Body) != 1 || v1Body["IPAddress"] != "") {
+		t.Errorf("ERROR, PATCH body not converted to v1: %v", v1Body)
+	}
+
+	ipAddrs, err := hlp.ListIPAddresses("a4bf012e7fb1")
+	if (err != nil || len(ipAddrs) != 1 || ipAddrs[0].IPAddr != "10.252.1.5") {
+		t.Errorf("ERROR, ListIPAddresses() returned %v, %v", ipAddrs, err)
+	}
+	err = hlp.AddIPAddress("a4bf012e7fb1", sm.IPAddressMapping{IPAddr: "10.254.1.5"})
+	if (!errors.Is(err, ErrUnsupportedByAPIVersion)) {
+		t.Errorf("ERROR, expected ErrUnsupportedByAPIVersion, got: %v", err)
+	}
+	_, err = hlp.QueryEthernetInterfaces(EthernetInterfaceFilter{Network: []string{"NMN"}})
+	if (!errors.Is(err, ErrUnsupportedByAPIVersion)) {
+		t.Errorf("ERROR, expected ErrUnsupportedByAPIVersion, got: %v", err)
+	}
+}
+
+func TestAPIVersionConfigured(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(handleV1))
+	defer srv.Close()
+
+	if version := New(srv.URL).APIVersion(); (version != HSMAPIVersion2) {
+		t.Errorf("ERROR, expected the default v2, got '%s'", version)
+	}
+	if version := New(srv.URL, WithBasePath("/apis/smd/hsm")).APIVersion(); (version != "") {
+		t.Errorf("ERROR, expected no version from a base path without one, got '%s'", version)
+	}
+
+	hlp := New(srv.URL, WithAPIVersion("v3"))
+	if (hlp.APIVersion() != HSMAPIVersion2) {
+		t.Errorf("ERROR, unknown version changed the base path to %s", hlp.currentBasePath())
+	}
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (!errors.Is(err, ErrUnknownAPIVersion)) {
+		t.Errorf("ERROR, expected ErrUnknownAPIVersion, got %v", err)
+	}
+}
+
+func TestAPIVersionDetectionFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	hlp := New(srv.URL, WithAPIVersionDetection())
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (err == nil) {
+		t.Errorf("ERROR, detection against a non-HSM server succeeded.")
+	}
+}
+
+func TestAPIVersionDetectionRetry(t *testing.T) {
+	unavailable := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if (unavailable && strings.HasSuffix(req.URL.Path, "/service/ready")) {
+			unavailable = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handleV1(w, req)
+	}))
+	defer srv.Close()
+
+	hlp := New(srv.URL, WithAPIVersionDetection())
+	hlp.HTTPClient.RetryMax = 0
+	_, err := hlp.GetAllEthernetInterfaces()
+	if (!base.IsHMSErrorClass(err, HSMErrClassUnavailable)) {
+		t.Errorf("ERROR, expected an unavailable HMSError from a failed probe, got %v", err)
+	}
+	if (hlp.versionState.resolved) {
+		t.Fatalf("ERROR, a failed probe resolved the API version")
+	}
+
+	// The next request probes again and finds v1.
+	list, err := hlp.GetAllEthernetInterfaces()
+	if (err != nil || len(list) != 2 || hlp.APIVersion() != HSMAPIVersion1) {
+		t.Errorf("ERROR, expected v1 after a retried probe, got %s, %v, error: %v", hlp.APIVersion(), list, err)
+	}
+}
+
+func TestAPIVersionDetectionOtherResources(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/hsm/v1/service/ready":
+			w.WriteHeader(http.StatusOK)
+		case "/hsm/v1/State/Components":
+			w.Write([]byte(`{"Components":[{"ID":"x3000c0s1b0n0","Type":"Node"}]}`))
+		case "/hsm/v1/Defaults/NodeMaps":
+			w.Write([]byte(`{"NodeMaps":[{"ID":"x3000c0s1b0n0","NID":1}]}`))
+		case "/hsm/v1/Inventory/RedfishEndpoints":
+			w.Write([]byte(`{"RedfishEndpoints":[{"ID":"x3000c0s1b0"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// Each is the first request of its helper, so it has to detect v1.
+	comps, err := New(srv.URL, WithAPIVersionDetection()).GetComponents()
+	if (err != nil || len(comps) != 1) {
+		t.Errorf("ERROR, GetComponents() returned %v, error: %v", comps, err)
+	}
+	nodeMaps, err := New(srv.URL, WithAPIVersionDetection()).GetNodeMaps()
+	if (err != nil || len(nodeMaps) != 1) {
+		t.Errorf("ERROR, GetNodeMaps() returned %v, error: %v", nodeMaps, err)
+	}
+	endpoints, err := New(srv.URL, WithAPIVersionDetection()).GetRedfishEndpoints()
+	if (err != nil || len(endpoints) != 1) {
+		t.Errorf("ERROR, GetRedfishEndpoints() returned %v, error: %v", endpoints, err)
+	}
+}